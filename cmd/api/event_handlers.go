@@ -0,0 +1,183 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// executionEventsPollInterval is how often an event stream re-reads the
+// execution while no events arrive. This keeps the connection alive and ends
+// the stream for executions that finish on another replica, whose events are
+// not published to this process.
+var executionEventsPollInterval = 15 * time.Second
+
+// handleExecutionEvents handles GET /executions/:id/events. It streams the
+// execution's progress as Server-Sent Events: the already persisted task logs
+// are replayed first, followed by live events from the engine, until the
+// execution finishes or the client disconnects.
+func handleExecutionEvents(
+	execRepo repository.ExecutionRepository,
+	taskLogRepo repository.TaskLogRepository,
+	events *engine.EventBus,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idParam := c.Param("id")
+		executionID, err := uuid.Parse(idParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid execution ID"})
+			return
+		}
+
+		// Subscribe before reading the stored state so no event is missed in
+		// between; events already covered by the replay are skipped below
+		live, unsubscribe := events.Subscribe(executionID)
+		defer unsubscribe()
+
+		execution, err := execRepo.GetByID(executionID)
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Execution not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve execution"})
+			return
+		}
+
+		taskLogs, err := taskLogRepo.GetByExecutionID(executionID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve task logs"})
+			return
+		}
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+
+		replayed := make(map[string]bool)
+		send := func(event engine.Event) {
+			replayed[eventKey(event)] = true
+			c.SSEvent(event.Type, event)
+			c.Writer.Flush()
+		}
+
+		for _, event := range replayEvents(execution, taskLogs) {
+			send(event)
+		}
+		if executionFinished(execution) {
+			send(finishedEvent(execution))
+			return
+		}
+		// Send the headers even if nothing was replayed, so clients know the
+		// stream is open
+		c.Writer.Flush()
+
+		ticker := time.NewTicker(executionEventsPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case event := <-live:
+				if replayed[eventKey(event)] {
+					continue
+				}
+				send(event)
+				if event.Type == engine.EventExecutionFinished {
+					return
+				}
+			case <-ticker.C:
+				execution, err := execRepo.GetByID(executionID)
+				if err == nil && executionFinished(execution) {
+					send(finishedEvent(execution))
+					return
+				}
+				c.SSEvent("ping", gin.H{"timestamp": time.Now().UTC()})
+				c.Writer.Flush()
+			}
+		}
+	}
+}
+
+// replayEvents rebuilds the events already published for an execution from
+// its stored state, in the order they happened
+func replayEvents(execution *repository.Execution, taskLogs []*repository.TaskLog) []engine.Event {
+	var events []engine.Event
+	if execution.Status == "pending" {
+		return events
+	}
+
+	events = append(events, engine.Event{
+		Type:        engine.EventExecutionStarted,
+		ExecutionID: execution.ID,
+		Status:      "running",
+		Timestamp:   execution.StartedAt,
+	})
+	for _, taskLog := range taskLogs {
+		event := engine.Event{
+			ExecutionID: taskLog.ExecutionID,
+			TaskID:      taskLog.TaskID,
+			TaskType:    taskLog.TaskType,
+			Attempt:     taskLog.Attempt,
+			Status:      taskLog.Status,
+			Error:       taskLog.Error,
+			Timestamp:   taskLog.StartedAt,
+		}
+
+		if taskLog.Status != "skipped" {
+			started := event
+			started.Type = engine.EventTaskStarted
+			started.Status = "running"
+			started.Error = ""
+			events = append(events, started)
+		}
+
+		switch taskLog.Status {
+		case "running":
+			continue
+		case "success":
+			event.Type = engine.EventTaskCompleted
+		case "skipped":
+			event.Type = engine.EventTaskSkipped
+		default:
+			event.Type = engine.EventTaskFailed
+		}
+		event.DurationMs = taskLog.CompletedAt.Sub(taskLog.StartedAt).Milliseconds()
+		event.Timestamp = taskLog.CompletedAt
+		events = append(events, event)
+	}
+	return events
+}
+
+// finishedEvent builds the execution_finished event of a finished execution
+func finishedEvent(execution *repository.Execution) engine.Event {
+	event := engine.Event{
+		Type:        engine.EventExecutionFinished,
+		ExecutionID: execution.ID,
+		Status:      execution.Status,
+		Timestamp:   time.Now().UTC(),
+	}
+	if execution.CompletedAt != nil {
+		event.DurationMs = execution.CompletedAt.Sub(execution.StartedAt).Milliseconds()
+		event.Timestamp = *execution.CompletedAt
+	}
+	return event
+}
+
+// executionFinished reports whether the execution has reached a final status
+func executionFinished(execution *repository.Execution) bool {
+	return execution.Status != "pending" && execution.Status != "running"
+}
+
+// eventKey identifies an event for de-duplicating replayed and live events
+func eventKey(event engine.Event) string {
+	return event.Type + "/" + event.TaskID + "/" + strconv.Itoa(event.Attempt)
+}
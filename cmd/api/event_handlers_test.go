@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/metrics"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+type eventsTestSetup struct {
+	router   *gin.Engine
+	execRepo *mockExecutionRepository
+	runner   *workflowRunner
+	blocking *blockingExecutor
+	workflow *repository.Workflow
+}
+
+// setupEventsRouter creates a router whose workflow records one task, then
+// blocks in a second one until the execution is cancelled
+func setupEventsRouter(t *testing.T) *eventsTestSetup {
+	registry := engine.NewRegistry()
+	registry.Register("record", &recordingExecutor{})
+	s := &eventsTestSetup{
+		execRepo: &mockExecutionRepository{},
+		blocking: &blockingExecutor{started: make(chan struct{}, 1)},
+	}
+	registry.Register("block", s.blocking)
+	workflowRepo := newMockWorkflowRepository()
+	taskLogRepo := &mockTaskLogRepository{}
+	executionEngine := engine.NewEngine(registry)
+	s.router = setupRouter(workflowRepo, s.execRepo, taskLogRepo, newMockScheduleRepository(), newMockWebhookRepository(), s.execRepo, executionEngine, metrics.New(), nil)
+	s.runner = newWorkflowRunner(workflowRepo, s.execRepo, taskLogRepo, executionEngine)
+	startWorkers(t, workflowRepo, s.execRepo, taskLogRepo, executionEngine)
+
+	definition, _ := json.Marshal(map[string]interface{}{
+		"name": "watched",
+		"tasks": []interface{}{
+			map[string]interface{}{"id": "first", "type": "record", "config": map[string]interface{}{"name": "first"}},
+			map[string]interface{}{"id": "wait", "type": "block"},
+		},
+	})
+	s.workflow = &repository.Workflow{Name: "watched", Definition: datatypes.JSON(definition)}
+	workflowRepo.Create(s.workflow)
+	return s
+}
+
+// readEvents parses Server-Sent Events from body until it ends
+func readEvents(t *testing.T, body io.Reader) []engine.Event {
+	var events []engine.Event
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		var event engine.Event
+		require.NoError(t, json.Unmarshal([]byte(data), &event))
+		events = append(events, event)
+	}
+	return events
+}
+
+func eventSummary(events []engine.Event) []string {
+	var summary []string
+	for _, event := range events {
+		summary = append(summary, event.Type+":"+event.TaskID+":"+event.Status)
+	}
+	return summary
+}
+
+func TestHandleExecutionEventsStreamsLiveProgress(t *testing.T) {
+	s := setupEventsRouter(t)
+	server := httptest.NewServer(s.router)
+	defer server.Close()
+
+	execution, err := s.runner.Start(context.Background(), s.workflow.ID, nil, nil)
+	require.NoError(t, err)
+	<-s.blocking.started
+
+	// Subscribe while the execution is running: the first task is replayed
+	// from its task log, the rest arrives live
+	resp, err := http.Get(server.URL + "/executions/" + execution.ID.String() + "/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/event-stream")
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		sendJSON(s.router, http.MethodPost, "/executions/"+execution.ID.String()+"/cancel", nil)
+	}()
+
+	events := readEvents(t, resp.Body)
+	assert.Equal(t, []string{
+		"execution_started::running",
+		"task_started:first:running",
+		"task_completed:first:success",
+		"task_started:wait:running",
+		"task_failed:wait:cancelled",
+		"execution_finished::cancelled",
+	}, eventSummary(events))
+	for _, event := range events {
+		assert.Equal(t, execution.ID, event.ExecutionID)
+	}
+}
+
+func TestHandleExecutionEventsReplaysFinishedExecution(t *testing.T) {
+	s := setupEventsRouter(t)
+
+	execution, err := s.runner.Start(context.Background(), s.workflow.ID, nil, nil)
+	require.NoError(t, err)
+	<-s.blocking.started
+	w := sendJSON(s.router, http.MethodPost, "/executions/"+execution.ID.String()+"/cancel", nil)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	assert.Eventually(t, func() bool {
+		stored, _ := s.execRepo.GetByID(execution.ID)
+		return stored.Status == "cancelled"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	w = sendJSON(s.router, http.MethodGet, "/executions/"+execution.ID.String()+"/events", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	events := readEvents(t, w.Body)
+	assert.Equal(t, []string{
+		"execution_started::running",
+		"task_started:first:running",
+		"task_completed:first:success",
+		"task_started:wait:running",
+		"task_failed:wait:cancelled",
+		"execution_finished::cancelled",
+	}, eventSummary(events))
+	assert.Equal(t, "first", events[2].TaskID)
+	assert.Equal(t, 1, events[2].Attempt)
+}
+
+func TestHandleExecutionEventsNotFound(t *testing.T) {
+	s := setupEventsRouter(t)
+
+	w := sendJSON(s.router, http.MethodGet, "/executions/"+uuid.New().String()+"/events", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = sendJSON(s.router, http.MethodGet, "/executions/invalid/events", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -1,12 +1,16 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"strings"
 
 	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
 	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/tracing"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
@@ -18,8 +22,27 @@ type CreateWorkflowRequest struct {
 	Definition map[string]interface{} `json:"definition" binding:"required"`
 }
 
+// ValidateWorkflowRequest represents the request body for validating a workflow definition
+type ValidateWorkflowRequest struct {
+	Definition map[string]interface{} `json:"definition" binding:"required"`
+}
+
+// validateDefinition checks a workflow's definition with engine.Validate and
+// returns the problems found, or nil if it is valid.
+func validateDefinition(workflow *repository.Workflow, registry *engine.Registry) []string {
+	workflowDef, err := workflow.ToWorkflowDefinition()
+	if err != nil {
+		return []string{err.Error()}
+	}
+	var validationErr *engine.ValidationError
+	if err := engine.Validate(*workflowDef, registry); errors.As(err, &validationErr) {
+		return validationErr.Problems
+	}
+	return nil
+}
+
 // handleCreateWorkflow handles POST /workflows
-func handleCreateWorkflow(repo repository.WorkflowRepository) gin.HandlerFunc {
+func handleCreateWorkflow(repo repository.WorkflowRepository, registry *engine.Registry) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req CreateWorkflowRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -40,6 +63,11 @@ func handleCreateWorkflow(repo repository.WorkflowRepository) gin.HandlerFunc {
 			Definition: defJSON,
 		}
 
+		if problems := validateDefinition(workflow, registry); problems != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workflow definition", "details": problems})
+			return
+		}
+
 		if err := repo.Create(workflow); err != nil {
 			// Check for duplicate name
 			if strings.Contains(err.Error(), "already exists") || strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "unique") {
@@ -92,7 +120,7 @@ func handleListWorkflows(repo repository.WorkflowRepository) gin.HandlerFunc {
 }
 
 // handleUpdateWorkflow handles PUT /workflows/:id
-func handleUpdateWorkflow(repo repository.WorkflowRepository) gin.HandlerFunc {
+func handleUpdateWorkflow(repo repository.WorkflowRepository, registry *engine.Registry) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		idParam := c.Param("id")
 		id, err := uuid.Parse(idParam)
@@ -127,6 +155,11 @@ func handleUpdateWorkflow(repo repository.WorkflowRepository) gin.HandlerFunc {
 		}
 		workflow.Definition = datatypes.JSON(defJSONBytes)
 
+		if problems := validateDefinition(workflow, registry); problems != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workflow definition", "details": problems})
+			return
+		}
+
 		if err := repo.Update(workflow); err != nil {
 			if strings.Contains(err.Error(), "already exists") {
 				c.JSON(http.StatusConflict, gin.H{"error": "Workflow with this name already exists"})
@@ -140,6 +173,33 @@ func handleUpdateWorkflow(repo repository.WorkflowRepository) gin.HandlerFunc {
 	}
 }
 
+// handleValidateWorkflow handles POST /workflows/validate. It checks a
+// definition without storing it and reports every problem found.
+func handleValidateWorkflow(registry *engine.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ValidateWorkflowRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+
+		defJSONBytes, err := json.Marshal(req.Definition)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workflow definition"})
+			return
+		}
+
+		name, _ := req.Definition["name"].(string)
+		workflow := &repository.Workflow{Name: name, Definition: datatypes.JSON(defJSONBytes)}
+		if problems := validateDefinition(workflow, registry); problems != nil {
+			c.JSON(http.StatusOK, gin.H{"valid": false, "errors": problems})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"valid": true, "errors": []string{}})
+	}
+}
+
 // handleDeleteWorkflow handles DELETE /workflows/:id
 func handleDeleteWorkflow(repo repository.WorkflowRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -163,13 +223,10 @@ func handleDeleteWorkflow(repo repository.WorkflowRepository) gin.HandlerFunc {
 	}
 }
 
-// handleRunWorkflow handles POST /workflows/:id/run
-func handleRunWorkflow(
-	workflowRepo repository.WorkflowRepository,
-	execRepo repository.ExecutionRepository,
-	taskLogRepo repository.TaskLogRepository,
-	executionEngine *engine.Engine,
-) gin.HandlerFunc {
+// handleRunWorkflow handles POST /workflows/:id/run. The optional JSON object
+// body is validated against the workflow's declared inputs and exposed to
+// tasks under "input".
+func handleRunWorkflow(runner *workflowRunner) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		idParam := c.Param("id")
 		workflowID, err := uuid.Parse(idParam)
@@ -178,48 +235,28 @@ func handleRunWorkflow(
 			return
 		}
 
-		// Load workflow from database
-		workflow, err := workflowRepo.GetByID(workflowID)
+		// The optional JSON body holds the workflow's input values
+		var values map[string]interface{}
+		body, err := io.ReadAll(c.Request.Body)
 		if err != nil {
-			if strings.Contains(err.Error(), "not found") {
-				c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+		if len(bytes.TrimSpace(body)) > 0 {
+			if err := json.Unmarshal(body, &values); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
 				return
 			}
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve workflow"})
-			return
 		}
 
-		// Convert to engine.WorkflowDefinition
-		workflowDef, err := workflow.ToWorkflowDefinition()
+		// A traceparent header makes the execution part of the caller's trace
+		ctx := tracing.ExtractHeaders(c.Request.Context(), c.Request.Header)
+		execution, err := runner.Start(ctx, workflowID, values, nil)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workflow definition", "details": err.Error()})
-			return
-		}
-
-		// Create execution logger adapter
-		logger := repository.NewExecutionLoggerAdapter(execRepo, taskLogRepo)
-
-		// Create execution record first to get the ID
-		execution := &repository.Execution{
-			WorkflowID: workflowID,
-			Status:     "pending",
-		}
-		if err := execRepo.Create(execution); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create execution record"})
+			respondRunError(c, err)
 			return
 		}
 
-		// Execute workflow asynchronously
-		go func(execID uuid.UUID) {
-			// Execute with logging using the execution ID we created
-			execRecord, execErr := executionEngine.ExecuteWithLogging(*workflowDef, workflowID, logger, &execID)
-			if execErr != nil {
-				// Error is already logged in ExecuteWithLogging
-				return
-			}
-			_ = execRecord // Execution is already updated by ExecuteWithLogging
-		}(execution.ID)
-
 		c.JSON(http.StatusAccepted, gin.H{
 			"execution_id": execution.ID,
 			"workflow_id":  workflowID,
@@ -229,6 +266,20 @@ func handleRunWorkflow(
 	}
 }
 
+// respondRunError writes the response for an error from workflowRunner.Start
+func respondRunError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, errWorkflowNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
+	case errors.Is(err, errInvalidDefinition):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workflow definition", "details": err.Error()})
+	case errors.Is(err, errInvalidInputs):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workflow inputs", "details": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start workflow execution", "details": err.Error()})
+	}
+}
+
 // handleGetExecution handles GET /executions/:id
 func handleGetExecution(execRepo repository.ExecutionRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -253,6 +304,160 @@ func handleGetExecution(execRepo repository.ExecutionRepository) gin.HandlerFunc
 	}
 }
 
+// handleCancelExecution handles POST /executions/:id/cancel
+func handleCancelExecution(
+	execRepo repository.ExecutionRepository,
+	executionQueue repository.ExecutionQueue,
+	executionEngine *engine.Engine,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idParam := c.Param("id")
+		executionID, err := uuid.Parse(idParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid execution ID"})
+			return
+		}
+
+		execution, err := execRepo.GetByID(executionID)
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Execution not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve execution"})
+			return
+		}
+
+		if execution.Status != "pending" && execution.Status != "running" {
+			c.JSON(http.StatusConflict, gin.H{"error": "Execution already finished", "status": execution.Status})
+			return
+		}
+
+		// Queued executions are cancelled before any worker claims them
+		if execution.Status == "pending" {
+			cancelled, err := executionQueue.CancelPending(executionID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel execution"})
+				return
+			}
+			if cancelled {
+				c.JSON(http.StatusOK, gin.H{
+					"execution_id": executionID,
+					"status":       "cancelled",
+					"message":      "Execution cancelled before it started",
+				})
+				return
+			}
+		}
+
+		// The engine marks the execution as cancelled once the in-flight task
+		// stops. Executions running on other replicas are cancelled by their
+		// worker when it next records a heartbeat.
+		if !executionEngine.Cancel(executionID) {
+			if execution.ParentExecutionID != nil {
+				c.JSON(http.StatusConflict, gin.H{"error": "Sub-workflow executions are cancelled with their parent", "parent_execution_id": execution.ParentExecutionID})
+				return
+			}
+			requested, err := executionQueue.RequestCancel(executionID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel execution"})
+				return
+			}
+			if !requested {
+				c.JSON(http.StatusConflict, gin.H{"error": "Execution already finished"})
+				return
+			}
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"execution_id": executionID,
+			"status":       "cancelling",
+			"message":      "Execution cancellation requested",
+		})
+	}
+}
+
+// handleResumeExecution handles POST /executions/:id/resume.
+// It queues a new execution of the same workflow, linked to the original,
+// that restores the original's context snapshot and skips the tasks that
+// already succeeded.
+func handleResumeExecution(
+	workflowRepo repository.WorkflowRepository,
+	execRepo repository.ExecutionRepository,
+	taskLogRepo repository.TaskLogRepository,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idParam := c.Param("id")
+		originalID, err := uuid.Parse(idParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid execution ID"})
+			return
+		}
+
+		original, err := execRepo.GetByID(originalID)
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Execution not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve execution"})
+			return
+		}
+
+		switch original.Status {
+		case "failed", "cancelled", "interrupted", "completed_with_errors":
+		default:
+			c.JSON(http.StatusConflict, gin.H{"error": "Only failed, cancelled, interrupted or completed_with_errors executions can be resumed", "status": original.Status})
+			return
+		}
+
+		// Load workflow from database
+		workflow, err := workflowRepo.GetByID(original.WorkflowID)
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve workflow"})
+			return
+		}
+
+		workflowDef, err := workflow.ToWorkflowDefinition()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid workflow definition", "details": err.Error()})
+			return
+		}
+
+		state, err := resumeState(execRepo, taskLogRepo, original, workflowDef)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rebuild execution state", "details": err.Error()})
+			return
+		}
+
+		// Queue the new execution; the worker rebuilds the state when it runs
+		execution := &repository.Execution{
+			WorkflowID:    original.WorkflowID,
+			ResumedFromID: &originalID,
+			Status:        "pending",
+			Input:         original.Input,
+			Trigger:       original.Trigger,
+		}
+		if err := execRepo.Create(execution); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create execution record"})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"execution_id":    execution.ID,
+			"resumed_from_id": originalID,
+			"workflow_id":     original.WorkflowID,
+			"completed_tasks": state.CompletedTasks,
+			"status":          "pending",
+			"message":         "Workflow execution resumed",
+		})
+	}
+}
+
 // handleListExecutions handles GET /executions
 func handleListExecutions(execRepo repository.ExecutionRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -265,3 +470,23 @@ func handleListExecutions(execRepo repository.ExecutionRepository) gin.HandlerFu
 		c.JSON(http.StatusOK, executions)
 	}
 }
+
+// handleListTaskTypes handles GET /task-types
+func handleListTaskTypes(registry *engine.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, registry.DescribeAll())
+	}
+}
+
+// handleGetTaskType handles GET /task-types/:type
+func handleGetTaskType(registry *engine.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		info, err := registry.Describe(c.Param("type"))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task type not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, info)
+	}
+}
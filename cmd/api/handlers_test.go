@@ -2,16 +2,24 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/metrics"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/queue"
 	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/tasks"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
 )
 
 func init() {
@@ -95,14 +103,34 @@ func (e *repositoryError) Error() string {
 }
 
 // mockExecutionRepository for handlers tests
-type mockExecutionRepository struct{}
+type mockExecutionRepository struct {
+	mu         sync.Mutex
+	executions map[uuid.UUID]*repository.Execution
+}
 
 func (m *mockExecutionRepository) Create(execution *repository.Execution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if execution.ID == uuid.Nil {
+		execution.ID = uuid.New()
+	}
+	if m.executions == nil {
+		m.executions = make(map[uuid.UUID]*repository.Execution)
+	}
+	stored := *execution
+	m.executions[execution.ID] = &stored
 	return nil
 }
 
 func (m *mockExecutionRepository) GetByID(id uuid.UUID) (*repository.Execution, error) {
-	return nil, nil
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	execution, exists := m.executions[id]
+	if !exists {
+		return nil, &repositoryError{message: "execution not found: " + id.String()}
+	}
+	found := *execution
+	return &found, nil
 }
 
 func (m *mockExecutionRepository) GetByWorkflowID(workflowID uuid.UUID) ([]*repository.Execution, error) {
@@ -114,22 +142,153 @@ func (m *mockExecutionRepository) GetAll() ([]*repository.Execution, error) {
 }
 
 func (m *mockExecutionRepository) Update(execution *repository.Execution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	previous, exists := m.executions[execution.ID]
+	if !exists {
+		return &repositoryError{message: "execution not found: " + execution.ID.String()}
+	}
+	// Like GormExecutionRepository, Update leaves the queue columns alone
+	stored := *execution
+	stored.Input = previous.Input
+	stored.Trigger = previous.Trigger
+	stored.TraceContext = previous.TraceContext
+	stored.ClaimedBy = previous.ClaimedBy
+	stored.HeartbeatAt = previous.HeartbeatAt
+	stored.CancelRequestedAt = previous.CancelRequestedAt
+	m.executions[execution.ID] = &stored
 	return nil
 }
 
+// mockExecutionRepository is also the ExecutionQueue in handlers tests
+
+func (m *mockExecutionRepository) Claim(workerID string) (*repository.Execution, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, execution := range m.executions {
+		if execution.Status == "pending" && execution.ParentExecutionID == nil {
+			now := time.Now()
+			execution.Status = "running"
+			execution.ClaimedBy = workerID
+			execution.HeartbeatAt = &now
+			claimed := *execution
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockExecutionRepository) Heartbeat(id uuid.UUID, workerID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	execution, exists := m.executions[id]
+	return exists && execution.CancelRequestedAt != nil, nil
+}
+
+func (m *mockExecutionRepository) RequeueStale(before time.Time) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+func (m *mockExecutionRepository) CancelPending(id uuid.UUID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	execution, exists := m.executions[id]
+	if !exists || execution.Status != "pending" {
+		return false, nil
+	}
+	execution.Status = "cancelled"
+	return true, nil
+}
+
+func (m *mockExecutionRepository) RequestCancel(id uuid.UUID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	execution, exists := m.executions[id]
+	if !exists || execution.Status != "running" || execution.ParentExecutionID != nil {
+		return false, nil
+	}
+	now := time.Now()
+	execution.CancelRequestedAt = &now
+	return true, nil
+}
+
+func (m *mockExecutionRepository) Depth() (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var depth int64
+	for _, execution := range m.executions {
+		if execution.Status == "pending" && execution.ParentExecutionID == nil {
+			depth++
+		}
+	}
+	return depth, nil
+}
+
+// startWorkers runs queued executions until the test ends, like the worker
+// pool started in main
+func startWorkers(
+	t *testing.T,
+	workflowRepo repository.WorkflowRepository,
+	execRepo *mockExecutionRepository,
+	taskLogRepo repository.TaskLogRepository,
+	executionEngine *engine.Engine,
+) {
+	runner := newWorkflowRunner(workflowRepo, execRepo, taskLogRepo, executionEngine)
+	workers := queue.NewPool(execRepo, runner.Execute, queue.Config{
+		Size:              2,
+		PollInterval:      5 * time.Millisecond,
+		HeartbeatInterval: 5 * time.Millisecond,
+		Cancel:            executionEngine.Cancel,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		workers.Run(ctx)
+		close(stopped)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-stopped
+	})
+}
+
 // mockTaskLogRepository for handlers tests
-type mockTaskLogRepository struct{}
+type mockTaskLogRepository struct {
+	mu   sync.Mutex
+	logs []*repository.TaskLog
+}
 
 func (m *mockTaskLogRepository) Create(taskLog *repository.TaskLog) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *taskLog
+	m.logs = append(m.logs, &stored)
 	return nil
 }
 
 func (m *mockTaskLogRepository) Update(taskLog *repository.TaskLog) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, existing := range m.logs {
+		if existing.ID == taskLog.ID {
+			stored := *taskLog
+			m.logs[i] = &stored
+		}
+	}
 	return nil
 }
 
 func (m *mockTaskLogRepository) GetByExecutionID(executionID uuid.UUID) ([]*repository.TaskLog, error) {
-	return nil, nil
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var logs []*repository.TaskLog
+	for _, taskLog := range m.logs {
+		if taskLog.ExecutionID == executionID {
+			found := *taskLog
+			logs = append(logs, &found)
+		}
+	}
+	return logs, nil
 }
 
 func TestHandleCreateWorkflow(t *testing.T) {
@@ -137,7 +296,7 @@ func TestHandleCreateWorkflow(t *testing.T) {
 	mockExecRepo := &mockExecutionRepository{}
 	mockTaskLogRepo := &mockTaskLogRepository{}
 	mockEngine := engine.NewEngine(engine.NewRegistry())
-	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, mockEngine)
+	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, mockEngine, metrics.New(), nil)
 
 	reqBody := CreateWorkflowRequest{
 		Name: "test-workflow",
@@ -167,7 +326,7 @@ func TestHandleCreateWorkflowInvalidJSON(t *testing.T) {
 	mockExecRepo := &mockExecutionRepository{}
 	mockTaskLogRepo := &mockTaskLogRepository{}
 	mockEngine := engine.NewEngine(engine.NewRegistry())
-	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, mockEngine)
+	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, mockEngine, metrics.New(), nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/workflows", bytes.NewReader([]byte("invalid json")))
 	req.Header.Set("Content-Type", "application/json")
@@ -183,7 +342,7 @@ func TestHandleGetWorkflow(t *testing.T) {
 	mockExecRepo := &mockExecutionRepository{}
 	mockTaskLogRepo := &mockTaskLogRepository{}
 	mockEngine := engine.NewEngine(engine.NewRegistry())
-	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, mockEngine)
+	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, mockEngine, metrics.New(), nil)
 
 	// Create a workflow first
 	workflow := &repository.Workflow{
@@ -209,7 +368,7 @@ func TestHandleGetWorkflowNotFound(t *testing.T) {
 	mockExecRepo := &mockExecutionRepository{}
 	mockTaskLogRepo := &mockTaskLogRepository{}
 	mockEngine := engine.NewEngine(engine.NewRegistry())
-	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, mockEngine)
+	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, mockEngine, metrics.New(), nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/workflows/"+uuid.New().String(), nil)
 	w := httptest.NewRecorder()
@@ -223,7 +382,7 @@ func TestHandleListWorkflows(t *testing.T) {
 	mockExecRepo := &mockExecutionRepository{}
 	mockTaskLogRepo := &mockTaskLogRepository{}
 	mockEngine := engine.NewEngine(engine.NewRegistry())
-	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, mockEngine)
+	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, mockEngine, metrics.New(), nil)
 
 	// Create some workflows
 	workflow1 := &repository.Workflow{ID: uuid.New(), Name: "workflow-1"}
@@ -247,7 +406,7 @@ func TestHandleDeleteWorkflow(t *testing.T) {
 	mockExecRepo := &mockExecutionRepository{}
 	mockTaskLogRepo := &mockTaskLogRepository{}
 	mockEngine := engine.NewEngine(engine.NewRegistry())
-	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, mockEngine)
+	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, mockEngine, metrics.New(), nil)
 
 	// Create a workflow first
 	workflow := &repository.Workflow{ID: uuid.New(), Name: "test-workflow"}
@@ -259,3 +418,632 @@ func TestHandleDeleteWorkflow(t *testing.T) {
 
 	assert.Equal(t, http.StatusNoContent, w.Code)
 }
+
+// blockingExecutor signals on started and blocks until its context is cancelled
+type blockingExecutor struct {
+	started chan struct{}
+}
+
+func (b *blockingExecutor) Execute(ctx context.Context, execCtx *engine.ExecutionContext, config map[string]interface{}) engine.TaskResult {
+	b.started <- struct{}{}
+	<-ctx.Done()
+	return engine.TaskResult{Status: "failed", Error: ctx.Err().Error()}
+}
+
+func TestHandleCancelExecution(t *testing.T) {
+	repo := newMockWorkflowRepository()
+	mockExecRepo := &mockExecutionRepository{}
+	mockTaskLogRepo := &mockTaskLogRepository{}
+	registry := engine.NewRegistry()
+	blocking := &blockingExecutor{started: make(chan struct{}, 1)}
+	registry.Register("block", blocking)
+	mockEngine := engine.NewEngine(registry)
+	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, mockEngine, metrics.New(), nil)
+
+	execution := &repository.Execution{WorkflowID: uuid.New(), Status: "pending"}
+	mockExecRepo.Create(execution)
+
+	done := make(chan error, 1)
+	go func() {
+		logger := repository.NewExecutionLoggerAdapter(mockExecRepo, mockTaskLogRepo)
+		workflow := engine.WorkflowDefinition{
+			Name:  "blocking",
+			Tasks: []engine.Task{{ID: "wait", Type: "block", Config: map[string]interface{}{}}},
+		}
+		_, err := mockEngine.ExecuteWithLogging(context.Background(), workflow, execution.WorkflowID, logger, &execution.ID)
+		done <- err
+	}()
+	<-blocking.started
+
+	req := httptest.NewRequest(http.MethodPost, "/executions/"+execution.ID.String()+"/cancel", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	stored, err := mockExecRepo.GetByID(execution.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "cancelled", stored.Status)
+}
+
+func TestHandleCancelQueuedExecution(t *testing.T) {
+	repo := newMockWorkflowRepository()
+	mockExecRepo := &mockExecutionRepository{}
+	mockEngine := engine.NewEngine(engine.NewRegistry())
+	router := setupRouter(repo, mockExecRepo, &mockTaskLogRepository{}, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, mockEngine, metrics.New(), nil)
+
+	// No worker has claimed the execution yet
+	execution := &repository.Execution{WorkflowID: uuid.New(), Status: "pending"}
+	mockExecRepo.Create(execution)
+
+	req := httptest.NewRequest(http.MethodPost, "/executions/"+execution.ID.String()+"/cancel", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	stored, err := mockExecRepo.GetByID(execution.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "cancelled", stored.Status)
+
+	claimed, err := mockExecRepo.Claim("worker")
+	assert.NoError(t, err)
+	assert.Nil(t, claimed)
+}
+
+func TestHandleCancelExecutionConflicts(t *testing.T) {
+	repo := newMockWorkflowRepository()
+	mockExecRepo := &mockExecutionRepository{}
+	mockTaskLogRepo := &mockTaskLogRepository{}
+	mockEngine := engine.NewEngine(engine.NewRegistry())
+	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, mockEngine, metrics.New(), nil)
+
+	finished := &repository.Execution{WorkflowID: uuid.New(), Status: "completed"}
+	parentID := uuid.New()
+	child := &repository.Execution{WorkflowID: uuid.New(), Status: "running", ParentExecutionID: &parentID}
+	mockExecRepo.Create(finished)
+	mockExecRepo.Create(child)
+
+	for _, execution := range []*repository.Execution{finished, child} {
+		req := httptest.NewRequest(http.MethodPost, "/executions/"+execution.ID.String()+"/cancel", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code, execution.Status)
+	}
+}
+
+func TestHandleCancelExecutionOnAnotherReplica(t *testing.T) {
+	repo := newMockWorkflowRepository()
+	mockExecRepo := &mockExecutionRepository{}
+	mockTaskLogRepo := &mockTaskLogRepository{}
+	registry := engine.NewRegistry()
+	blocking := &blockingExecutor{started: make(chan struct{}, 1)}
+	registry.Register("block", blocking)
+	// The API and the worker running the execution use different engines,
+	// like two replicas
+	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, engine.NewEngine(registry), metrics.New(), nil)
+	startWorkers(t, repo, mockExecRepo, mockTaskLogRepo, engine.NewEngine(registry))
+
+	definition, _ := json.Marshal(map[string]interface{}{
+		"name":  "blocking",
+		"tasks": []interface{}{map[string]interface{}{"id": "wait", "type": "block", "config": map[string]interface{}{}}},
+	})
+	workflow := &repository.Workflow{Name: "blocking", Definition: datatypes.JSON(definition)}
+	repo.Create(workflow)
+
+	w := sendJSON(router, http.MethodPost, "/workflows/"+workflow.ID.String()+"/run", nil)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var response struct {
+		ExecutionID uuid.UUID `json:"execution_id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	<-blocking.started
+
+	w = sendJSON(router, http.MethodPost, "/executions/"+response.ExecutionID.String()+"/cancel", nil)
+	assert.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	assert.Eventually(t, func() bool {
+		execution, _ := mockExecRepo.GetByID(response.ExecutionID)
+		return execution.Status == "cancelled"
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestHandleCancelExecutionNotFound(t *testing.T) {
+	repo := newMockWorkflowRepository()
+	mockExecRepo := &mockExecutionRepository{}
+	mockTaskLogRepo := &mockTaskLogRepository{}
+	mockEngine := engine.NewEngine(engine.NewRegistry())
+	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, mockEngine, metrics.New(), nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/executions/"+uuid.New().String()+"/cancel", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// recordingExecutor records the config["name"] of every task it runs
+type recordingExecutor struct {
+	mu  sync.Mutex
+	ran []string
+}
+
+func (r *recordingExecutor) Execute(ctx context.Context, execCtx *engine.ExecutionContext, config map[string]interface{}) engine.TaskResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	name, _ := config["name"].(string)
+	r.ran = append(r.ran, name)
+	return engine.TaskResult{Status: "success", Output: name}
+}
+
+func (r *recordingExecutor) names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.ran...)
+}
+
+func TestHandleResumeExecution(t *testing.T) {
+	repo := newMockWorkflowRepository()
+	mockExecRepo := &mockExecutionRepository{}
+	mockTaskLogRepo := &mockTaskLogRepository{}
+	registry := engine.NewRegistry()
+	recorder := &recordingExecutor{}
+	registry.Register("record", recorder)
+	mockEngine := engine.NewEngine(registry)
+	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, mockEngine, metrics.New(), nil)
+	startWorkers(t, repo, mockExecRepo, mockTaskLogRepo, mockEngine)
+
+	definition, _ := json.Marshal(map[string]interface{}{
+		"name": "resumable",
+		"tasks": []interface{}{
+			map[string]interface{}{"id": "first", "type": "record", "config": map[string]interface{}{"name": "first"}},
+			map[string]interface{}{"id": "second", "type": "record", "config": map[string]interface{}{"name": "second"}},
+		},
+	})
+	workflow := &repository.Workflow{Name: "resumable", Definition: datatypes.JSON(definition)}
+	repo.Create(workflow)
+
+	original := &repository.Execution{
+		WorkflowID:      workflow.ID,
+		Status:          "failed",
+		ContextSnapshot: datatypes.JSON(`{"first_result": "from snapshot", "failure": {"task_id": "second"}}`),
+	}
+	mockExecRepo.Create(original)
+	mockTaskLogRepo.Create(&repository.TaskLog{ID: uuid.New(), ExecutionID: original.ID, TaskID: "first", Status: "success"})
+	mockTaskLogRepo.Create(&repository.TaskLog{ID: uuid.New(), ExecutionID: original.ID, TaskID: "second", Status: "failed"})
+
+	req := httptest.NewRequest(http.MethodPost, "/executions/"+original.ID.String()+"/resume", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	var response struct {
+		ExecutionID    uuid.UUID `json:"execution_id"`
+		ResumedFromID  uuid.UUID `json:"resumed_from_id"`
+		CompletedTasks []string  `json:"completed_tasks"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, original.ID, response.ResumedFromID)
+	assert.Equal(t, []string{"first"}, response.CompletedTasks)
+
+	var resumed *repository.Execution
+	assert.Eventually(t, func() bool {
+		resumed, _ = mockExecRepo.GetByID(response.ExecutionID)
+		return resumed != nil && resumed.Status == "completed"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, []string{"second"}, recorder.names())
+	if assert.NotNil(t, resumed.ResumedFromID) {
+		assert.Equal(t, original.ID, *resumed.ResumedFromID)
+	}
+	assert.JSONEq(t, `{"first_result": "from snapshot", "second_result": "second"}`, string(resumed.ContextSnapshot))
+}
+
+func TestHandleResumeExecutionWithoutSnapshot(t *testing.T) {
+	repo := newMockWorkflowRepository()
+	mockExecRepo := &mockExecutionRepository{}
+	mockTaskLogRepo := &mockTaskLogRepository{}
+	registry := engine.NewRegistry()
+	recorder := &recordingExecutor{}
+	registry.Register("record", recorder)
+	mockEngine := engine.NewEngine(registry)
+	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, mockEngine, metrics.New(), nil)
+	startWorkers(t, repo, mockExecRepo, mockTaskLogRepo, mockEngine)
+
+	definition, _ := json.Marshal(map[string]interface{}{
+		"name": "resumable",
+		"tasks": []interface{}{
+			map[string]interface{}{"id": "first", "type": "record", "config": map[string]interface{}{"name": "first"}},
+			map[string]interface{}{"id": "second", "type": "record", "config": map[string]interface{}{"name": "second"}, "depends_on": []string{"first"}},
+		},
+	})
+	workflow := &repository.Workflow{Name: "resumable", Definition: datatypes.JSON(definition)}
+	repo.Create(workflow)
+
+	// Requeued after a crash, then interrupted: the run never wrote a snapshot
+	original := &repository.Execution{
+		WorkflowID: workflow.ID,
+		Status:     "interrupted",
+		Input:      datatypes.JSON(`{"url": "https://example.com"}`),
+		Trigger:    datatypes.JSON(`{"type": "webhook"}`),
+	}
+	mockExecRepo.Create(original)
+	mockTaskLogRepo.Create(&repository.TaskLog{ID: uuid.New(), ExecutionID: original.ID, TaskID: "first", Status: "success", Output: datatypes.JSON(`{"items": [1, 2]}`)})
+	mockTaskLogRepo.Create(&repository.TaskLog{ID: uuid.New(), ExecutionID: original.ID, TaskID: "second", Status: "interrupted"})
+
+	w := sendJSON(router, http.MethodPost, "/executions/"+original.ID.String()+"/resume", nil)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	var response struct {
+		ExecutionID uuid.UUID `json:"execution_id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	var resumed *repository.Execution
+	require.Eventually(t, func() bool {
+		resumed, _ = mockExecRepo.GetByID(response.ExecutionID)
+		return resumed != nil && resumed.Status == "completed"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, []string{"second"}, recorder.names())
+	assert.JSONEq(t, `{"url": "https://example.com"}`, string(resumed.Input))
+	assert.JSONEq(t, `{
+		"input": {"url": "https://example.com"},
+		"trigger": {"type": "webhook"},
+		"first_result": {"items": [1, 2]},
+		"second_result": "second"
+	}`, string(resumed.ContextSnapshot))
+}
+
+// flakyExecutor fails the first failures tasks it runs, then succeeds
+type flakyExecutor struct {
+	mu       sync.Mutex
+	failures int
+	runs     int
+}
+
+func (f *flakyExecutor) Execute(ctx context.Context, execCtx *engine.ExecutionContext, config map[string]interface{}) engine.TaskResult {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.runs++
+	if f.runs <= f.failures {
+		return engine.TaskResult{Status: "failed", Error: "flaky"}
+	}
+	return engine.TaskResult{Status: "success", Output: "ok"}
+}
+
+func TestHandleResumeExecutionTwice(t *testing.T) {
+	repo := newMockWorkflowRepository()
+	mockExecRepo := &mockExecutionRepository{}
+	mockTaskLogRepo := &mockTaskLogRepository{}
+	registry := engine.NewRegistry()
+	recorder := &recordingExecutor{}
+	flaky := &flakyExecutor{failures: 1}
+	registry.Register("record", recorder)
+	registry.Register("flaky", flaky)
+	mockEngine := engine.NewEngine(registry)
+	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, mockEngine, metrics.New(), nil)
+	startWorkers(t, repo, mockExecRepo, mockTaskLogRepo, mockEngine)
+
+	definition, _ := json.Marshal(map[string]interface{}{
+		"name": "resumable",
+		"tasks": []interface{}{
+			map[string]interface{}{"id": "first", "type": "record", "config": map[string]interface{}{"name": "first"}},
+			map[string]interface{}{"id": "second", "type": "record", "config": map[string]interface{}{"name": "second"}, "depends_on": []string{"first"}},
+			map[string]interface{}{"id": "third", "type": "flaky", "config": map[string]interface{}{}, "depends_on": []string{"second"}},
+		},
+	})
+	workflow := &repository.Workflow{Name: "resumable", Definition: datatypes.JSON(definition)}
+	repo.Create(workflow)
+
+	original := &repository.Execution{WorkflowID: workflow.ID, Status: "failed", ContextSnapshot: datatypes.JSON(`{"first_result": "first"}`)}
+	mockExecRepo.Create(original)
+	mockTaskLogRepo.Create(&repository.TaskLog{ID: uuid.New(), ExecutionID: original.ID, TaskID: "first", Status: "success"})
+	mockTaskLogRepo.Create(&repository.TaskLog{ID: uuid.New(), ExecutionID: original.ID, TaskID: "second", Status: "failed"})
+
+	resume := func(id uuid.UUID, wantStatus string, wantCompleted []string) *repository.Execution {
+		t.Helper()
+		w := sendJSON(router, http.MethodPost, "/executions/"+id.String()+"/resume", nil)
+		require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+		var response struct {
+			ExecutionID    uuid.UUID `json:"execution_id"`
+			CompletedTasks []string  `json:"completed_tasks"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, wantCompleted, response.CompletedTasks)
+
+		var resumed *repository.Execution
+		require.Eventually(t, func() bool {
+			resumed, _ = mockExecRepo.GetByID(response.ExecutionID)
+			return resumed != nil && resumed.Status == wantStatus
+		}, 2*time.Second, 10*time.Millisecond)
+		return resumed
+	}
+
+	// The first resume runs second, then third fails
+	resumed := resume(original.ID, "failed", []string{"first"})
+	// first has no TaskLog in the resumed execution but is still not run again
+	resume(resumed.ID, "completed", []string{"first", "second"})
+
+	assert.Equal(t, []string{"second"}, recorder.names())
+	assert.Equal(t, 2, flaky.runs)
+}
+
+func TestHandleResumeExecutionConflicts(t *testing.T) {
+	repo := newMockWorkflowRepository()
+	mockExecRepo := &mockExecutionRepository{}
+	mockTaskLogRepo := &mockTaskLogRepository{}
+	mockEngine := engine.NewEngine(engine.NewRegistry())
+	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, mockEngine, metrics.New(), nil)
+
+	for _, status := range []string{"pending", "running", "completed"} {
+		execution := &repository.Execution{WorkflowID: uuid.New(), Status: status}
+		mockExecRepo.Create(execution)
+
+		req := httptest.NewRequest(http.MethodPost, "/executions/"+execution.ID.String()+"/resume", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code, status)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/executions/"+uuid.New().String()+"/resume", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// inputsWorkflow stores a workflow that declares a required and a defaulted input
+func inputsWorkflow(repo *mockWorkflowRepositoryForHandlers) *repository.Workflow {
+	definition, _ := json.Marshal(map[string]interface{}{
+		"name": "with-inputs",
+		"inputs": []interface{}{
+			map[string]interface{}{"name": "url", "type": "string", "required": true},
+			map[string]interface{}{"name": "limit", "type": "integer", "default": 10},
+		},
+		"tasks": []interface{}{
+			map[string]interface{}{"id": "first", "type": "record", "config": map[string]interface{}{"name": "first"}},
+		},
+	})
+	workflow := &repository.Workflow{Name: "with-inputs", Definition: datatypes.JSON(definition)}
+	repo.Create(workflow)
+	return workflow
+}
+
+func TestHandleRunWorkflowWithInputs(t *testing.T) {
+	repo := newMockWorkflowRepository()
+	mockExecRepo := &mockExecutionRepository{}
+	mockTaskLogRepo := &mockTaskLogRepository{}
+	registry := engine.NewRegistry()
+	registry.Register("record", &recordingExecutor{})
+	executionEngine := engine.NewEngine(registry)
+	router := setupRouter(repo, mockExecRepo, mockTaskLogRepo, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, executionEngine, metrics.New(), nil)
+	startWorkers(t, repo, mockExecRepo, mockTaskLogRepo, executionEngine)
+	workflow := inputsWorkflow(repo)
+
+	req := httptest.NewRequest(http.MethodPost, "/workflows/"+workflow.ID.String()+"/run",
+		bytes.NewBufferString(`{"url": "http://example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	var response struct {
+		ExecutionID uuid.UUID `json:"execution_id"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	var execution *repository.Execution
+	assert.Eventually(t, func() bool {
+		execution, _ = mockExecRepo.GetByID(response.ExecutionID)
+		return execution != nil && execution.Status == "completed"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	var snapshot map[string]interface{}
+	assert.NoError(t, json.Unmarshal(execution.ContextSnapshot, &snapshot))
+	assert.Equal(t, map[string]interface{}{"url": "http://example.com", "limit": float64(10)}, snapshot["input"])
+}
+
+func TestHandleRunWorkflowStoresTraceparent(t *testing.T) {
+	repo := newMockWorkflowRepository()
+	mockExecRepo := &mockExecutionRepository{}
+	router := setupRouter(repo, mockExecRepo, &mockTaskLogRepository{}, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, engine.NewEngine(engine.NewRegistry()), metrics.New(), nil)
+	workflow := inputsWorkflow(repo)
+
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req := httptest.NewRequest(http.MethodPost, "/workflows/"+workflow.ID.String()+"/run",
+		bytes.NewBufferString(`{"url": "http://example.com"}`))
+	req.Header.Set("traceparent", traceparent)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	var response struct {
+		ExecutionID uuid.UUID `json:"execution_id"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	execution, err := mockExecRepo.GetByID(response.ExecutionID)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"traceparent": "`+traceparent+`"}`, string(execution.TraceContext))
+}
+
+func TestHandleRunWorkflowInvalidInputs(t *testing.T) {
+	repo := newMockWorkflowRepository()
+	mockExecRepo := &mockExecutionRepository{}
+	router := setupRouter(repo, mockExecRepo, &mockTaskLogRepository{}, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, engine.NewEngine(engine.NewRegistry()), metrics.New(), nil)
+	workflow := inputsWorkflow(repo)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"missing body", ""},
+		{"missing required input", `{"limit": 5}`},
+		{"wrong type", `{"url": "http://example.com", "limit": "five"}`},
+		{"unknown input", `{"url": "http://example.com", "verbose": true}`},
+		{"not an object", `["http://example.com"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/workflows/"+workflow.ID.String()+"/run",
+				bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+
+	assert.Empty(t, mockExecRepo.executions)
+}
+
+func TestHandleCreateWorkflowInvalidDefinition(t *testing.T) {
+	repo := newMockWorkflowRepository()
+	registry := engine.NewRegistry()
+	registry.Register("record", &recordingExecutor{})
+	router := setupRouter(repo, &mockExecutionRepository{}, &mockTaskLogRepository{}, newMockScheduleRepository(), newMockWebhookRepository(), &mockExecutionRepository{}, engine.NewEngine(registry), metrics.New(), nil)
+
+	bodyBytes, _ := json.Marshal(CreateWorkflowRequest{
+		Name: "broken",
+		Definition: map[string]interface{}{
+			"tasks": []interface{}{
+				map[string]interface{}{"id": "a", "type": "record"},
+				map[string]interface{}{"id": "a", "type": "unknown"},
+			},
+		},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/workflows", bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response struct {
+		Details []string `json:"details"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{"duplicate task id 'a'", "task 'a' has unknown type 'unknown'"}, response.Details)
+	assert.Empty(t, repo.workflows)
+}
+
+func TestHandleUpdateWorkflowInvalidDefinition(t *testing.T) {
+	repo := newMockWorkflowRepository()
+	router := setupRouter(repo, &mockExecutionRepository{}, &mockTaskLogRepository{}, newMockScheduleRepository(), newMockWebhookRepository(), &mockExecutionRepository{}, engine.NewEngine(engine.NewRegistry()), metrics.New(), nil)
+	workflow := &repository.Workflow{Name: "existing", Definition: datatypes.JSON(`{"tasks": []}`)}
+	repo.Create(workflow)
+
+	bodyBytes, _ := json.Marshal(CreateWorkflowRequest{
+		Name: "existing",
+		Definition: map[string]interface{}{
+			"tasks": []interface{}{map[string]interface{}{"id": "a", "type": "unknown"}},
+		},
+	})
+	req := httptest.NewRequest(http.MethodPut, "/workflows/"+workflow.ID.String(), bytes.NewReader(bodyBytes))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "task 'a' has unknown type 'unknown'")
+}
+
+func TestHandleValidateWorkflow(t *testing.T) {
+	registry := engine.NewRegistry()
+	registry.Register("record", &recordingExecutor{})
+	router := setupRouter(newMockWorkflowRepository(), &mockExecutionRepository{}, &mockTaskLogRepository{}, newMockScheduleRepository(), newMockWebhookRepository(), &mockExecutionRepository{}, engine.NewEngine(registry), metrics.New(), nil)
+
+	tests := []struct {
+		name       string
+		definition map[string]interface{}
+		valid      bool
+		errors     []string
+	}{
+		{
+			name: "valid",
+			definition: map[string]interface{}{
+				"tasks": []interface{}{map[string]interface{}{"id": "a", "type": "record"}},
+			},
+			valid:  true,
+			errors: []string{},
+		},
+		{
+			name: "unknown result reference",
+			definition: map[string]interface{}{
+				"tasks": []interface{}{map[string]interface{}{
+					"id": "a", "type": "record", "config": map[string]interface{}{"data_source": "fetch_result"},
+				}},
+			},
+			errors: []string{"task 'a' references 'fetch_result' but the workflow has no task 'fetch'"},
+		},
+		{
+			name:       "malformed tasks",
+			definition: map[string]interface{}{"tasks": "not a list"},
+			errors:     []string{"json: cannot unmarshal string into Go struct field WorkflowDefinition.tasks of type []engine.Task"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodyBytes, _ := json.Marshal(ValidateWorkflowRequest{Definition: tt.definition})
+			req := httptest.NewRequest(http.MethodPost, "/workflows/validate", bytes.NewReader(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var response struct {
+				Valid  bool     `json:"valid"`
+				Errors []string `json:"errors"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.valid, response.Valid)
+			assert.Equal(t, tt.errors, response.Errors)
+		})
+	}
+}
+
+func TestHandleTaskTypes(t *testing.T) {
+	registry := engine.NewRegistry()
+	registry.Register("record", &recordingExecutor{})
+	tasks.RegisterHTTPTask(registry)
+	router := setupRouter(newMockWorkflowRepository(), &mockExecutionRepository{}, &mockTaskLogRepository{}, newMockScheduleRepository(), newMockWebhookRepository(), &mockExecutionRepository{}, engine.NewEngine(registry), metrics.New(), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/task-types", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list []engine.TaskTypeInfo
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	if assert.Len(t, list, 2) {
+		assert.Equal(t, "http_request", list[0].Type)
+		assert.NotNil(t, list[0].Schema)
+		assert.Equal(t, "record", list[1].Type)
+		assert.Nil(t, list[1].Schema)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/task-types/http_request", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var info engine.TaskTypeInfo
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+	if assert.NotNil(t, info.Schema) {
+		assert.ElementsMatch(t, []interface{}{"method", "url"}, info.Schema.Config["required"])
+		assert.Contains(t, info.Schema.Output["properties"], "status_code")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/task-types/ftp", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
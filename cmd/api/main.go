@@ -1,19 +1,38 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+	_ "time/tzdata" // schedules may use any IANA timezone, even without system zoneinfo
 
 	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/metrics"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/queue"
 	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/scheduler"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/secrets"
 	"github.com/davioliveira/rest_api_automation_hub_go/internal/tasks"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/tracing"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
+// defaultDrainTimeout is how long shutdown waits for in-flight executions
+const defaultDrainTimeout = 30 * time.Second
+
 func main() {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	// Secret values resolved by tasks are masked in the logs
+	secretMasker := secrets.NewMasker()
+	logger := slog.New(secretMasker.LogHandler(slog.NewJSONHandler(os.Stdout, nil)))
 	slog.SetDefault(logger)
 
 	// Initialize database
@@ -31,50 +50,203 @@ func main() {
 	workflowRepo := repository.NewWorkflowRepository(repository.DB)
 	execRepo := repository.NewExecutionRepository(repository.DB)
 	taskLogRepo := repository.NewTaskLogRepository(repository.DB)
+	scheduleRepo := repository.NewScheduleRepository(repository.DB)
+	webhookRepo := repository.NewWebhookRepository(repository.DB)
+	executionQueue := repository.NewExecutionQueue(repository.DB)
+
+	// Secrets are only available with a master key
+	var secretStore *secrets.Store
+	masterKey, err := secrets.MasterKeyFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to initialize secrets: %v", err)
+	}
+	if masterKey != nil {
+		if secretStore, err = secrets.NewStore(repository.NewSecretRepository(repository.DB), masterKey); err != nil {
+			log.Fatalf("Failed to initialize secrets: %v", err)
+		}
+	} else {
+		slog.Warn("Secrets are disabled", "reason", secrets.MasterKeyEnv+" is not set")
+	}
+
+	// Prometheus metrics, served at /metrics
+	appMetrics := metrics.New()
+	appMetrics.ObserveQueue(executionQueue)
+
+	// OpenTelemetry tracing, exported as configured by OTEL_TRACES_EXPORTER
+	traceProvider, err := tracing.NewProvider(context.Background(), tracing.ConfigFromEnv())
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	defer func() {
+		if err := traceProvider.Shutdown(context.Background()); err != nil {
+			slog.Error("Failed to flush traces", "error", err)
+		}
+	}()
+	tracing.SetGlobal(traceProvider)
+	tracer := tracing.New(traceProvider)
 
 	// Initialize task registry
 	registry := engine.NewRegistry()
 
-	// Register task executors
-	tasks.RegisterHTTPTask(registry)      // Story 2.1
-	tasks.RegisterTransformTask(registry) // Story 2.2
+	// Register task executors; outbound HTTP requests are traced and recorded in the metrics
+	tasks.RegisterHTTPTaskWithTransport(registry, tracer.InstrumentTransport(appMetrics.InstrumentTransport(nil)))
+	tasks.RegisterTransformTask(registry)  // Story 2.2
 	tasks.RegisterHTMLParserTask(registry) // Story 2.3
+	tasks.RegisterWorkflowTask(registry, workflowRepo)
 
 	// Create engine with registry
 	executionEngine := engine.NewEngine(registry)
+	// http_request tasks of an execution share OAuth2 tokens and cookies
+	tasks.InstallHTTPSessions(executionEngine)
+	appMetrics.Instrument(executionEngine)
+	tracer.Instrument(executionEngine)
+	// Last, so the middleware above only sees masked task results
+	secrets.Install(executionEngine, secretStore, secretMasker)
 
-	router := setupRouter(workflowRepo, execRepo, taskLogRepo, executionEngine)
-	port := getPort()
+	// Stop on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	slog.Info("Starting GoAutomation Hub API Server", "port", port)
-	if err := router.Run(":" + port); err != nil {
+	// Start the worker pool that runs queued executions
+	runner := newWorkflowRunner(workflowRepo, execRepo, taskLogRepo, executionEngine)
+	workers := queue.NewPool(executionQueue, runner.Execute, queue.Config{Size: getWorkerPoolSize(), Cancel: executionEngine.Cancel})
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Run(ctx)
+		close(workersDone)
+	}()
+
+	// Start the scheduler; scheduled runs take the same path as POST /workflows/:id/run
+	cronScheduler := scheduler.New(scheduleRepo, execRepo, func(workflowID uuid.UUID, input map[string]interface{}) (uuid.UUID, error) {
+		execution, err := runner.Start(ctx, workflowID, input, nil)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return execution.ID, nil
+	})
+	go cronScheduler.Run(ctx)
+
+	router := setupRouter(workflowRepo, execRepo, taskLogRepo, scheduleRepo, webhookRepo, executionQueue, executionEngine, appMetrics, secretStore)
+	// Request contexts end as soon as shutdown starts, so open event streams
+	// don't hold it up; other handlers don't depend on their context
+	requestCtx, cancelRequests := context.WithCancel(context.Background())
+	server := &http.Server{
+		Addr:        ":" + getPort(),
+		Handler:     router,
+		BaseContext: func(net.Listener) context.Context { return requestCtx },
+	}
+	server.RegisterOnShutdown(cancelRequests)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Starting GoAutomation Hub API Server", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	shutdown(server, executionEngine, workersDone, getDrainTimeout())
+
+	select {
+	case err := <-serverErr:
 		slog.Error("Failed to start server", "error", err)
 		os.Exit(1)
+	default:
+	}
+}
+
+// shutdown stops accepting requests, then waits up to drainTimeout for
+// in-flight executions to finish. Executions still running after that are
+// interrupted, so they can be resumed later. The worker pool and scheduler
+// must already have been told to stop.
+func shutdown(server *http.Server, executionEngine *engine.Engine, workersDone <-chan struct{}, drainTimeout time.Duration) {
+	slog.Info("Shutting down", "drain_timeout", drainTimeout)
+	drainCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(drainCtx); err != nil {
+		slog.Error("Failed to shut down HTTP server cleanly", "error", err)
+	}
+
+	// The worker pool returns once its in-flight executions finish
+	select {
+	case <-workersDone:
+	case <-drainCtx.Done():
+		// Drain interrupts whatever is still running and waits for it to be recorded
+		_ = executionEngine.Drain(drainCtx)
+		<-workersDone
+		slog.Warn("Drain timeout reached; in-flight executions were interrupted")
 	}
+
+	slog.Info("Shutdown complete")
 }
 
 func setupRouter(
 	workflowRepo repository.WorkflowRepository,
 	execRepo repository.ExecutionRepository,
 	taskLogRepo repository.TaskLogRepository,
+	scheduleRepo repository.ScheduleRepository,
+	webhookRepo repository.WebhookRepository,
+	executionQueue repository.ExecutionQueue,
 	executionEngine *engine.Engine,
+	appMetrics *metrics.Metrics,
+	secretStore *secrets.Store,
 ) *gin.Engine {
-	router := gin.Default()
+	router := gin.New()
+	router.Use(gin.LoggerWithFormatter(accessLogFormatter), gin.Recovery())
+	router.Use(appMetrics.GinMiddleware())
+	runner := newWorkflowRunner(workflowRepo, execRepo, taskLogRepo, executionEngine)
 
 	// Health endpoint (includes database check)
 	router.GET("/health", healthHandler)
 
+	// Prometheus metrics
+	router.GET("/metrics", gin.WrapH(appMetrics.Handler()))
+
 	// Workflow endpoints
-	router.POST("/workflows", handleCreateWorkflow(workflowRepo))
+	router.POST("/workflows", handleCreateWorkflow(workflowRepo, executionEngine.Registry()))
+	router.POST("/workflows/validate", handleValidateWorkflow(executionEngine.Registry()))
 	router.GET("/workflows/:id", handleGetWorkflow(workflowRepo))
 	router.GET("/workflows", handleListWorkflows(workflowRepo))
-	router.PUT("/workflows/:id", handleUpdateWorkflow(workflowRepo))
+	router.PUT("/workflows/:id", handleUpdateWorkflow(workflowRepo, executionEngine.Registry()))
 	router.DELETE("/workflows/:id", handleDeleteWorkflow(workflowRepo))
 
 	// Execution endpoints (Story 3.2)
-	router.POST("/workflows/:id/run", handleRunWorkflow(workflowRepo, execRepo, taskLogRepo, executionEngine))
+	router.POST("/workflows/:id/run", handleRunWorkflow(runner))
 	router.GET("/executions", handleListExecutions(execRepo))
 	router.GET("/executions/:id", handleGetExecution(execRepo))
+	router.POST("/executions/:id/cancel", handleCancelExecution(execRepo, executionQueue, executionEngine))
+	router.POST("/executions/:id/resume", handleResumeExecution(workflowRepo, execRepo, taskLogRepo))
+	router.GET("/executions/:id/events", handleExecutionEvents(execRepo, taskLogRepo, executionEngine.Events()))
+
+	// Schedule endpoints
+	router.POST("/schedules", handleCreateSchedule(scheduleRepo, workflowRepo))
+	router.GET("/schedules", handleListSchedules(scheduleRepo))
+	router.GET("/schedules/:id", handleGetSchedule(scheduleRepo))
+	router.PUT("/schedules/:id", handleUpdateSchedule(scheduleRepo, workflowRepo))
+	router.DELETE("/schedules/:id", handleDeleteSchedule(scheduleRepo))
+
+	// Webhook trigger endpoints
+	router.POST("/webhooks", handleCreateWebhook(webhookRepo, workflowRepo, secretStore))
+	router.GET("/webhooks", handleListWebhooks(webhookRepo))
+	router.GET("/webhooks/:id", handleGetWebhook(webhookRepo))
+	router.PUT("/webhooks/:id", handleUpdateWebhook(webhookRepo, workflowRepo, secretStore))
+	router.DELETE("/webhooks/:id", handleDeleteWebhook(webhookRepo))
+	router.POST("/webhooks/:id/rotate", handleRotateWebhookToken(webhookRepo))
+	router.POST("/hooks/:token", handleTriggerWebhook(webhookRepo, runner, secretStore))
+
+	// Secret endpoints; values are write-only
+	router.POST("/secrets", handleCreateSecret(secretStore))
+	router.GET("/secrets", handleListSecrets(secretStore))
+	router.GET("/secrets/:id", handleGetSecret(secretStore))
+	router.PUT("/secrets/:id", handleUpdateSecret(secretStore))
+	router.DELETE("/secrets/:id", handleDeleteSecret(secretStore))
+
+	// Task type endpoints
+	router.GET("/task-types", handleListTaskTypes(executionEngine.Registry()))
+	router.GET("/task-types/:type", handleGetTaskType(executionEngine.Registry()))
 
 	return router
 }
@@ -87,6 +259,36 @@ func getPort() string {
 	return port
 }
 
+// getDrainTimeout returns how long shutdown waits for in-flight executions,
+// from SHUTDOWN_DRAIN_TIMEOUT (a duration such as "45s")
+func getDrainTimeout() time.Duration {
+	value := os.Getenv("SHUTDOWN_DRAIN_TIMEOUT")
+	if value == "" {
+		return defaultDrainTimeout
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		slog.Warn("Invalid SHUTDOWN_DRAIN_TIMEOUT, using default", "value", value, "default", defaultDrainTimeout)
+		return defaultDrainTimeout
+	}
+	return timeout
+}
+
+// getWorkerPoolSize returns the number of executions run at once, from
+// WORKER_POOL_SIZE
+func getWorkerPoolSize() int {
+	value := os.Getenv("WORKER_POOL_SIZE")
+	if value == "" {
+		return queue.DefaultPoolSize
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil || size < 1 {
+		slog.Warn("Invalid WORKER_POOL_SIZE, using default", "value", value, "default", queue.DefaultPoolSize)
+		return queue.DefaultPoolSize
+	}
+	return size
+}
+
 func healthHandler(c *gin.Context) {
 	slog.Info("Health check requested")
 	dbHealthy := repository.HealthCheck() == nil
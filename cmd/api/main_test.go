@@ -1,17 +1,22 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/metrics"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/queue"
 	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
 )
 
 func init() {
@@ -52,7 +57,7 @@ func createTestRouter() *gin.Engine {
 	mockExecRepo := &mockExecutionRepository{}
 	mockTaskLogRepo := &mockTaskLogRepository{}
 	mockEngine := engine.NewEngine(engine.NewRegistry())
-	return setupRouter(mockWorkflowRepo, mockExecRepo, mockTaskLogRepo, mockEngine)
+	return setupRouter(mockWorkflowRepo, mockExecRepo, mockTaskLogRepo, newMockScheduleRepository(), newMockWebhookRepository(), mockExecRepo, mockEngine, metrics.New(), nil)
 }
 
 func TestHealthEndpoint(t *testing.T) {
@@ -110,6 +115,18 @@ func TestSetupRouter(t *testing.T) {
 	assert.True(t, w.Code == http.StatusOK || w.Code == http.StatusInternalServerError)
 }
 
+func TestMetricsEndpoint(t *testing.T) {
+	router := createTestRouter()
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, w.Body.String(), `automation_hub_api_requests_total{method="GET",route="/health"`)
+}
+
 func TestGetPortDefault(t *testing.T) {
 	os.Unsetenv("PORT")
 	port := getPort()
@@ -123,3 +140,55 @@ func TestGetPortFromEnv(t *testing.T) {
 	port := getPort()
 	assert.Equal(t, "3000", port, "Port should be read from environment variable")
 }
+
+func TestGetDrainTimeout(t *testing.T) {
+	os.Unsetenv("SHUTDOWN_DRAIN_TIMEOUT")
+	assert.Equal(t, defaultDrainTimeout, getDrainTimeout())
+
+	os.Setenv("SHUTDOWN_DRAIN_TIMEOUT", "45s")
+	defer os.Unsetenv("SHUTDOWN_DRAIN_TIMEOUT")
+	assert.Equal(t, 45*time.Second, getDrainTimeout())
+
+	os.Setenv("SHUTDOWN_DRAIN_TIMEOUT", "soon")
+	assert.Equal(t, defaultDrainTimeout, getDrainTimeout())
+}
+
+func TestShutdownInterruptsExecutionsAfterDrainTimeout(t *testing.T) {
+	repo := newMockWorkflowRepository()
+	mockExecRepo := &mockExecutionRepository{}
+	mockTaskLogRepo := &mockTaskLogRepository{}
+	registry := engine.NewRegistry()
+	blocking := &blockingExecutor{started: make(chan struct{}, 1)}
+	registry.Register("block", blocking)
+	executionEngine := engine.NewEngine(registry)
+
+	definition, _ := json.Marshal(map[string]interface{}{
+		"name":  "long-running",
+		"tasks": []interface{}{map[string]interface{}{"id": "wait", "type": "block"}},
+	})
+	workflow := &repository.Workflow{Name: "long-running", Definition: datatypes.JSON(definition)}
+	repo.Create(workflow)
+
+	runner := newWorkflowRunner(repo, mockExecRepo, mockTaskLogRepo, executionEngine)
+	execution, err := runner.Start(context.Background(), workflow.ID, nil, nil)
+	assert.NoError(t, err)
+
+	ctx, stop := context.WithCancel(context.Background())
+	workers := queue.NewPool(mockExecRepo, runner.Execute, queue.Config{Size: 1, PollInterval: 5 * time.Millisecond})
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Run(ctx)
+		close(workersDone)
+	}()
+	<-blocking.started
+
+	// As on SIGTERM: stop the workers, then drain
+	stop()
+	start := time.Now()
+	shutdown(&http.Server{}, executionEngine, workersDone, 50*time.Millisecond)
+	assert.Less(t, time.Since(start), time.Second)
+
+	stored, err := mockExecRepo.GetByID(execution.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "interrupted", stored.Status)
+}
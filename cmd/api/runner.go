@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/tracing"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Errors returned by workflowRunner.Start
+var (
+	errWorkflowNotFound  = errors.New("workflow not found")
+	errInvalidDefinition = errors.New("invalid workflow definition")
+	errInvalidInputs     = errors.New("invalid workflow inputs")
+)
+
+// runError tags an error from workflowRunner.Start with one of the errors above
+// while keeping the original message.
+type runError struct {
+	kind error
+	err  error
+}
+
+func (e *runError) Error() string {
+	return e.err.Error()
+}
+
+func (e *runError) Is(target error) bool {
+	return target == e.kind
+}
+
+// workflowRunner queues workflow executions and runs them for the worker
+// pool. It is the single path through which the run endpoint, the scheduler
+// and webhook triggers create executions.
+type workflowRunner struct {
+	workflows       repository.WorkflowRepository
+	executions      repository.ExecutionRepository
+	taskLogs        repository.TaskLogRepository
+	executionEngine *engine.Engine
+}
+
+// newWorkflowRunner creates a workflowRunner
+func newWorkflowRunner(
+	workflowRepo repository.WorkflowRepository,
+	execRepo repository.ExecutionRepository,
+	taskLogRepo repository.TaskLogRepository,
+	executionEngine *engine.Engine,
+) *workflowRunner {
+	return &workflowRunner{
+		workflows:       workflowRepo,
+		executions:      execRepo,
+		taskLogs:        taskLogRepo,
+		executionEngine: executionEngine,
+	}
+}
+
+// Start validates values against the workflow's declared inputs and queues a
+// pending execution for the worker pool (see Execute). trigger, when not nil,
+// is exposed to tasks under "trigger". The trace context of ctx, if any, is
+// stored with the execution so its spans join the caller's trace. When the
+// caller is at fault the error matches errWorkflowNotFound,
+// errInvalidDefinition or errInvalidInputs (see errors.Is).
+func (r *workflowRunner) Start(ctx context.Context, workflowID uuid.UUID, values, trigger map[string]interface{}) (*repository.Execution, error) {
+	// Load workflow from database
+	workflow, err := r.workflows.GetByID(workflowID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, &runError{kind: errWorkflowNotFound, err: err}
+		}
+		return nil, fmt.Errorf("failed to retrieve workflow: %w", err)
+	}
+
+	// Convert to engine.WorkflowDefinition
+	workflowDef, err := workflow.ToWorkflowDefinition()
+	if err != nil {
+		return nil, &runError{kind: errInvalidDefinition, err: err}
+	}
+
+	input, err := workflowDef.ResolveInputs(values)
+	if err != nil {
+		return nil, &runError{kind: errInvalidInputs, err: err}
+	}
+
+	execution := &repository.Execution{
+		WorkflowID: workflowID,
+		Status:     "pending",
+	}
+	if execution.Input, err = marshalJSON(input); err != nil {
+		return nil, &runError{kind: errInvalidInputs, err: err}
+	}
+	if execution.Trigger, err = marshalJSON(trigger); err != nil {
+		return nil, fmt.Errorf("failed to encode trigger: %w", err)
+	}
+	if carrier := tracing.Inject(ctx); carrier != nil {
+		if execution.TraceContext, err = json.Marshal(carrier); err != nil {
+			return nil, fmt.Errorf("failed to encode trace context: %w", err)
+		}
+	}
+	if err := r.executions.Create(execution); err != nil {
+		return nil, fmt.Errorf("failed to create execution record: %w", err)
+	}
+
+	return execution, nil
+}
+
+// Execute runs an execution claimed from the queue. Executions that resume
+// another one restart from its first unsuccessful task (see resumeState).
+// Problems found before the engine starts are recorded as a failed execution.
+func (r *workflowRunner) Execute(ctx context.Context, execution *repository.Execution) {
+	if err := r.execute(ctx, execution); err != nil {
+		slog.Error("Failed to start queued execution", "execution_id", execution.ID, "error", err)
+		completedAt := time.Now().UTC()
+		execution.Status = "failed"
+		execution.CompletedAt = &completedAt
+		if err := r.executions.Update(execution); err != nil {
+			slog.Error("Failed to record execution failure", "execution_id", execution.ID, "error", err)
+		}
+	}
+}
+
+// execute runs execution, returning an error only if the engine could not start it
+func (r *workflowRunner) execute(ctx context.Context, execution *repository.Execution) error {
+	if len(execution.TraceContext) > 0 {
+		var carrier map[string]string
+		if err := json.Unmarshal(execution.TraceContext, &carrier); err != nil {
+			// The execution can run without its caller's trace
+			slog.Warn("Invalid execution trace context", "execution_id", execution.ID, "error", err)
+		}
+		ctx = tracing.Extract(ctx, carrier)
+	}
+
+	workflow, err := r.workflows.GetByID(execution.WorkflowID)
+	if err != nil {
+		return err
+	}
+	workflowDef, err := workflow.ToWorkflowDefinition()
+	if err != nil {
+		return err
+	}
+
+	logger := repository.NewExecutionLoggerAdapter(r.executions, r.taskLogs)
+
+	if execution.ResumedFromID != nil {
+		original, err := r.executions.GetByID(*execution.ResumedFromID)
+		if err != nil {
+			return err
+		}
+		state, err := resumeState(r.executions, r.taskLogs, original, workflowDef)
+		if err != nil {
+			return err
+		}
+		// Errors are already logged and recorded by ResumeWithLogging
+		_, _ = r.executionEngine.ResumeWithLogging(ctx, *workflowDef, execution.WorkflowID, logger, &execution.ID, state)
+		return nil
+	}
+
+	var input, trigger map[string]interface{}
+	if len(execution.Input) > 0 {
+		if err := json.Unmarshal(execution.Input, &input); err != nil {
+			return fmt.Errorf("invalid execution input: %w", err)
+		}
+	}
+	if len(execution.Trigger) > 0 {
+		if err := json.Unmarshal(execution.Trigger, &trigger); err != nil {
+			return fmt.Errorf("invalid execution trigger: %w", err)
+		}
+	}
+
+	// Errors are already logged and recorded by ExecuteWithInput
+	_, _ = r.executionEngine.ExecuteWithInput(ctx, *workflowDef, execution.WorkflowID, logger, &execution.ID, input, trigger)
+	return nil
+}
+
+// resumeState rebuilds the state to resume original from: its context
+// snapshot and the tasks with a successful TaskLog, which are not run again.
+// A resumed execution only logs the tasks it ran, so the TaskLogs of the
+// executions it was resumed from, and so on, count too.
+//
+// Executions cancelled while pending or requeued after a crash have no
+// snapshot, so the context is always seeded with the input and trigger of
+// the chain, and the results missing from it are taken from the outputs of
+// the successful TaskLogs.
+func resumeState(
+	execRepo repository.ExecutionRepository,
+	taskLogRepo repository.TaskLogRepository,
+	original *repository.Execution,
+	workflowDef *engine.WorkflowDefinition,
+) (engine.ResumeState, error) {
+	snapshot := map[string]interface{}{}
+	if len(original.ContextSnapshot) > 0 {
+		if err := json.Unmarshal(original.ContextSnapshot, &snapshot); err != nil {
+			return engine.ResumeState{}, fmt.Errorf("invalid context snapshot: %w", err)
+		}
+	}
+
+	var input, trigger datatypes.JSON
+	outputs := make(map[string]datatypes.JSON)
+	succeeded := make(map[string]bool)
+	visited := make(map[uuid.UUID]bool)
+	for execution := original; execution != nil && !visited[execution.ID]; {
+		visited[execution.ID] = true
+		if len(input) == 0 {
+			input = execution.Input
+		}
+		if len(trigger) == 0 {
+			trigger = execution.Trigger
+		}
+		taskLogs, err := taskLogRepo.GetByExecutionID(execution.ID)
+		if err != nil {
+			return engine.ResumeState{}, fmt.Errorf("failed to retrieve task logs: %w", err)
+		}
+		for _, taskLog := range taskLogs {
+			// The most recent execution's output wins
+			if taskLog.Status == "success" && !succeeded[taskLog.TaskID] {
+				succeeded[taskLog.TaskID] = true
+				outputs[taskLog.TaskID] = taskLog.Output
+			}
+		}
+
+		if execution.ResumedFromID == nil {
+			break
+		}
+		if execution, err = execRepo.GetByID(*execution.ResumedFromID); err != nil {
+			return engine.ResumeState{}, fmt.Errorf("failed to retrieve resumed execution: %w", err)
+		}
+	}
+
+	for key, value := range map[string]datatypes.JSON{"input": input, "trigger": trigger} {
+		if len(value) == 0 {
+			continue
+		}
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(value, &decoded); err != nil {
+			return engine.ResumeState{}, fmt.Errorf("invalid execution %s: %w", key, err)
+		}
+		snapshot[key] = decoded
+	}
+
+	completed := []string{}
+	for _, task := range workflowDef.Tasks {
+		if !succeeded[task.ID] {
+			continue
+		}
+		completed = append(completed, task.ID)
+		key := task.ID + "_result"
+		if _, ok := snapshot[key]; ok {
+			continue
+		}
+		var output interface{}
+		if len(outputs[task.ID]) > 0 {
+			if err := json.Unmarshal(outputs[task.ID], &output); err != nil {
+				return engine.ResumeState{}, fmt.Errorf("invalid output of task %s: %w", task.ID, err)
+			}
+		}
+		snapshot[key] = output
+	}
+
+	return engine.ResumeState{
+		ExecutionID:    original.ID,
+		Context:        snapshot,
+		CompletedTasks: completed,
+	}, nil
+}
+
+// marshalJSON encodes value for a jsonb column, leaving nil values NULL
+func marshalJSON(value map[string]interface{}) (datatypes.JSON, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return datatypes.JSON(data), nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/tracing"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/datatypes"
+)
+
+func TestWorkflowRunnerStartQueuesExecution(t *testing.T) {
+	repo := newMockWorkflowRepository()
+	mockExecRepo := &mockExecutionRepository{}
+	runner := newWorkflowRunner(repo, mockExecRepo, &mockTaskLogRepository{}, engine.NewEngine(engine.NewRegistry()))
+	workflow := inputsWorkflow(repo)
+
+	execution, err := runner.Start(context.Background(), workflow.ID, map[string]interface{}{"url": "http://example.com"}, map[string]interface{}{"type": "test"})
+	assert.NoError(t, err)
+
+	stored, err := mockExecRepo.GetByID(execution.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", stored.Status)
+	assert.JSONEq(t, `{"url": "http://example.com", "limit": 10}`, string(stored.Input))
+	assert.JSONEq(t, `{"type": "test"}`, string(stored.Trigger))
+}
+
+func TestWorkflowRunnerExecuteRecordsStartFailure(t *testing.T) {
+	mockExecRepo := &mockExecutionRepository{}
+	runner := newWorkflowRunner(newMockWorkflowRepository(), mockExecRepo, &mockTaskLogRepository{}, engine.NewEngine(engine.NewRegistry()))
+
+	// The workflow was deleted after the execution was queued
+	execution := &repository.Execution{WorkflowID: uuid.New(), Status: "pending", Input: datatypes.JSON(`{}`)}
+	mockExecRepo.Create(execution)
+	claimed, _ := mockExecRepo.Claim("worker")
+
+	runner.Execute(context.Background(), claimed)
+
+	stored, err := mockExecRepo.GetByID(execution.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "failed", stored.Status)
+	assert.NotNil(t, stored.CompletedAt)
+}
+
+// traceHook records the trace ID of the runs it sees
+type traceHook struct {
+	traceID trace.TraceID
+}
+
+func (h *traceHook) BeforeRun(ctx context.Context, run *engine.RunInfo) (context.Context, error) {
+	h.traceID = trace.SpanContextFromContext(ctx).TraceID()
+	return ctx, nil
+}
+
+func (h *traceHook) AfterRun(ctx context.Context, run *engine.RunInfo) error {
+	return nil
+}
+
+func TestWorkflowRunnerExecuteContinuesCallerTrace(t *testing.T) {
+	repo := newMockWorkflowRepository()
+	mockExecRepo := &mockExecutionRepository{}
+	registry := engine.NewRegistry()
+	registry.Register("record", &recordingExecutor{})
+	executionEngine := engine.NewEngine(registry)
+	hook := &traceHook{}
+	executionEngine.AddHook(hook)
+	runner := newWorkflowRunner(repo, mockExecRepo, &mockTaskLogRepository{}, executionEngine)
+	workflow := inputsWorkflow(repo)
+
+	// The trace context of the request that queued the execution is stored with it
+	ctx := tracing.Extract(context.Background(), map[string]string{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	})
+	execution, err := runner.Start(ctx, workflow.ID, map[string]interface{}{"url": "http://example.com"}, nil)
+	assert.NoError(t, err)
+	claimed, _ := mockExecRepo.Claim("worker")
+	assert.Equal(t, execution.ID, claimed.ID)
+
+	runner.Execute(context.Background(), claimed)
+
+	stored, err := mockExecRepo.GetByID(execution.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "completed", stored.Status)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", hook.traceID.String())
+}
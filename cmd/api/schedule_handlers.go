@@ -0,0 +1,223 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/scheduler"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// ScheduleRequest represents the request body for creating or updating a schedule
+type ScheduleRequest struct {
+	WorkflowID     uuid.UUID `json:"workflow_id" binding:"required"`
+	CronExpression string    `json:"cron_expression" binding:"required"`
+	// Timezone is an IANA zone name such as "America/Sao_Paulo" (default: "UTC")
+	Timezone string `json:"timezone"`
+	// Enabled defaults to true
+	Enabled *bool                  `json:"enabled"`
+	Inputs  map[string]interface{} `json:"inputs"`
+	// MissedRunPolicy is "skip" (default) or "catch_up"
+	MissedRunPolicy string `json:"missed_run_policy"`
+	// OverlapPolicy is "skip" (default) or "allow"
+	OverlapPolicy string `json:"overlap_policy"`
+}
+
+// scheduleRequestError is a problem with a ScheduleRequest and the status to report it with
+type scheduleRequestError struct {
+	status  int
+	message string
+	details string
+}
+
+// applyScheduleRequest validates req and copies it onto schedule, computing
+// the next run time from now.
+func applyScheduleRequest(schedule *repository.Schedule, req ScheduleRequest, workflowRepo repository.WorkflowRepository) *scheduleRequestError {
+	workflow, err := workflowRepo.GetByID(req.WorkflowID)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return &scheduleRequestError{status: http.StatusNotFound, message: "Workflow not found"}
+		}
+		return &scheduleRequestError{status: http.StatusInternalServerError, message: "Failed to retrieve workflow"}
+	}
+	workflowDef, err := workflow.ToWorkflowDefinition()
+	if err != nil {
+		return &scheduleRequestError{status: http.StatusBadRequest, message: "Invalid workflow definition", details: err.Error()}
+	}
+	if _, err := workflowDef.ResolveInputs(req.Inputs); err != nil {
+		return &scheduleRequestError{status: http.StatusBadRequest, message: "Invalid workflow inputs", details: err.Error()}
+	}
+
+	missedRunPolicy := req.MissedRunPolicy
+	if missedRunPolicy == "" {
+		missedRunPolicy = repository.MissedRunSkip
+	}
+	if missedRunPolicy != repository.MissedRunSkip && missedRunPolicy != repository.MissedRunCatchUp {
+		return &scheduleRequestError{status: http.StatusBadRequest, message: "Invalid missed_run_policy", details: "expected 'skip' or 'catch_up'"}
+	}
+	overlapPolicy := req.OverlapPolicy
+	if overlapPolicy == "" {
+		overlapPolicy = repository.OverlapSkip
+	}
+	if overlapPolicy != repository.OverlapSkip && overlapPolicy != repository.OverlapAllow {
+		return &scheduleRequestError{status: http.StatusBadRequest, message: "Invalid overlap_policy", details: "expected 'skip' or 'allow'"}
+	}
+
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	nextRunAt, err := scheduler.NextRun(req.CronExpression, timezone, time.Now())
+	if err != nil {
+		return &scheduleRequestError{status: http.StatusBadRequest, message: "Invalid schedule", details: err.Error()}
+	}
+
+	var inputs datatypes.JSON
+	if req.Inputs != nil {
+		inputsJSON, err := json.Marshal(req.Inputs)
+		if err != nil {
+			return &scheduleRequestError{status: http.StatusBadRequest, message: "Invalid workflow inputs", details: err.Error()}
+		}
+		inputs = datatypes.JSON(inputsJSON)
+	}
+
+	schedule.WorkflowID = req.WorkflowID
+	schedule.CronExpression = req.CronExpression
+	schedule.Timezone = timezone
+	schedule.Enabled = req.Enabled == nil || *req.Enabled
+	schedule.Inputs = inputs
+	schedule.MissedRunPolicy = missedRunPolicy
+	schedule.OverlapPolicy = overlapPolicy
+	schedule.NextRunAt = nil
+	if schedule.Enabled {
+		schedule.NextRunAt = nextRunAt
+	}
+	return nil
+}
+
+// handleCreateSchedule handles POST /schedules
+func handleCreateSchedule(scheduleRepo repository.ScheduleRepository, workflowRepo repository.WorkflowRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req ScheduleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+
+		schedule := &repository.Schedule{}
+		if reqErr := applyScheduleRequest(schedule, req, workflowRepo); reqErr != nil {
+			c.JSON(reqErr.status, gin.H{"error": reqErr.message, "details": reqErr.details})
+			return
+		}
+
+		if err := scheduleRepo.Create(schedule); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create schedule", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, schedule)
+	}
+}
+
+// handleGetSchedule handles GET /schedules/:id
+func handleGetSchedule(scheduleRepo repository.ScheduleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+			return
+		}
+
+		schedule, err := scheduleRepo.GetByID(id)
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve schedule"})
+			return
+		}
+
+		c.JSON(http.StatusOK, schedule)
+	}
+}
+
+// handleListSchedules handles GET /schedules
+func handleListSchedules(scheduleRepo repository.ScheduleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		schedules, err := scheduleRepo.GetAll()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve schedules"})
+			return
+		}
+
+		c.JSON(http.StatusOK, schedules)
+	}
+}
+
+// handleUpdateSchedule handles PUT /schedules/:id. The next run time is
+// recomputed from the current time.
+func handleUpdateSchedule(scheduleRepo repository.ScheduleRepository, workflowRepo repository.WorkflowRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+			return
+		}
+
+		var req ScheduleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+
+		schedule, err := scheduleRepo.GetByID(id)
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve schedule"})
+			return
+		}
+
+		if reqErr := applyScheduleRequest(schedule, req, workflowRepo); reqErr != nil {
+			c.JSON(reqErr.status, gin.H{"error": reqErr.message, "details": reqErr.details})
+			return
+		}
+
+		if err := scheduleRepo.Update(schedule); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update schedule"})
+			return
+		}
+
+		c.JSON(http.StatusOK, schedule)
+	}
+}
+
+// handleDeleteSchedule handles DELETE /schedules/:id
+func handleDeleteSchedule(scheduleRepo repository.ScheduleRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid schedule ID"})
+			return
+		}
+
+		if err := scheduleRepo.Delete(id); err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete schedule"})
+			return
+		}
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/metrics"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockScheduleRepository for handlers tests
+type mockScheduleRepository struct {
+	mu        sync.Mutex
+	schedules map[uuid.UUID]*repository.Schedule
+}
+
+func newMockScheduleRepository() *mockScheduleRepository {
+	return &mockScheduleRepository{schedules: make(map[uuid.UUID]*repository.Schedule)}
+}
+
+func (m *mockScheduleRepository) Create(schedule *repository.Schedule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if schedule.ID == uuid.Nil {
+		schedule.ID = uuid.New()
+	}
+	stored := *schedule
+	m.schedules[schedule.ID] = &stored
+	return nil
+}
+
+func (m *mockScheduleRepository) GetByID(id uuid.UUID) (*repository.Schedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	schedule, exists := m.schedules[id]
+	if !exists {
+		return nil, &repositoryError{message: "schedule not found: " + id.String()}
+	}
+	found := *schedule
+	return &found, nil
+}
+
+func (m *mockScheduleRepository) GetAll() ([]*repository.Schedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var schedules []*repository.Schedule
+	for _, schedule := range m.schedules {
+		found := *schedule
+		schedules = append(schedules, &found)
+	}
+	return schedules, nil
+}
+
+func (m *mockScheduleRepository) ClaimDue(now time.Time, advance func(schedule *repository.Schedule)) ([]*repository.Schedule, error) {
+	return nil, nil
+}
+
+func (m *mockScheduleRepository) RecordRun(id uuid.UUID, ranAt time.Time, executionID uuid.UUID) error {
+	return nil
+}
+
+func (m *mockScheduleRepository) Update(schedule *repository.Schedule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.schedules[schedule.ID]; !exists {
+		return &repositoryError{message: "schedule not found: " + schedule.ID.String()}
+	}
+	stored := *schedule
+	m.schedules[schedule.ID] = &stored
+	return nil
+}
+
+func (m *mockScheduleRepository) Delete(id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.schedules[id]; !exists {
+		return &repositoryError{message: "schedule not found: " + id.String()}
+	}
+	delete(m.schedules, id)
+	return nil
+}
+
+func setupScheduleRouter() (*gin.Engine, *mockScheduleRepository, *repository.Workflow) {
+	workflowRepo := newMockWorkflowRepository()
+	scheduleRepo := newMockScheduleRepository()
+	router := setupRouter(workflowRepo, &mockExecutionRepository{}, &mockTaskLogRepository{}, scheduleRepo, newMockWebhookRepository(), &mockExecutionRepository{}, engine.NewEngine(engine.NewRegistry()), metrics.New(), nil)
+	return router, scheduleRepo, inputsWorkflow(workflowRepo)
+}
+
+func sendJSON(router *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandleCreateSchedule(t *testing.T) {
+	router, scheduleRepo, workflow := setupScheduleRouter()
+
+	w := sendJSON(router, http.MethodPost, "/schedules", map[string]interface{}{
+		"workflow_id":     workflow.ID,
+		"cron_expression": "*/5 * * * *",
+		"timezone":        "America/Sao_Paulo",
+		"inputs":          map[string]interface{}{"url": "http://example.com"},
+	})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response repository.Schedule
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Enabled)
+	assert.Equal(t, "America/Sao_Paulo", response.Timezone)
+	assert.Equal(t, repository.MissedRunSkip, response.MissedRunPolicy)
+	assert.Equal(t, repository.OverlapSkip, response.OverlapPolicy)
+	if assert.NotNil(t, response.NextRunAt) {
+		assert.True(t, response.NextRunAt.After(time.Now()))
+		assert.Zero(t, response.NextRunAt.Minute()%5)
+	}
+	assert.Len(t, scheduleRepo.schedules, 1)
+}
+
+func TestHandleCreateScheduleDisabled(t *testing.T) {
+	router, _, workflow := setupScheduleRouter()
+
+	w := sendJSON(router, http.MethodPost, "/schedules", map[string]interface{}{
+		"workflow_id":     workflow.ID,
+		"cron_expression": "@daily",
+		"enabled":         false,
+		"inputs":          map[string]interface{}{"url": "http://example.com"},
+	})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response repository.Schedule
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Enabled)
+	assert.Nil(t, response.NextRunAt)
+}
+
+func TestHandleCreateScheduleInvalid(t *testing.T) {
+	router, scheduleRepo, workflow := setupScheduleRouter()
+	inputs := map[string]interface{}{"url": "http://example.com"}
+
+	tests := []struct {
+		name   string
+		body   map[string]interface{}
+		status int
+	}{
+		{"missing cron expression", map[string]interface{}{"workflow_id": workflow.ID}, http.StatusBadRequest},
+		{"invalid cron expression", map[string]interface{}{"workflow_id": workflow.ID, "cron_expression": "61 * * * *", "inputs": inputs}, http.StatusBadRequest},
+		{"invalid timezone", map[string]interface{}{"workflow_id": workflow.ID, "cron_expression": "@hourly", "timezone": "Mars/Base", "inputs": inputs}, http.StatusBadRequest},
+		{"invalid missed run policy", map[string]interface{}{"workflow_id": workflow.ID, "cron_expression": "@hourly", "missed_run_policy": "retry", "inputs": inputs}, http.StatusBadRequest},
+		{"invalid overlap policy", map[string]interface{}{"workflow_id": workflow.ID, "cron_expression": "@hourly", "overlap_policy": "queue", "inputs": inputs}, http.StatusBadRequest},
+		{"missing required input", map[string]interface{}{"workflow_id": workflow.ID, "cron_expression": "@hourly"}, http.StatusBadRequest},
+		{"unknown workflow", map[string]interface{}{"workflow_id": uuid.New(), "cron_expression": "@hourly"}, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := sendJSON(router, http.MethodPost, "/schedules", tt.body)
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+
+	assert.Empty(t, scheduleRepo.schedules)
+}
+
+func TestHandleScheduleLifecycle(t *testing.T) {
+	router, scheduleRepo, workflow := setupScheduleRouter()
+	inputs := map[string]interface{}{"url": "http://example.com"}
+
+	w := sendJSON(router, http.MethodPost, "/schedules", map[string]interface{}{
+		"workflow_id":     workflow.ID,
+		"cron_expression": "@hourly",
+		"inputs":          inputs,
+	})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var created repository.Schedule
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	w = sendJSON(router, http.MethodGet, "/schedules/"+created.ID.String(), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = sendJSON(router, http.MethodPut, "/schedules/"+created.ID.String(), map[string]interface{}{
+		"workflow_id":       workflow.ID,
+		"cron_expression":   "30 2 * * *",
+		"missed_run_policy": "catch_up",
+		"inputs":            inputs,
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	stored, _ := scheduleRepo.GetByID(created.ID)
+	assert.Equal(t, "30 2 * * *", stored.CronExpression)
+	assert.Equal(t, repository.MissedRunCatchUp, stored.MissedRunPolicy)
+	if assert.NotNil(t, stored.NextRunAt) {
+		assert.Equal(t, 2, stored.NextRunAt.Hour())
+		assert.Equal(t, 30, stored.NextRunAt.Minute())
+	}
+
+	w = sendJSON(router, http.MethodDelete, "/schedules/"+created.ID.String(), nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = sendJSON(router, http.MethodGet, "/schedules/"+created.ID.String(), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = sendJSON(router, http.MethodDelete, "/schedules/"+created.ID.String(), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/secrets"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateSecretRequest represents the request body for creating a secret
+type CreateSecretRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Value string `json:"value" binding:"required"`
+}
+
+// UpdateSecretRequest represents the request body for updating a secret;
+// only the value can change
+type UpdateSecretRequest struct {
+	Value string `json:"value" binding:"required"`
+}
+
+// respondSecretError writes the response for an error from secrets.Store
+func respondSecretError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, secrets.ErrNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Secrets are not configured", "details": err.Error()})
+	case errors.Is(err, secrets.ErrInvalidName):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid secret name", "details": err.Error()})
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Secret not found"})
+	case strings.Contains(err.Error(), "already exists") || strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "unique"):
+		c.JSON(http.StatusConflict, gin.H{"error": "Secret with this name already exists"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// handleCreateSecret handles POST /secrets. The response, like every secrets
+// response, leaves the value out.
+func handleCreateSecret(store *secrets.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req CreateSecretRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+
+		secret, err := store.Create(req.Name, req.Value)
+		if err != nil {
+			respondSecretError(c, err, "Failed to create secret")
+			return
+		}
+
+		c.JSON(http.StatusCreated, secret)
+	}
+}
+
+// handleListSecrets handles GET /secrets
+func handleListSecrets(store *secrets.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, err := store.List()
+		if err != nil {
+			respondSecretError(c, err, "Failed to retrieve secrets")
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"secrets": list,
+			"count":   len(list),
+		})
+	}
+}
+
+// handleGetSecret handles GET /secrets/:id
+func handleGetSecret(store *secrets.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid secret ID"})
+			return
+		}
+
+		secret, err := store.Get(id)
+		if err != nil {
+			respondSecretError(c, err, "Failed to retrieve secret")
+			return
+		}
+
+		c.JSON(http.StatusOK, secret)
+	}
+}
+
+// handleUpdateSecret handles PUT /secrets/:id
+func handleUpdateSecret(store *secrets.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid secret ID"})
+			return
+		}
+
+		var req UpdateSecretRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+
+		secret, err := store.Update(id, req.Value)
+		if err != nil {
+			respondSecretError(c, err, "Failed to update secret")
+			return
+		}
+
+		c.JSON(http.StatusOK, secret)
+	}
+}
+
+// handleDeleteSecret handles DELETE /secrets/:id
+func handleDeleteSecret(store *secrets.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid secret ID"})
+			return
+		}
+
+		if err := store.Delete(id); err != nil {
+			respondSecretError(c, err, "Failed to delete secret")
+			return
+		}
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/metrics"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/secrets"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/tasks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+// mockSecretRepository for secrets tests
+type mockSecretRepository struct {
+	mu      sync.Mutex
+	secrets map[uuid.UUID]*repository.Secret
+}
+
+func newMockSecretRepository() *mockSecretRepository {
+	return &mockSecretRepository{secrets: make(map[uuid.UUID]*repository.Secret)}
+}
+
+func (m *mockSecretRepository) Create(secret *repository.Secret) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.secrets {
+		if existing.Name == secret.Name {
+			return &repositoryError{message: "secret with name '" + secret.Name + "' already exists"}
+		}
+	}
+	if secret.ID == uuid.Nil {
+		secret.ID = uuid.New()
+	}
+	stored := *secret
+	m.secrets[secret.ID] = &stored
+	return nil
+}
+
+func (m *mockSecretRepository) GetByID(id uuid.UUID) (*repository.Secret, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	secret, exists := m.secrets[id]
+	if !exists {
+		return nil, &repositoryError{message: "secret not found: " + id.String()}
+	}
+	found := *secret
+	return &found, nil
+}
+
+func (m *mockSecretRepository) GetByName(name string) (*repository.Secret, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, secret := range m.secrets {
+		if secret.Name == name {
+			found := *secret
+			return &found, nil
+		}
+	}
+	return nil, &repositoryError{message: "secret not found: " + name}
+}
+
+func (m *mockSecretRepository) GetAll() ([]*repository.Secret, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var secrets []*repository.Secret
+	for _, secret := range m.secrets {
+		found := *secret
+		secrets = append(secrets, &found)
+	}
+	return secrets, nil
+}
+
+func (m *mockSecretRepository) Update(secret *repository.Secret) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.secrets[secret.ID]; !exists {
+		return &repositoryError{message: "secret not found: " + secret.ID.String()}
+	}
+	stored := *secret
+	m.secrets[secret.ID] = &stored
+	return nil
+}
+
+func (m *mockSecretRepository) Delete(id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.secrets[id]; !exists {
+		return &repositoryError{message: "secret not found: " + id.String()}
+	}
+	delete(m.secrets, id)
+	return nil
+}
+
+func newTestSecretStore(t *testing.T) *secrets.Store {
+	store, err := secrets.NewStore(newMockSecretRepository(), bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	return store
+}
+
+func TestSecretEndpoints(t *testing.T) {
+	store := newTestSecretStore(t)
+	router := setupRouter(newMockWorkflowRepository(), &mockExecutionRepository{}, &mockTaskLogRepository{}, newMockScheduleRepository(), newMockWebhookRepository(), &mockExecutionRepository{}, engine.NewEngine(engine.NewRegistry()), metrics.New(), store)
+
+	w := sendJSON(router, http.MethodPost, "/secrets", map[string]interface{}{"name": "api_token", "value": "tok-123"})
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.NotContains(t, w.Body.String(), "tok-123")
+	var created repository.Secret
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "api_token", created.Name)
+
+	w = sendJSON(router, http.MethodPost, "/secrets", map[string]interface{}{"name": "api_token", "value": "other"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = sendJSON(router, http.MethodPost, "/secrets", map[string]interface{}{"name": "bad name", "value": "x"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = sendJSON(router, http.MethodPost, "/secrets", map[string]interface{}{"name": "empty"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = sendJSON(router, http.MethodGet, "/secrets", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"count":1`)
+	assert.NotContains(t, w.Body.String(), "tok-123")
+
+	w = sendJSON(router, http.MethodPut, "/secrets/"+created.ID.String(), map[string]interface{}{"value": "tok-456"})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "tok-456")
+	value, err := store.Value("api_token")
+	require.NoError(t, err)
+	assert.Equal(t, "tok-456", value)
+
+	w = sendJSON(router, http.MethodGet, "/secrets/"+created.ID.String(), nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"value"`)
+
+	w = sendJSON(router, http.MethodDelete, "/secrets/"+created.ID.String(), nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = sendJSON(router, http.MethodGet, "/secrets/"+created.ID.String(), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = sendJSON(router, http.MethodGet, "/secrets/not-a-uuid", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestSecretEndpointsWithoutMasterKey(t *testing.T) {
+	router := setupRouter(newMockWorkflowRepository(), &mockExecutionRepository{}, &mockTaskLogRepository{}, newMockScheduleRepository(), newMockWebhookRepository(), &mockExecutionRepository{}, engine.NewEngine(engine.NewRegistry()), metrics.New(), nil)
+
+	w := sendJSON(router, http.MethodPost, "/secrets", map[string]interface{}{"name": "api_token", "value": "tok-123"})
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	w = sendJSON(router, http.MethodGet, "/secrets", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestSecretReferenceIsSentButNotRecorded(t *testing.T) {
+	var received string
+	var mu sync.Mutex
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received = r.Header.Get("Authorization")
+		mu.Unlock()
+		// Echo the credentials back, as some APIs do
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"authorization": r.Header.Get("Authorization")})
+	}))
+	defer target.Close()
+
+	store := newTestSecretStore(t)
+	_, err := store.Create("api_token", "tok-123")
+	require.NoError(t, err)
+
+	repo := newMockWorkflowRepository()
+	execRepo := &mockExecutionRepository{}
+	taskLogRepo := &mockTaskLogRepository{}
+	registry := engine.NewRegistry()
+	tasks.RegisterHTTPTask(registry)
+	executionEngine := engine.NewEngine(registry)
+	secrets.Install(executionEngine, store, secrets.NewMasker())
+	router := setupRouter(repo, execRepo, taskLogRepo, newMockScheduleRepository(), newMockWebhookRepository(), execRepo, executionEngine, metrics.New(), store)
+	startWorkers(t, repo, execRepo, taskLogRepo, executionEngine)
+
+	definition, _ := json.Marshal(map[string]interface{}{
+		"name": "authorized",
+		"tasks": []interface{}{map[string]interface{}{
+			"id":   "call",
+			"type": "http_request",
+			"config": map[string]interface{}{
+				"method":  "GET",
+				"url":     target.URL,
+				"headers": map[string]interface{}{"Authorization": `Bearer {{secret "api_token"}}`},
+			},
+		}},
+	})
+	workflow := &repository.Workflow{Name: "authorized", Definition: datatypes.JSON(definition)}
+	repo.Create(workflow)
+
+	w := sendJSON(router, http.MethodPost, "/workflows/"+workflow.ID.String()+"/run", nil)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var response struct {
+		ExecutionID uuid.UUID `json:"execution_id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	var execution *repository.Execution
+	require.Eventually(t, func() bool {
+		execution, _ = execRepo.GetByID(response.ExecutionID)
+		return execution != nil && execution.Status == "completed"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	assert.Equal(t, "Bearer tok-123", received)
+	mu.Unlock()
+
+	assert.NotContains(t, string(execution.ContextSnapshot), "tok-123")
+	taskLogs, _ := taskLogRepo.GetByExecutionID(execution.ID)
+	require.Len(t, taskLogs, 1)
+	assert.Contains(t, string(taskLogs[0].Input), `{{secret \"api_token\"}}`)
+	assert.NotContains(t, string(taskLogs[0].Output), "tok-123")
+	assert.Contains(t, string(taskLogs[0].Output), "Bearer ***")
+}
@@ -0,0 +1,432 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/secrets"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// webhookSignatureHeader carries "sha256=<hex HMAC-SHA256 of the body>"
+	// for webhooks with a signing secret
+	webhookSignatureHeader = "X-Signature-256"
+	// maxWebhookBodySize limits the size of webhook request bodies
+	maxWebhookBodySize = 1 << 20
+	// sealedSecretPrefix marks signing secrets sealed with the secrets store
+	sealedSecretPrefix = "sealed:"
+)
+
+// errReservedSigningSecret rejects plain signing secrets that would read as
+// sealed ones
+var errReservedSigningSecret = errors.New("signing secret must not start with '" + sealedSecretPrefix + "'")
+
+// webhookTokenPath matches the token in a trigger URL path
+var webhookTokenPath = regexp.MustCompile(`^/hooks/[^/?]+`)
+
+// accessLogFormatter formats the access log like gin's default logger, with
+// webhook tokens redacted since anyone who reads one can trigger its workflow
+func accessLogFormatter(param gin.LogFormatterParams) string {
+	var statusColor, methodColor, resetColor string
+	if param.IsOutputColor() {
+		statusColor = param.StatusCodeColor()
+		methodColor = param.MethodColor()
+		resetColor = param.ResetColor()
+	}
+	if param.Latency > time.Minute {
+		param.Latency = param.Latency.Truncate(time.Second)
+	}
+	return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		statusColor, param.StatusCode, resetColor,
+		param.Latency,
+		param.ClientIP,
+		methodColor, param.Method, resetColor,
+		webhookTokenPath.ReplaceAllString(param.Path, "/hooks/***"),
+		param.ErrorMessage,
+	)
+}
+
+// webhookHiddenHeaders are not copied into the trigger, since the execution
+// context is stored with the execution
+var webhookHiddenHeaders = map[string]bool{
+	"Authorization":        true,
+	"Cookie":               true,
+	"Proxy-Authorization":  true,
+	webhookSignatureHeader: true,
+}
+
+// WebhookRequest represents the request body for creating or updating a webhook trigger
+type WebhookRequest struct {
+	WorkflowID uuid.UUID `json:"workflow_id" binding:"required"`
+	// SigningSecret enables HMAC signature verification when set; an empty
+	// string disables it. Updates without it keep the current secret.
+	SigningSecret *string `json:"signing_secret"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+}
+
+// generateWebhookToken returns a random token for a webhook URL
+func generateWebhookToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// sealSigningSecret returns the value to store for the signing secret of
+// webhook: sealed with store, or as is when secrets are not configured.
+func sealSigningSecret(store *secrets.Store, webhook *repository.WebhookTrigger, secret string) (string, error) {
+	if secret == "" {
+		return "", nil
+	}
+	if store == nil {
+		if strings.HasPrefix(secret, sealedSecretPrefix) {
+			return "", errReservedSigningSecret
+		}
+		return secret, nil
+	}
+	sealed, err := store.Seal(signingSecretLabel(webhook), secret)
+	if err != nil {
+		return "", err
+	}
+	return sealedSecretPrefix + sealed, nil
+}
+
+// signingSecret returns the signing secret of webhook, opening it if it
+// was sealed.
+func signingSecret(store *secrets.Store, webhook *repository.WebhookTrigger) (string, error) {
+	sealed, ok := strings.CutPrefix(webhook.SigningSecret, sealedSecretPrefix)
+	if !ok {
+		return webhook.SigningSecret, nil
+	}
+	return store.Open(signingSecretLabel(webhook), sealed)
+}
+
+// signingSecretLabel binds a sealed signing secret to its webhook
+func signingSecretLabel(webhook *repository.WebhookTrigger) string {
+	return "webhook:" + webhook.ID.String()
+}
+
+// validSignature reports whether signature is the HMAC-SHA256 of body under
+// secret, hex encoded and optionally prefixed with "sha256="
+func validSignature(secret string, body []byte, signature string) bool {
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// webhookTrigger builds the "trigger" context value for a webhook request:
+// the body (decoded when it is JSON), headers and query parameters.
+func webhookTrigger(webhook *repository.WebhookTrigger, r *http.Request, body []byte) map[string]interface{} {
+	var payload interface{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			payload = string(body)
+		}
+	}
+
+	headers := make(map[string]interface{})
+	for name, values := range r.Header {
+		if webhookHiddenHeaders[name] {
+			continue
+		}
+		headers[name] = strings.Join(values, ", ")
+	}
+
+	query := make(map[string]interface{})
+	for name, values := range r.URL.Query() {
+		if len(values) == 1 {
+			query[name] = values[0]
+			continue
+		}
+		list := make([]interface{}, len(values))
+		for i, v := range values {
+			list[i] = v
+		}
+		query[name] = list
+	}
+
+	return map[string]interface{}{
+		"type":       "webhook",
+		"webhook_id": webhook.ID.String(),
+		"method":     r.Method,
+		"body":       payload,
+		"headers":    headers,
+		"query":      query,
+	}
+}
+
+// handleTriggerWebhook handles POST /hooks/:token. The workflow runs with
+// its default inputs and the request exposed under "trigger".
+func handleTriggerWebhook(webhookRepo repository.WebhookRepository, runner *workflowRunner, secretStore *secrets.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		webhook, err := webhookRepo.GetByToken(c.Param("token"))
+		if err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook"})
+			return
+		}
+		if !webhook.Enabled {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBodySize))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+
+		secret, err := signingSecret(secretStore, webhook)
+		if err != nil {
+			slog.Error("Failed to open webhook signing secret", "error", err, "webhook_id", webhook.ID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify signature"})
+			return
+		}
+		if secret != "" && !validSignature(secret, body, c.GetHeader(webhookSignatureHeader)) {
+			slog.Warn("Rejected webhook with invalid signature", "webhook_id", webhook.ID)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+			return
+		}
+
+		execution, err := runner.Start(c.Request.Context(), webhook.WorkflowID, nil, webhookTrigger(webhook, c.Request, body))
+		if err != nil {
+			respondRunError(c, err)
+			return
+		}
+
+		now := time.Now().UTC()
+		webhook.LastTriggeredAt = &now
+		if err := webhookRepo.Update(webhook); err != nil {
+			slog.Error("Failed to record webhook trigger time", "error", err, "webhook_id", webhook.ID)
+		}
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"execution_id": execution.ID,
+			"workflow_id":  webhook.WorkflowID,
+			"status":       "pending",
+			"message":      "Workflow execution started",
+		})
+	}
+}
+
+// handleCreateWebhook handles POST /webhooks. Like every webhook response,
+// it tells whether there is a signing secret but not what it is.
+func handleCreateWebhook(webhookRepo repository.WebhookRepository, workflowRepo repository.WorkflowRepository, secretStore *secrets.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req WebhookRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+
+		if _, err := workflowRepo.GetByID(req.WorkflowID); err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve workflow"})
+			return
+		}
+
+		token, err := generateWebhookToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate webhook token"})
+			return
+		}
+
+		webhook := &repository.WebhookTrigger{
+			ID:         uuid.New(),
+			WorkflowID: req.WorkflowID,
+			Token:      token,
+			Enabled:    req.Enabled == nil || *req.Enabled,
+		}
+		if req.SigningSecret != nil {
+			if webhook.SigningSecret, err = sealSigningSecret(secretStore, webhook, *req.SigningSecret); err != nil {
+				respondSigningSecretError(c, err)
+				return
+			}
+		}
+		if err := webhookRepo.Create(webhook); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, webhook)
+	}
+}
+
+// handleGetWebhook handles GET /webhooks/:id
+func handleGetWebhook(webhookRepo repository.WebhookRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		webhook, ok := loadWebhook(c, webhookRepo)
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, webhook)
+	}
+}
+
+// handleListWebhooks handles GET /webhooks
+func handleListWebhooks(webhookRepo repository.WebhookRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		webhooks, err := webhookRepo.GetAll()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhooks"})
+			return
+		}
+
+		c.JSON(http.StatusOK, webhooks)
+	}
+}
+
+// handleUpdateWebhook handles PUT /webhooks/:id. The token is kept; use
+// POST /webhooks/:id/rotate to replace it.
+func handleUpdateWebhook(webhookRepo repository.WebhookRepository, workflowRepo repository.WorkflowRepository, secretStore *secrets.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req WebhookRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+			return
+		}
+
+		webhook, ok := loadWebhook(c, webhookRepo)
+		if !ok {
+			return
+		}
+
+		if _, err := workflowRepo.GetByID(req.WorkflowID); err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve workflow"})
+			return
+		}
+
+		if req.SigningSecret != nil {
+			secret, err := sealSigningSecret(secretStore, webhook, *req.SigningSecret)
+			if err != nil {
+				respondSigningSecretError(c, err)
+				return
+			}
+			webhook.SigningSecret = secret
+		}
+		webhook.WorkflowID = req.WorkflowID
+		webhook.Enabled = req.Enabled == nil || *req.Enabled
+		if err := webhookRepo.Update(webhook); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+			return
+		}
+
+		c.JSON(http.StatusOK, webhook)
+	}
+}
+
+// handleRotateWebhookToken handles POST /webhooks/:id/rotate. The old URL
+// stops working immediately.
+func handleRotateWebhookToken(webhookRepo repository.WebhookRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		webhook, ok := loadWebhook(c, webhookRepo)
+		if !ok {
+			return
+		}
+
+		token, err := generateWebhookToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate webhook token"})
+			return
+		}
+
+		webhook.Token = token
+		if err := webhookRepo.Update(webhook); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+			return
+		}
+
+		slog.Info("Rotated webhook token", "webhook_id", webhook.ID)
+		c.JSON(http.StatusOK, webhook)
+	}
+}
+
+// handleDeleteWebhook handles DELETE /webhooks/:id
+func handleDeleteWebhook(webhookRepo repository.WebhookRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+			return
+		}
+
+		if err := webhookRepo.Delete(id); err != nil {
+			if strings.Contains(err.Error(), "not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+			return
+		}
+
+		c.JSON(http.StatusNoContent, nil)
+	}
+}
+
+// respondSigningSecretError writes the response for an error from
+// sealSigningSecret
+func respondSigningSecretError(c *gin.Context, err error) {
+	if errors.Is(err, errReservedSigningSecret) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid signing secret", "details": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to seal signing secret"})
+}
+
+// loadWebhook loads the webhook trigger named by the :id parameter, writing
+// the error response and returning false if it can't.
+func loadWebhook(c *gin.Context, webhookRepo repository.WebhookRepository) (*repository.WebhookTrigger, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return nil, false
+	}
+
+	webhook, err := webhookRepo.GetByID(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook"})
+		return nil, false
+	}
+	return webhook, true
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/metrics"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/secrets"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
+)
+
+// mockWebhookRepository for handlers tests
+type mockWebhookRepository struct {
+	mu       sync.Mutex
+	webhooks map[uuid.UUID]*repository.WebhookTrigger
+}
+
+func newMockWebhookRepository() *mockWebhookRepository {
+	return &mockWebhookRepository{webhooks: make(map[uuid.UUID]*repository.WebhookTrigger)}
+}
+
+func (m *mockWebhookRepository) Create(webhook *repository.WebhookTrigger) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if webhook.ID == uuid.Nil {
+		webhook.ID = uuid.New()
+	}
+	stored := *webhook
+	m.webhooks[webhook.ID] = &stored
+	return nil
+}
+
+func (m *mockWebhookRepository) GetByID(id uuid.UUID) (*repository.WebhookTrigger, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	webhook, exists := m.webhooks[id]
+	if !exists {
+		return nil, &repositoryError{message: "webhook trigger not found: " + id.String()}
+	}
+	found := *webhook
+	return &found, nil
+}
+
+func (m *mockWebhookRepository) GetByToken(token string) (*repository.WebhookTrigger, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, webhook := range m.webhooks {
+		if webhook.Token == token {
+			found := *webhook
+			return &found, nil
+		}
+	}
+	return nil, &repositoryError{message: "webhook trigger not found"}
+}
+
+func (m *mockWebhookRepository) GetAll() ([]*repository.WebhookTrigger, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var webhooks []*repository.WebhookTrigger
+	for _, webhook := range m.webhooks {
+		found := *webhook
+		webhooks = append(webhooks, &found)
+	}
+	return webhooks, nil
+}
+
+func (m *mockWebhookRepository) Update(webhook *repository.WebhookTrigger) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.webhooks[webhook.ID]; !exists {
+		return &repositoryError{message: "webhook trigger not found: " + webhook.ID.String()}
+	}
+	stored := *webhook
+	m.webhooks[webhook.ID] = &stored
+	return nil
+}
+
+func (m *mockWebhookRepository) Delete(id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.webhooks[id]; !exists {
+		return &repositoryError{message: "webhook trigger not found: " + id.String()}
+	}
+	delete(m.webhooks, id)
+	return nil
+}
+
+type webhookTestSetup struct {
+	router       *gin.Engine
+	workflowRepo *mockWorkflowRepositoryForHandlers
+	execRepo     *mockExecutionRepository
+	webhookRepo  *mockWebhookRepository
+	workflow     *repository.Workflow
+}
+
+func setupWebhookRouter(t *testing.T) *webhookTestSetup {
+	return setupWebhookRouterWithSecrets(t, newTestSecretStore(t))
+}
+
+func setupWebhookRouterWithSecrets(t *testing.T, secretStore *secrets.Store) *webhookTestSetup {
+	registry := engine.NewRegistry()
+	registry.Register("record", &recordingExecutor{})
+	s := &webhookTestSetup{
+		workflowRepo: newMockWorkflowRepository(),
+		execRepo:     &mockExecutionRepository{},
+		webhookRepo:  newMockWebhookRepository(),
+	}
+	taskLogRepo := &mockTaskLogRepository{}
+	executionEngine := engine.NewEngine(registry)
+	s.router = setupRouter(s.workflowRepo, s.execRepo, taskLogRepo, newMockScheduleRepository(), s.webhookRepo, s.execRepo, executionEngine, metrics.New(), secretStore)
+	startWorkers(t, s.workflowRepo, s.execRepo, taskLogRepo, executionEngine)
+
+	definition, _ := json.Marshal(map[string]interface{}{
+		"name": "on-event",
+		"tasks": []interface{}{
+			map[string]interface{}{"id": "first", "type": "record", "config": map[string]interface{}{"name": "first"}},
+		},
+	})
+	s.workflow = &repository.Workflow{Name: "on-event", Definition: datatypes.JSON(definition)}
+	s.workflowRepo.Create(s.workflow)
+	return s
+}
+
+func (s *webhookTestSetup) createWebhook(t *testing.T, body map[string]interface{}) repository.WebhookTrigger {
+	w := sendJSON(s.router, http.MethodPost, "/webhooks", body)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var webhook repository.WebhookTrigger
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &webhook))
+	return webhook
+}
+
+func (s *webhookTestSetup) trigger(path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestHandleCreateWebhook(t *testing.T) {
+	s := setupWebhookRouter(t)
+
+	webhook := s.createWebhook(t, map[string]interface{}{"workflow_id": s.workflow.ID})
+	assert.Len(t, webhook.Token, 64)
+	assert.True(t, webhook.Enabled)
+
+	w := sendJSON(s.router, http.MethodPost, "/webhooks", map[string]interface{}{"workflow_id": uuid.New()})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAccessLogRedactsWebhookTokens(t *testing.T) {
+	var accessLog bytes.Buffer
+	defaultWriter := gin.DefaultWriter
+	gin.DefaultWriter = &accessLog
+	s := setupWebhookRouter(t)
+	gin.DefaultWriter = defaultWriter
+	webhook := s.createWebhook(t, map[string]interface{}{"workflow_id": s.workflow.ID})
+
+	w := s.trigger("/hooks/"+webhook.Token+"?source=github", `{}`, nil)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	w = s.trigger("/hooks/unknown-token", `{}`, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	assert.NotContains(t, accessLog.String(), webhook.Token)
+	assert.NotContains(t, accessLog.String(), "unknown-token")
+	assert.Contains(t, accessLog.String(), `"/hooks/***?source=github"`)
+	assert.Contains(t, accessLog.String(), `"/webhooks"`)
+}
+
+func TestHandleTriggerWebhook(t *testing.T) {
+	s := setupWebhookRouter(t)
+	webhook := s.createWebhook(t, map[string]interface{}{"workflow_id": s.workflow.ID})
+
+	w := s.trigger("/hooks/"+webhook.Token+"?source=github&tag=a&tag=b", `{"action": "opened", "number": 7}`, map[string]string{
+		"Content-Type":  "application/json",
+		"X-Event":       "pull_request",
+		"Authorization": "Bearer secret",
+	})
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	var response struct {
+		ExecutionID uuid.UUID `json:"execution_id"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	var execution *repository.Execution
+	assert.Eventually(t, func() bool {
+		execution, _ = s.execRepo.GetByID(response.ExecutionID)
+		return execution != nil && execution.Status == "completed"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	var snapshot struct {
+		Trigger struct {
+			Type      string                 `json:"type"`
+			WebhookID string                 `json:"webhook_id"`
+			Body      map[string]interface{} `json:"body"`
+			Headers   map[string]interface{} `json:"headers"`
+			Query     map[string]interface{} `json:"query"`
+		} `json:"trigger"`
+	}
+	assert.NoError(t, json.Unmarshal(execution.ContextSnapshot, &snapshot))
+	assert.Equal(t, "webhook", snapshot.Trigger.Type)
+	assert.Equal(t, webhook.ID.String(), snapshot.Trigger.WebhookID)
+	assert.Equal(t, map[string]interface{}{"action": "opened", "number": float64(7)}, snapshot.Trigger.Body)
+	assert.Equal(t, "pull_request", snapshot.Trigger.Headers["X-Event"])
+	assert.NotContains(t, snapshot.Trigger.Headers, "Authorization")
+	assert.Equal(t, "github", snapshot.Trigger.Query["source"])
+	assert.Equal(t, []interface{}{"a", "b"}, snapshot.Trigger.Query["tag"])
+
+	stored, _ := s.webhookRepo.GetByID(webhook.ID)
+	assert.NotNil(t, stored.LastTriggeredAt)
+}
+
+func TestHandleTriggerWebhookSignature(t *testing.T) {
+	s := setupWebhookRouter(t)
+	w := sendJSON(s.router, http.MethodPost, "/webhooks", map[string]interface{}{"workflow_id": s.workflow.ID, "signing_secret": "s3cret"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.NotContains(t, w.Body.String(), "s3cret")
+	var webhook struct {
+		repository.WebhookTrigger
+		HasSigningSecret bool `json:"has_signing_secret"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &webhook))
+	assert.True(t, webhook.HasSigningSecret)
+
+	// The secret is stored sealed
+	stored, _ := s.webhookRepo.GetByID(webhook.ID)
+	assert.NotContains(t, stored.SigningSecret, "s3cret")
+
+	body := `{"action": "opened"}`
+	w = s.trigger("/hooks/"+webhook.Token, body, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = s.trigger("/hooks/"+webhook.Token, body, map[string]string{webhookSignatureHeader: sign("wrong", body)})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = s.trigger("/hooks/"+webhook.Token, body, map[string]string{webhookSignatureHeader: sign("s3cret", body)})
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	for _, path := range []string{"/webhooks/" + webhook.ID.String(), "/webhooks"} {
+		w = sendJSON(s.router, http.MethodGet, path, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "s3cret")
+		assert.Contains(t, w.Body.String(), `"has_signing_secret":true`)
+	}
+
+	// Updates without a signing secret keep it; an empty one removes it
+	w = sendJSON(s.router, http.MethodPut, "/webhooks/"+webhook.ID.String(), map[string]interface{}{"workflow_id": s.workflow.ID})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"has_signing_secret":true`)
+	w = s.trigger("/hooks/"+webhook.Token, body, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = sendJSON(s.router, http.MethodPut, "/webhooks/"+webhook.ID.String(), map[string]interface{}{"workflow_id": s.workflow.ID, "signing_secret": ""})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"has_signing_secret":false`)
+	w = s.trigger("/hooks/"+webhook.Token, body, nil)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+}
+
+func TestHandleTriggerWebhookSignatureWithoutSecrets(t *testing.T) {
+	s := setupWebhookRouterWithSecrets(t, nil)
+	webhook := s.createWebhook(t, map[string]interface{}{"workflow_id": s.workflow.ID, "signing_secret": "s3cret"})
+
+	body := `{"action": "opened"}`
+	w := s.trigger("/hooks/"+webhook.Token, body, map[string]string{webhookSignatureHeader: sign("wrong", body)})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = s.trigger("/hooks/"+webhook.Token, body, map[string]string{webhookSignatureHeader: sign("s3cret", body)})
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	// A plain secret can't pass for a sealed one
+	w = sendJSON(s.router, http.MethodPost, "/webhooks", map[string]interface{}{"workflow_id": s.workflow.ID, "signing_secret": sealedSecretPrefix + "abc"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandleTriggerWebhookRejected(t *testing.T) {
+	s := setupWebhookRouter(t)
+	disabled := s.createWebhook(t, map[string]interface{}{"workflow_id": s.workflow.ID, "enabled": false})
+
+	w := s.trigger("/hooks/unknown", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = s.trigger("/hooks/"+disabled.Token, "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Webhooks don't supply inputs, so required inputs can't be satisfied
+	withInputs := inputsWorkflow(s.workflowRepo)
+	webhook := s.createWebhook(t, map[string]interface{}{"workflow_id": withInputs.ID})
+	w = s.trigger("/hooks/"+webhook.Token, "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	assert.Empty(t, s.execRepo.executions)
+}
+
+func TestHandleRotateWebhookToken(t *testing.T) {
+	s := setupWebhookRouter(t)
+	webhook := s.createWebhook(t, map[string]interface{}{"workflow_id": s.workflow.ID})
+
+	w := sendJSON(s.router, http.MethodPost, "/webhooks/"+webhook.ID.String()+"/rotate", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var rotated repository.WebhookTrigger
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+	assert.NotEqual(t, webhook.Token, rotated.Token)
+
+	w = s.trigger("/hooks/"+webhook.Token, "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = s.trigger("/hooks/"+rotated.Token, "", nil)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	w = sendJSON(s.router, http.MethodPost, "/webhooks/"+uuid.New().String()+"/rotate", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandleUpdateAndDeleteWebhook(t *testing.T) {
+	s := setupWebhookRouter(t)
+	webhook := s.createWebhook(t, map[string]interface{}{"workflow_id": s.workflow.ID})
+
+	w := sendJSON(s.router, http.MethodPut, "/webhooks/"+webhook.ID.String(), map[string]interface{}{
+		"workflow_id": s.workflow.ID,
+		"enabled":     false,
+	})
+	assert.Equal(t, http.StatusOK, w.Code)
+	stored, _ := s.webhookRepo.GetByID(webhook.ID)
+	assert.False(t, stored.Enabled)
+	assert.Equal(t, webhook.Token, stored.Token)
+
+	w = sendJSON(s.router, http.MethodDelete, "/webhooks/"+webhook.ID.String(), nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = sendJSON(s.router, http.MethodGet, "/webhooks/"+webhook.ID.String(), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -2,6 +2,7 @@ package e2e
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -418,7 +419,7 @@ func handleRunWorkflow(
 		}
 
 		go func(execID uuid.UUID) {
-			eng.ExecuteWithLogging(*workflowDef, workflowID, logger, &execID)
+			eng.ExecuteWithLogging(context.Background(), *workflowDef, workflowID, logger, &execID)
 		}(execution.ID)
 
 		c.JSON(http.StatusAccepted, gin.H{
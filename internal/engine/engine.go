@@ -1,9 +1,10 @@
 package engine
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
+	"errors"
 	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,12 +21,14 @@ type ExecutionLogger interface {
 
 // ExecutionRecord represents an execution record for logging
 type ExecutionRecord struct {
-	ID              uuid.UUID
-	WorkflowID      uuid.UUID
-	Status          string
-	ContextSnapshot datatypes.JSON
-	StartedAt       time.Time
-	CompletedAt     *time.Time
+	ID                uuid.UUID
+	WorkflowID        uuid.UUID
+	ParentExecutionID *uuid.UUID // set for sub-workflow executions
+	ResumedFromID     *uuid.UUID // set for executions that resume a previous one
+	Status            string
+	ContextSnapshot   datatypes.JSON
+	StartedAt         time.Time
+	CompletedAt       *time.Time
 }
 
 // TaskLogRecord represents a task log record for logging
@@ -35,6 +38,7 @@ type TaskLogRecord struct {
 	TaskID      string
 	TaskType    string
 	Status      string
+	Attempt     int
 	Input       datatypes.JSON
 	Output      datatypes.JSON
 	Error       string
@@ -42,13 +46,27 @@ type TaskLogRecord struct {
 	CompletedAt time.Time
 }
 
-// Engine orchestrates workflow execution with sequential task processing
+// Engine orchestrates workflow execution. It holds only state that is safe to
+// share between executions (the task registry, the event bus, middleware and
+// hooks, and the cancel functions of in-flight executions); everything scoped to a single execution lives on a
+// Run, so one Engine can serve many concurrent requests.
 type Engine struct {
-	context  *ExecutionContext
 	registry *Registry
+	events   *EventBus
+
+	mu           sync.Mutex
+	middleware   []Middleware
+	hooks        []RunHook
+	active       map[uuid.UUID]context.CancelCauseFunc
+	idle         chan struct{} // closed when the last in-flight execution finishes
+	interrupting bool          // set once Drain gives up waiting
 }
 
-// NewEngine creates a new Engine instance with an initialized ExecutionContext and Registry.
+// ErrInterrupted is the cancellation cause of executions stopped by Drain.
+// They are recorded as "interrupted" rather than "cancelled".
+var ErrInterrupted = errors.New("execution interrupted by shutdown")
+
+// NewEngine creates a new Engine instance with the given Registry.
 // If registry is nil, a new empty registry will be created.
 func NewEngine(registry *Registry) *Engine {
 	slog.Info("Initializing new execution engine")
@@ -56,226 +74,177 @@ func NewEngine(registry *Registry) *Engine {
 		registry = NewRegistry()
 	}
 	return &Engine{
-		context:  NewExecutionContext(),
 		registry: registry,
+		events:   NewEventBus(),
+		active:   make(map[uuid.UUID]context.CancelCauseFunc),
 	}
 }
 
-// Execute processes a workflow by iterating through its tasks sequentially.
-// Each task is looked up in the registry, executed with the current context,
-// and its result is stored for subsequent tasks to access.
-func (e *Engine) Execute(workflow WorkflowDefinition) error {
-	slog.Info("Starting workflow execution", "workflow", workflow.Name, "task_count", len(workflow.Tasks))
-
-	for i, task := range workflow.Tasks {
-		slog.Info("Processing task",
-			"index", i,
-			"id", task.ID,
-			"type", task.Type,
-		)
-
-		// Lookup executor from registry
-		executor, err := e.registry.Get(task.Type)
-		if err != nil {
-			slog.Error("Task executor not found", "type", task.Type, "error", err)
-			return fmt.Errorf("task executor not found for type '%s': %w", task.Type, err)
-		}
-
-		// Execute task with current context and config
-		result := executor.Execute(e.context, task.Config)
-
-		// Handle result
-		if result.Status == "success" {
-			slog.Info("Task completed successfully",
-				"id", task.ID,
-				"type", task.Type,
-			)
-			// Store result in context for subsequent tasks
-			e.context.Set(task.ID+"_result", result.Output)
-		} else {
-			slog.Error("Task failed",
-				"id", task.ID,
-				"type", task.Type,
-				"error", result.Error,
-			)
-			return fmt.Errorf("task %s failed: %s", task.ID, result.Error)
-		}
-	}
+// Registry returns the engine's task registry.
+func (e *Engine) Registry() *Registry {
+	return e.registry
+}
 
-	slog.Info("Workflow execution completed successfully",
-		"workflow", workflow.Name,
-		"total_tasks", len(workflow.Tasks),
-	)
+// Events returns the bus that logged runs publish their progress to.
+func (e *Engine) Events() *EventBus {
+	return e.events
+}
 
-	return nil
+// NewRun creates an isolated Run with a fresh ExecutionContext, the engine's
+// registry, event bus, middleware and hooks, and the given logger. The logger
+// may be nil when the run is not persisted (see Run.Execute).
+func (e *Engine) NewRun(logger ExecutionLogger) *Run {
+	run := newRun(e.registry, logger)
+	run.events = e.events
+	e.mu.Lock()
+	run.middleware = e.middleware[:len(e.middleware):len(e.middleware)]
+	run.hooks = e.hooks[:len(e.hooks):len(e.hooks)]
+	e.mu.Unlock()
+	return run
 }
 
-// GetContext returns the engine's ExecutionContext for inspection or testing
-func (e *Engine) GetContext() *ExecutionContext {
-	return e.context
+// Execute processes a workflow in a new Run and returns the run's
+// ExecutionContext so callers can inspect task results.
+func (e *Engine) Execute(ctx context.Context, workflow WorkflowDefinition) (*ExecutionContext, error) {
+	run := e.NewRun(nil)
+	err := run.Execute(ctx, workflow)
+	return run.Context(), err
 }
 
-// ExecuteWithLogging processes a workflow with full logging to database.
-// It creates an Execution record (or updates existing if executionID is provided),
-// logs each task execution, and updates the execution status.
-// If executionID is nil, a new execution will be created.
+// ExecuteWithLogging processes a workflow in a new Run with full logging to database.
+// It is safe to call concurrently; each call gets its own ExecutionContext.
+// If executionID is nil, a new execution will be created. When executionID is
+// provided the execution can be stopped with Cancel while it is in flight.
 func (e *Engine) ExecuteWithLogging(
+	ctx context.Context,
 	workflow WorkflowDefinition,
 	workflowID uuid.UUID,
 	logger ExecutionLogger,
 	executionID *uuid.UUID,
 ) (*ExecutionRecord, error) {
-	// Create new context for this execution
-	e.context = NewExecutionContext()
+	return e.executeWithLogging(ctx, e.NewRun(logger), workflow, workflowID, executionID)
+}
 
-	// Create or update Execution record
-	var execution *ExecutionRecord
-	if executionID != nil {
-		// Use existing execution ID
-		execution = &ExecutionRecord{
-			ID:         *executionID,
-			WorkflowID: workflowID,
-			Status:     "running",
-			StartedAt:  time.Now().UTC(),
-		}
-		// Update existing execution
-		if err := logger.UpdateExecution(execution); err != nil {
-			// If update fails, try to create (execution might not exist yet)
-			if err := logger.CreateExecution(execution); err != nil {
-				return nil, fmt.Errorf("failed to create/update execution record: %w", err)
-			}
-		}
-	} else {
-		// Create new execution
-		execution = &ExecutionRecord{
-			ID:         uuid.New(),
-			WorkflowID: workflowID,
-			Status:     "running",
-			StartedAt:  time.Now().UTC(),
-		}
-		if err := logger.CreateExecution(execution); err != nil {
-			return nil, fmt.Errorf("failed to create execution record: %w", err)
-		}
+// ExecuteWithInput is ExecuteWithLogging for a run that receives input
+// values, which are seeded into the context under "input". The values should
+// already have been checked with WorkflowDefinition.ResolveInputs. A non-nil
+// trigger describes what started the run (e.g. the webhook request) and is
+// seeded under "trigger".
+func (e *Engine) ExecuteWithInput(
+	ctx context.Context,
+	workflow WorkflowDefinition,
+	workflowID uuid.UUID,
+	logger ExecutionLogger,
+	executionID *uuid.UUID,
+	input map[string]interface{},
+	trigger map[string]interface{},
+) (*ExecutionRecord, error) {
+	run := e.NewRun(logger)
+	run.context.Set("input", input)
+	if trigger != nil {
+		run.context.Set("trigger", trigger)
 	}
+	return e.executeWithLogging(ctx, run, workflow, workflowID, executionID)
+}
 
-	slog.Info("Starting workflow execution with logging",
-		"execution_id", execution.ID,
-		"workflow", workflow.Name,
-		"task_count", len(workflow.Tasks),
-	)
-
-	var executionError error
-
-	// Execute each task with logging
-	for i, task := range workflow.Tasks {
-		taskStartTime := time.Now().UTC()
-
-		// Create TaskLog record
-		taskLog := &TaskLogRecord{
-			ID:          uuid.New(),
-			ExecutionID: execution.ID,
-			TaskID:      task.ID,
-			TaskType:    task.Type,
-			Status:      "running",
-			StartedAt:   taskStartTime,
-		}
-
-		// Serialize task config as input
-		if configJSON, err := json.Marshal(task.Config); err == nil {
-			taskLog.Input = datatypes.JSON(configJSON)
-		}
-
-		// Log task start
-		if err := logger.CreateTaskLog(taskLog); err != nil {
-			slog.Error("Failed to create task log", "error", err, "task_id", task.ID)
-			// Continue execution even if logging fails
-		}
-
-		slog.Info("Processing task",
-			"execution_id", execution.ID,
-			"index", i,
-			"id", task.ID,
-			"type", task.Type,
-		)
-
-		// Lookup executor from registry
-		executor, err := e.registry.Get(task.Type)
-		if err != nil {
-			slog.Error("Task executor not found", "type", task.Type, "error", err)
-			taskLog.Status = "failed"
-			taskLog.Error = fmt.Sprintf("task executor not found for type '%s': %v", task.Type, err)
-			taskLog.CompletedAt = time.Now().UTC()
-			logger.UpdateTaskLog(taskLog) // Update task log
-			executionError = fmt.Errorf("task executor not found for type '%s': %w", task.Type, err)
-			break
-		}
+// executeWithLogging runs run with logging, tracking it for Drain and, when
+// executionID is provided, for Cancel.
+func (e *Engine) executeWithLogging(
+	ctx context.Context,
+	run *Run,
+	workflow WorkflowDefinition,
+	workflowID uuid.UUID,
+	executionID *uuid.UUID,
+) (*ExecutionRecord, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
 
-		// Execute task with current context and config
-		result := executor.Execute(e.context, task.Config)
+	key := uuid.New()
+	if executionID != nil {
+		key = *executionID
+	}
+	e.track(key, cancel)
+	defer e.untrack(key)
 
-		// Update TaskLog with result
-		taskLog.CompletedAt = time.Now().UTC()
-		if result.Status == "success" {
-			taskLog.Status = "success"
-			slog.Info("Task completed successfully",
-				"execution_id", execution.ID,
-				"id", task.ID,
-				"type", task.Type,
-			)
-			// Store result in context for subsequent tasks
-			e.context.Set(task.ID+"_result", result.Output)
+	return run.ExecuteWithLogging(ctx, workflow, workflowID, executionID)
+}
 
-			// Serialize output
-			if outputJSON, err := json.Marshal(result.Output); err == nil {
-				taskLog.Output = datatypes.JSON(outputJSON)
-			}
-		} else {
-			taskLog.Status = "failed"
-			taskLog.Error = result.Error
-			slog.Error("Task failed",
-				"execution_id", execution.ID,
-				"id", task.ID,
-				"type", task.Type,
-				"error", result.Error,
-			)
-			executionError = fmt.Errorf("task %s failed: %s", task.ID, result.Error)
-		}
+// Cancel stops an in-flight execution started by ExecuteWithLogging.
+// The running task's context is cancelled and the execution is recorded as
+// "cancelled". Returns false if the execution is not running on this engine.
+func (e *Engine) Cancel(executionID uuid.UUID) bool {
+	e.mu.Lock()
+	cancel, exists := e.active[executionID]
+	e.mu.Unlock()
 
-		// Update task log
-		if err := logger.UpdateTaskLog(taskLog); err != nil {
-			slog.Error("Failed to update task log", "error", err, "task_id", task.ID)
-		}
+	if !exists {
+		return false
+	}
+	slog.Info("Cancelling execution", "execution_id", executionID)
+	cancel(nil)
+	return true
+}
 
-		// If task failed, stop execution
-		if result.Status != "success" {
-			break
-		}
+// Drain waits for the in-flight executions started by ExecuteWithLogging to
+// finish. If ctx is done first, including when nothing is in flight, the
+// remaining executions are stopped with ErrInterrupted and recorded as
+// "interrupted"; Drain then waits for them to be recorded and returns ctx's
+// error. From then on, executions are interrupted as soon as they start,
+// e.g. one a worker claimed just before shutdown. Callers should stop
+// starting new executions before calling Drain.
+func (e *Engine) Drain(ctx context.Context) error {
+	e.mu.Lock()
+	idle := e.idle
+	e.mu.Unlock()
+
+	if idle == nil && ctx.Err() == nil {
+		return nil
+	}
+	// A nil idle never becomes ready, so only ctx ends the wait
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
 	}
 
-	// Save context snapshot
-	contextSnapshot := e.context.GetAll()
-	if snapshotJSON, err := json.Marshal(contextSnapshot); err == nil {
-		execution.ContextSnapshot = datatypes.JSON(snapshotJSON)
+	e.mu.Lock()
+	e.interrupting = true
+	idle = e.idle
+	if len(e.active) > 0 {
+		slog.Warn("Interrupting in-flight executions", "count", len(e.active))
+	}
+	for _, cancel := range e.active {
+		cancel(ErrInterrupted)
 	}
+	e.mu.Unlock()
 
-	// Update Execution status
-	completedAt := time.Now().UTC()
-	execution.CompletedAt = &completedAt
-	if executionError != nil {
-		execution.Status = "failed"
-	} else {
-		execution.Status = "completed"
-		slog.Info("Workflow execution completed successfully",
-			"execution_id", execution.ID,
-			"workflow", workflow.Name,
-			"total_tasks", len(workflow.Tasks),
-		)
+	if idle != nil {
+		<-idle
 	}
+	return ctx.Err()
+}
 
-	if err := logger.UpdateExecution(execution); err != nil {
-		slog.Error("Failed to update execution", "error", err, "execution_id", execution.ID)
-		return execution, fmt.Errorf("failed to update execution: %w", err)
+// track registers the cancel function of an in-flight execution, interrupting
+// it right away if Drain has given up waiting.
+func (e *Engine) track(key uuid.UUID, cancel context.CancelCauseFunc) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.active) == 0 {
+		e.idle = make(chan struct{})
+	}
+	e.active[key] = cancel
+	if e.interrupting {
+		cancel(ErrInterrupted)
 	}
+}
 
-	return execution, executionError
+// untrack removes a finished execution from the in-flight set.
+func (e *Engine) untrack(key uuid.UUID) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.active, key)
+	if len(e.active) == 0 && e.idle != nil {
+		close(e.idle)
+		e.idle = nil
+	}
 }
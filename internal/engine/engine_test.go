@@ -1,18 +1,20 @@
 package engine
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewEngine(t *testing.T) {
 	engine := NewEngine(nil)
 
 	assert.NotNil(t, engine)
-	assert.NotNil(t, engine.context)
 	assert.NotNil(t, engine.registry)
-	assert.Equal(t, 0, len(engine.context.GetAll()))
 }
 
 func TestNewEngine_WithRegistry(t *testing.T) {
@@ -37,19 +39,21 @@ func TestEngine_Execute_EmptyWorkflow(t *testing.T) {
 		Tasks: []Task{},
 	}
 
-	err := engine.Execute(workflow)
+	execCtx, err := engine.Execute(context.Background(), workflow)
 
 	assert.NoError(t, err)
-	assert.Equal(t, 0, len(engine.context.GetAll()))
+	assert.Equal(t, 0, len(execCtx.GetAll()))
 }
 
-func TestEngine_GetContext(t *testing.T) {
+func TestEngine_NewRun(t *testing.T) {
 	engine := NewEngine(nil)
 
-	ctx := engine.GetContext()
+	run1 := engine.NewRun(nil)
+	run2 := engine.NewRun(nil)
 
-	assert.NotNil(t, ctx)
-	assert.Same(t, engine.context, ctx)
+	assert.NotNil(t, run1.Context())
+	assert.NotSame(t, run1.Context(), run2.Context())
+	assert.Same(t, engine.registry, run1.registry)
 }
 
 func TestEngine_MultipleExecutions(t *testing.T) {
@@ -70,21 +74,21 @@ func TestEngine_MultipleExecutions(t *testing.T) {
 		Tasks: []Task{{ID: "task2", Type: "test", Config: map[string]interface{}{}}},
 	}
 
-	err1 := engine1.Execute(workflow1)
-	err2 := engine2.Execute(workflow2)
+	ctx1, err1 := engine1.Execute(context.Background(), workflow1)
+	ctx2, err2 := engine2.Execute(context.Background(), workflow2)
 
 	assert.NoError(t, err1)
 	assert.NoError(t, err2)
 
 	// Verify isolation
-	_, exists := engine1.context.Get("task1_result")
+	_, exists := ctx1.Get("task1_result")
 	assert.True(t, exists)
-	_, exists = engine1.context.Get("task2_result")
+	_, exists = ctx1.Get("task2_result")
 	assert.False(t, exists)
 
-	_, exists = engine2.context.Get("task2_result")
+	_, exists = ctx2.Get("task2_result")
 	assert.True(t, exists)
-	_, exists = engine2.context.Get("task1_result")
+	_, exists = ctx2.Get("task1_result")
 	assert.False(t, exists)
 }
 
@@ -107,11 +111,11 @@ func TestEngine_WithMockExecutor(t *testing.T) {
 		},
 	}
 
-	err := engine.Execute(workflow)
+	execCtx, err := engine.Execute(context.Background(), workflow)
 	assert.NoError(t, err)
 
 	// Verify result was stored in context
-	result, exists := engine.context.Get("task1_result")
+	result, exists := execCtx.Get("task1_result")
 	assert.True(t, exists)
 	assert.Equal(t, map[string]interface{}{"result": "success"}, result)
 }
@@ -127,7 +131,7 @@ func TestEngine_UnregisteredTaskType(t *testing.T) {
 		},
 	}
 
-	err := engine.Execute(workflow)
+	_, err := engine.Execute(context.Background(), workflow)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "task executor not found")
 	assert.Contains(t, err.Error(), "unknown_type")
@@ -150,7 +154,7 @@ func TestEngine_TaskFailure(t *testing.T) {
 		},
 	}
 
-	err := engine.Execute(workflow)
+	_, err := engine.Execute(context.Background(), workflow)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "task1 failed")
 	assert.Contains(t, err.Error(), "intentional failure")
@@ -181,15 +185,15 @@ func TestEngine_TaskFailureStopsExecution(t *testing.T) {
 		},
 	}
 
-	err := engine.Execute(workflow)
+	execCtx, err := engine.Execute(context.Background(), workflow)
 	assert.Error(t, err)
 
 	// Task1 should have no result (failed)
-	_, exists := engine.context.Get("task1_result")
+	_, exists := execCtx.Get("task1_result")
 	assert.False(t, exists)
 
 	// Task2 should not have executed
-	_, exists = engine.context.Get("task2_result")
+	_, exists = execCtx.Get("task2_result")
 	assert.False(t, exists)
 }
 
@@ -214,15 +218,15 @@ func TestEngine_MultipleTasksContextSharing(t *testing.T) {
 		},
 	}
 
-	err := engine.Execute(workflow)
+	execCtx, err := engine.Execute(context.Background(), workflow)
 	assert.NoError(t, err)
 
 	// Verify both results are in context
-	result1, exists1 := engine.context.Get("task1_result")
+	result1, exists1 := execCtx.Get("task1_result")
 	assert.True(t, exists1)
 	assert.Equal(t, map[string]interface{}{"step1": "data"}, result1)
 
-	result2, exists2 := engine.context.Get("task2_result")
+	result2, exists2 := execCtx.Get("task2_result")
 	assert.True(t, exists2)
 	assert.Equal(t, map[string]interface{}{"step2": "more data"}, result2)
 }
@@ -254,10 +258,215 @@ func TestEngine_TaskWithConfig(t *testing.T) {
 		},
 	}
 
-	err := engine.Execute(workflow)
+	execCtx, err := engine.Execute(context.Background(), workflow)
 	assert.NoError(t, err)
 
-	result, exists := engine.context.Get("task1_result")
+	result, exists := execCtx.Get("task1_result")
 	assert.True(t, exists)
 	assert.Equal(t, map[string]interface{}{"processed": true}, result)
 }
+
+// blockingExecutor signals on Started and then blocks until ctx is done.
+type blockingExecutor struct {
+	Started chan struct{}
+}
+
+func (b *blockingExecutor) Execute(ctx context.Context, execCtx *ExecutionContext, config map[string]interface{}) TaskResult {
+	if b.Started != nil {
+		b.Started <- struct{}{}
+	}
+	<-ctx.Done()
+	return TaskResult{Status: "failed", Error: ctx.Err().Error()}
+}
+
+func TestEngine_Execute_CancelledContext(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("mock", &MockExecutor{})
+	engine := NewEngine(registry)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	execCtx, err := engine.Execute(ctx, WorkflowDefinition{
+		Name:  "cancelled",
+		Tasks: []Task{{ID: "task1", Type: "mock", Config: map[string]interface{}{}}},
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	_, exists := execCtx.Get("task1_result")
+	assert.False(t, exists)
+}
+
+func TestEngine_Execute_WorkflowTimeout(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("block", &blockingExecutor{})
+	engine := NewEngine(registry)
+
+	start := time.Now()
+	_, err := engine.Execute(context.Background(), WorkflowDefinition{
+		Name:    "timeout",
+		Timeout: 1,
+		Tasks:   []Task{{ID: "task1", Type: "block", Config: map[string]interface{}{}}},
+	})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "task task1 stopped")
+	assert.Less(t, time.Since(start), 3*time.Second)
+}
+
+func TestEngine_Execute_TaskTimeoutIsRetried(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("block", &blockingExecutor{})
+	engine := NewEngine(registry)
+
+	_, err := engine.Execute(context.Background(), WorkflowDefinition{
+		Name: "task-timeout",
+		Tasks: []Task{{
+			ID:      "task1",
+			Type:    "block",
+			Config:  map[string]interface{}{},
+			Timeout: 1,
+			Retry:   &RetryPolicy{MaxAttempts: 2, DelayMs: 1},
+		}},
+	})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "task task1 failed after 2 attempts: context deadline exceeded")
+}
+
+func TestEngine_Cancel(t *testing.T) {
+	started := make(chan struct{}, 1)
+	registry := NewRegistry()
+	registry.Register("block", &blockingExecutor{Started: started})
+	registry.Register("mock", &MockExecutor{})
+	engine := NewEngine(registry)
+	logger := newMemoryLogger()
+	executionID := uuid.New()
+
+	type outcome struct {
+		record *ExecutionRecord
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		record, err := engine.ExecuteWithLogging(context.Background(), WorkflowDefinition{
+			Name: "cancellable",
+			Tasks: []Task{
+				{ID: "task1", Type: "block", Config: map[string]interface{}{}},
+				{ID: "task2", Type: "mock", Config: map[string]interface{}{}},
+			},
+		}, uuid.New(), logger, &executionID)
+		done <- outcome{record, err}
+	}()
+
+	<-started
+	assert.True(t, engine.Cancel(executionID))
+
+	result := <-done
+	assert.ErrorIs(t, result.err, context.Canceled)
+	require.NotNil(t, result.record)
+	assert.Equal(t, "cancelled", result.record.Status)
+
+	logs := logger.taskLogsFor(executionID)
+	require.Len(t, logs, 1)
+	assert.Equal(t, "task1", logs[0].TaskID)
+	assert.Equal(t, "cancelled", logs[0].Status)
+
+	// The finished execution is no longer tracked
+	assert.False(t, engine.Cancel(executionID))
+}
+
+func TestEngine_Cancel_UnknownExecution(t *testing.T) {
+	engine := NewEngine(nil)
+
+	assert.False(t, engine.Cancel(uuid.New()))
+}
+
+func TestEngine_Drain_WaitsForInFlightExecutions(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("item", &itemExecutor{delay: 50 * time.Millisecond})
+	engine := NewEngine(registry)
+	logger := newMemoryLogger()
+	executionID := uuid.New()
+
+	// Nothing in flight
+	assert.NoError(t, engine.Drain(context.Background()))
+
+	go func() {
+		_, _ = engine.ExecuteWithLogging(context.Background(), WorkflowDefinition{
+			Name:  "slow",
+			Tasks: []Task{{ID: "task1", Type: "item", Config: map[string]interface{}{}}},
+		}, uuid.New(), logger, &executionID)
+	}()
+	// Wait until the task is running
+	assert.Eventually(t, func() bool { return len(logger.taskLogsFor(executionID)) > 0 }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	assert.NoError(t, engine.Drain(ctx))
+	assert.Equal(t, "completed", logger.executions[executionID].Status)
+}
+
+func TestEngine_Drain_InterruptsAfterTimeout(t *testing.T) {
+	started := make(chan struct{}, 1)
+	registry := NewRegistry()
+	registry.Register("block", &blockingExecutor{Started: started})
+	registry.Register("mock", &MockExecutor{})
+	engine := NewEngine(registry)
+	logger := newMemoryLogger()
+	executionID := uuid.New()
+
+	workflow := WorkflowDefinition{
+		Name: "interruptible",
+		Tasks: []Task{
+			{ID: "task1", Type: "block", Config: map[string]interface{}{}},
+			{ID: "task2", Type: "mock", Config: map[string]interface{}{}},
+		},
+		OnFailure: []Task{{ID: "cleanup", Type: "mock", Config: map[string]interface{}{}}},
+	}
+	done := make(chan *ExecutionRecord, 1)
+	go func() {
+		record, _ := engine.ExecuteWithLogging(context.Background(), workflow, uuid.New(), logger, &executionID)
+		done <- record
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, engine.Drain(ctx), context.DeadlineExceeded)
+
+	record := <-done
+	require.NotNil(t, record)
+	assert.Equal(t, "interrupted", record.Status)
+	logs := logger.taskLogsFor(executionID)
+	require.Len(t, logs, 1)
+	assert.Equal(t, "interrupted", logs[0].Status)
+
+	// Executions started after Drain gave up are interrupted right away
+	lateID := uuid.New()
+	record, err := engine.ExecuteWithLogging(context.Background(), workflow, uuid.New(), logger, &lateID)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "interrupted", record.Status)
+}
+
+func TestEngine_Drain_TimeoutWithNothingInFlight(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("mock", &MockExecutor{})
+	engine := NewEngine(registry)
+	logger := newMemoryLogger()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, engine.Drain(ctx), context.Canceled)
+
+	// An execution a worker claimed before the timeout but starts afterwards
+	// is interrupted too
+	executionID := uuid.New()
+	record, err := engine.ExecuteWithLogging(context.Background(), WorkflowDefinition{
+		Name:  "late",
+		Tasks: []Task{{ID: "task1", Type: "mock", Config: map[string]interface{}{}}},
+	}, uuid.New(), logger, &executionID)
+	assert.ErrorIs(t, err, context.Canceled)
+	require.NotNil(t, record)
+	assert.Equal(t, "interrupted", record.Status)
+}
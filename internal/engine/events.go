@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types published while an execution runs
+const (
+	EventExecutionStarted  = "execution_started"
+	EventTaskStarted       = "task_started"
+	EventTaskCompleted     = "task_completed"
+	EventTaskFailed        = "task_failed"
+	EventTaskSkipped       = "task_skipped"
+	EventExecutionFinished = "execution_finished"
+)
+
+// eventBufferSize is how many events a subscriber may fall behind by before
+// further events are dropped for it
+const eventBufferSize = 64
+
+// Event describes progress of an execution. Task events carry the task and
+// attempt; finished events carry the final status and the duration.
+type Event struct {
+	Type        string    `json:"type"`
+	ExecutionID uuid.UUID `json:"execution_id"`
+	TaskID      string    `json:"task_id,omitempty"`
+	TaskType    string    `json:"task_type,omitempty"`
+	Attempt     int       `json:"attempt,omitempty"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	DurationMs  int64     `json:"duration_ms,omitempty"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// EventBus fans out execution events to subscribers of that execution. It is
+// in-process only: subscribers see events of executions run by this
+// process's Engine.
+type EventBus struct {
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[chan Event]struct{}
+}
+
+// NewEventBus creates an EventBus without subscribers
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[uuid.UUID]map[chan Event]struct{})}
+}
+
+// Subscribe returns a channel receiving the events of the given execution and
+// a function that ends the subscription. The channel is never closed.
+func (b *EventBus) Subscribe(executionID uuid.UUID) (<-chan Event, func()) {
+	ch := make(chan Event, eventBufferSize)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subscribers[executionID] == nil {
+		b.subscribers[executionID] = make(map[chan Event]struct{})
+	}
+	b.subscribers[executionID][ch] = struct{}{}
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers[executionID], ch)
+		if len(b.subscribers[executionID]) == 0 {
+			delete(b.subscribers, executionID)
+		}
+	}
+}
+
+// Publish delivers event to the subscribers of its execution. It never
+// blocks: a subscriber whose buffer is full misses the event. Publishing on a
+// nil EventBus does nothing.
+func (b *EventBus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers[event.ExecutionID] {
+		select {
+		case ch <- event:
+		default:
+			slog.Warn("Dropping execution event for slow subscriber",
+				"execution_id", event.ExecutionID,
+				"type", event.Type,
+			)
+		}
+	}
+}
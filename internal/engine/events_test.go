@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drainEvents returns the events received on ch until it stays empty
+func drainEvents(ch <-chan Event) []Event {
+	var events []Event
+	for {
+		select {
+		case event := <-ch:
+			events = append(events, event)
+		case <-time.After(20 * time.Millisecond):
+			return events
+		}
+	}
+}
+
+func TestEventBus_PublishesToSubscribersOfExecution(t *testing.T) {
+	bus := NewEventBus()
+	executionID := uuid.New()
+
+	first, unsubscribeFirst := bus.Subscribe(executionID)
+	second, unsubscribeSecond := bus.Subscribe(executionID)
+	defer unsubscribeSecond()
+	other, unsubscribeOther := bus.Subscribe(uuid.New())
+	defer unsubscribeOther()
+
+	bus.Publish(Event{Type: EventExecutionStarted, ExecutionID: executionID})
+	unsubscribeFirst()
+	bus.Publish(Event{Type: EventExecutionFinished, ExecutionID: executionID})
+
+	received := drainEvents(first)
+	require.Len(t, received, 1)
+	assert.Equal(t, EventExecutionStarted, received[0].Type)
+	assert.False(t, received[0].Timestamp.IsZero())
+	assert.Len(t, drainEvents(second), 2)
+	assert.Empty(t, drainEvents(other))
+}
+
+func TestEventBus_PublishDoesNotBlockOnSlowSubscriber(t *testing.T) {
+	bus := NewEventBus()
+	executionID := uuid.New()
+	events, unsubscribe := bus.Subscribe(executionID)
+	defer unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < eventBufferSize*2; i++ {
+			bus.Publish(Event{Type: EventTaskStarted, ExecutionID: executionID})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a full subscriber")
+	}
+	assert.Len(t, drainEvents(events), eventBufferSize)
+
+	// Publishing on a nil bus is a no-op
+	var nilBus *EventBus
+	nilBus.Publish(Event{Type: EventTaskStarted, ExecutionID: executionID})
+}
+
+func TestEngine_ExecuteWithLogging_PublishesEvents(t *testing.T) {
+	registry := conditionalRegistry()
+	registry.Register("fail", &MockExecutor{ShouldFail: true, ErrorMsg: "boom"})
+	engine := NewEngine(registry)
+
+	workflow := conditionalWorkflow("fetch_result.status_code == 200")
+	workflow.Tasks = append(workflow.Tasks, Task{ID: "broken", Type: "fail", Config: map[string]interface{}{}})
+
+	executionID := uuid.New()
+	events, unsubscribe := engine.Events().Subscribe(executionID)
+	defer unsubscribe()
+
+	_, err := engine.ExecuteWithLogging(context.Background(), workflow, uuid.New(), newMemoryLogger(), &executionID)
+	assert.Error(t, err)
+
+	var types []string
+	for _, event := range drainEvents(events) {
+		assert.Equal(t, executionID, event.ExecutionID)
+		types = append(types, event.Type+":"+event.TaskID)
+	}
+	assert.Equal(t, []string{
+		"execution_started:",
+		"task_started:fetch",
+		"task_completed:fetch",
+		"task_skipped:parse",
+		"task_started:report",
+		"task_completed:report",
+		"task_started:broken",
+		"task_failed:broken",
+		"execution_finished:",
+	}, types)
+}
+
+func TestEngine_ExecuteWithLogging_FinishedEventCarriesOutcome(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("fail", &MockExecutor{ShouldFail: true, ErrorMsg: "boom"})
+	engine := NewEngine(registry)
+
+	executionID := uuid.New()
+	events, unsubscribe := engine.Events().Subscribe(executionID)
+	defer unsubscribe()
+
+	workflow := WorkflowDefinition{Name: "failing", Tasks: []Task{{ID: "broken", Type: "fail"}}}
+	_, err := engine.ExecuteWithLogging(context.Background(), workflow, uuid.New(), newMemoryLogger(), &executionID)
+	assert.Error(t, err)
+
+	received := drainEvents(events)
+	require.NotEmpty(t, received)
+	failed := received[len(received)-2]
+	assert.Equal(t, EventTaskFailed, failed.Type)
+	assert.Equal(t, "failed", failed.Status)
+	assert.Equal(t, "boom", failed.Error)
+	assert.Equal(t, 1, failed.Attempt)
+
+	finished := received[len(received)-1]
+	assert.Equal(t, EventExecutionFinished, finished.Type)
+	assert.Equal(t, "failed", finished.Status)
+	assert.Contains(t, finished.Error, "boom")
+}
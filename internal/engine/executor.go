@@ -1,13 +1,60 @@
 package engine
 
+import "context"
+
 // TaskExecutor defines the contract for all task implementations.
 // Each task type (http_request, html_parser, transform, etc.) must implement this interface.
-// The Execute method receives the current ExecutionContext and task-specific configuration,
-// and returns a TaskResult indicating success or failure.
+// The Execute method receives a context.Context for cancellation and deadlines, the current
+// ExecutionContext and task-specific configuration, and returns a TaskResult indicating success or failure.
 type TaskExecutor interface {
 	// Execute runs the task with the given context and configuration.
+	// ctx is cancelled when the execution is cancelled or its workflow/task timeout expires;
+	// long-running tasks should stop and return a failed TaskResult when it is done.
 	// The ExecutionContext allows reading data from previous tasks and storing output.
 	// The config map contains task-specific configuration from the workflow JSON.
 	// Returns a TaskResult indicating success/failure, output data, and any error message.
-	Execute(ctx *ExecutionContext, config map[string]interface{}) TaskResult
+	Execute(ctx context.Context, execCtx *ExecutionContext, config map[string]interface{}) TaskResult
+}
+
+// ConfigValidator is an optional interface for TaskExecutors that can check
+// a task's configuration before the workflow runs, e.g. for missing required
+// keys or templates that do not parse. It is used by Validate.
+type ConfigValidator interface {
+	// ValidateConfig returns an error describing the first problem found in config.
+	ValidateConfig(config map[string]interface{}) error
+}
+
+// ContextPathProvider is an optional interface for TaskExecutors that name
+// the config keys holding paths into the workflow context, such as
+// "login_result.body.token". Validate checks the <id>_result keys of the
+// strings under those keys only; without it every string of the config that
+// looks like such a path is checked. Templates are checked in both cases.
+type ContextPathProvider interface {
+	ContextPathKeys() []string
+}
+
+// TemplateProvider is an optional interface for TaskExecutors that execute
+// some config strings as Go templates. TemplateKeys names the config keys
+// holding them, directly or in nested maps and lists. Middleware rewriting
+// configs, like secret resolution, uses it so that the values it inserts
+// there are rendered literally rather than executed.
+type TemplateProvider interface {
+	TemplateKeys() []string
+}
+
+// TaskSchema describes a task type for documentation, UIs and validation.
+// Config is a JSON Schema for the task's config; Validate checks configs
+// against its type, required, properties, additionalProperties, items and
+// enum keywords. Output describes the shape of TaskResult.Output, also as a
+// JSON Schema.
+type TaskSchema struct {
+	Description string                 `json:"description"`
+	Config      map[string]interface{} `json:"config_schema"`
+	Output      map[string]interface{} `json:"output_schema"`
+}
+
+// SchemaProvider is an optional interface for TaskExecutors that describe
+// their configuration and output.
+type SchemaProvider interface {
+	Schema() TaskSchema
 }
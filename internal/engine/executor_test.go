@@ -1,6 +1,7 @@
 package engine
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -15,7 +16,7 @@ func TestMockExecutor_Success(t *testing.T) {
 	ctx := NewExecutionContext()
 	config := map[string]interface{}{}
 
-	result := executor.Execute(ctx, config)
+	result := executor.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.NotNil(t, result.Output)
@@ -30,7 +31,7 @@ func TestMockExecutor_SuccessWithDefaultOutput(t *testing.T) {
 	}
 
 	ctx := NewExecutionContext()
-	result := executor.Execute(ctx, map[string]interface{}{})
+	result := executor.Execute(context.Background(), ctx, map[string]interface{}{})
 
 	assert.Equal(t, "success", result.Status)
 	assert.Equal(t, map[string]interface{}{"mock": "result"}, result.Output)
@@ -44,7 +45,7 @@ func TestMockExecutor_Failure(t *testing.T) {
 	}
 
 	ctx := NewExecutionContext()
-	result := executor.Execute(ctx, map[string]interface{}{})
+	result := executor.Execute(context.Background(), ctx, map[string]interface{}{})
 
 	assert.Equal(t, "failed", result.Status)
 	assert.Nil(t, result.Output)
@@ -67,7 +68,7 @@ func TestMockExecutor_WithConfig(t *testing.T) {
 		"param2": 123,
 	}
 
-	result := executor.Execute(ctx, config)
+	result := executor.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.NotNil(t, result.Output)
@@ -0,0 +1,446 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// EvaluateCondition evaluates a `when` expression against the given data
+// (usually ExecutionContext.GetAll()) and reports whether it holds.
+//
+// Supported syntax:
+//   - paths into the data: fetch_result.status_code, parse_result[0].titles
+//   - literals: numbers, 'single' or "double" quoted strings, true, false, null
+//   - comparisons: ==, !=, <, <=, >, >= (ordering works on numbers and strings)
+//   - logic: &&, ||, ! and parentheses; the right side of && and || is not
+//     evaluated once the left side decides the result, so guards like
+//     fetch_result != null && fetch_result.status_code >= 200 hold
+//
+// A path that does not exist evaluates to null. A bare value is true unless
+// it is null, false, zero, an empty string or an empty collection.
+func EvaluateCondition(expr string, data map[string]interface{}) (bool, error) {
+	tokens, err := tokenizeExpression(expr)
+	if err != nil {
+		return false, err
+	}
+
+	p := &exprParser{tokens: tokens, data: data}
+	value, err := p.parseOr()
+	if err != nil {
+		return false, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return false, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+	return truthy(value), nil
+}
+
+// conditionPaths checks the syntax of a `when` expression without evaluating
+// it and returns the context paths it refers to.
+func conditionPaths(expr string) ([]string, error) {
+	tokens, err := tokenizeExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tokens: tokens, syntaxOnly: true}
+	if _, err := p.parseOr(); err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+	return p.paths, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPath
+	tokenNumber
+	tokenString
+	tokenOperator
+	tokenLParen
+	tokenRParen
+)
+
+type exprToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// tokenizeExpression splits an expression into tokens.
+func tokenizeExpression(expr string) ([]exprToken, error) {
+	var tokens []exprToken
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, exprToken{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, exprToken{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case r == '\'' || r == '"':
+			start := i
+			i++
+			var sb strings.Builder
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++
+			tokens = append(tokens, exprToken{kind: tokenString, text: sb.String(), pos: start})
+		case strings.ContainsRune("=!<>&|", r):
+			start := i
+			op := string(r)
+			if i+1 < len(runes) {
+				two := string(runes[i : i+2])
+				switch two {
+				case "==", "!=", "<=", ">=", "&&", "||":
+					op = two
+				}
+			}
+			if op == "=" || op == "&" || op == "|" {
+				return nil, fmt.Errorf("unexpected %q at position %d", op, start)
+			}
+			i += len(op)
+			tokens = append(tokens, exprToken{kind: tokenOperator, text: op, pos: start})
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) ||
+				strings.ContainsRune("_.[]-", runes[i])) {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: tokenPath, text: string(runes[start:i]), pos: start})
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+		}
+	}
+
+	return append(tokens, exprToken{kind: tokenEOF, text: "end of expression", pos: len(runes)}), nil
+}
+
+// exprParser is a recursive descent parser that evaluates while parsing.
+// With syntaxOnly set it only checks the syntax and collects paths.
+type exprParser struct {
+	tokens     []exprToken
+	pos        int
+	data       map[string]interface{}
+	syntaxOnly bool
+	paths      []string
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *exprParser) parseOr() (interface{}, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOperator && p.peek().text == "||" {
+		p.next()
+		if !p.syntaxOnly && truthy(left) {
+			if err := p.skip(p.parseAnd); err != nil {
+				return nil, err
+			}
+			left = true
+			continue
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = truthy(left) || truthy(right)
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (interface{}, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOperator && p.peek().text == "&&" {
+		p.next()
+		if !p.syntaxOnly && !truthy(left) {
+			if err := p.skip(p.parseNot); err != nil {
+				return nil, err
+			}
+			left = false
+			continue
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = truthy(left) && truthy(right)
+	}
+	return left, nil
+}
+
+// skip parses an operand that doesn't affect the result, checking its
+// syntax without evaluating it
+func (p *exprParser) skip(parse func() (interface{}, error)) error {
+	syntaxOnly := p.syntaxOnly
+	p.syntaxOnly = true
+	defer func() { p.syntaxOnly = syntaxOnly }()
+	_, err := parse()
+	return err
+}
+
+func (p *exprParser) parseNot() (interface{}, error) {
+	if p.peek().kind == tokenOperator && p.peek().text == "!" {
+		p.next()
+		value, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return !truthy(value), nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (interface{}, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	if tok.kind != tokenOperator {
+		return left, nil
+	}
+	switch tok.text {
+	case "==", "!=", "<", "<=", ">", ">=":
+	default:
+		return left, nil
+	}
+	p.next()
+
+	right, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if p.syntaxOnly {
+		return nil, nil
+	}
+	return compareValues(tok.text, left, right)
+}
+
+func (p *exprParser) parsePrimary() (interface{}, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos)
+		}
+		return n, nil
+	case tokenString:
+		return tok.text, nil
+	case tokenPath:
+		switch tok.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null", "nil":
+			return nil, nil
+		}
+		if p.syntaxOnly {
+			p.paths = append(p.paths, tok.text)
+			return nil, nil
+		}
+		return ResolvePath(p.data, tok.text)
+	case tokenLParen:
+		value, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ')' at position %d", closing.pos)
+		}
+		return value, nil
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+}
+
+// ResolvePath looks up a dotted path such as a.b[0].c in data, using the
+// same path syntax as EvaluateCondition.
+// Missing keys and out-of-range indexes resolve to nil.
+func ResolvePath(data map[string]interface{}, path string) (interface{}, error) {
+	var current interface{} = data
+	for _, segment := range strings.Split(path, ".") {
+		name := segment
+		var indexes []int
+		if open := strings.IndexByte(segment, '['); open >= 0 {
+			name = segment[:open]
+			rest := segment[open:]
+			for rest != "" {
+				closing := strings.IndexByte(rest, ']')
+				if rest[0] != '[' || closing < 0 {
+					return nil, fmt.Errorf("invalid path %q", path)
+				}
+				idx, err := strconv.Atoi(rest[1:closing])
+				if err != nil {
+					return nil, fmt.Errorf("invalid index in path %q", path)
+				}
+				indexes = append(indexes, idx)
+				rest = rest[closing+1:]
+			}
+		}
+
+		if name != "" {
+			current = lookupKey(current, name)
+		}
+		for _, idx := range indexes {
+			current = lookupIndex(current, idx)
+		}
+		if current == nil {
+			return nil, nil
+		}
+	}
+	return current, nil
+}
+
+func lookupKey(value interface{}, key string) interface{} {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return nil
+	}
+	found := v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+	if !found.IsValid() {
+		return nil
+	}
+	return found.Interface()
+}
+
+func lookupIndex(value interface{}, idx int) interface{} {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil
+	}
+	if idx < 0 || idx >= v.Len() {
+		return nil
+	}
+	return v.Index(idx).Interface()
+}
+
+// compareValues applies a comparison operator. Numbers of any Go numeric
+// type are compared as float64.
+func compareValues(op string, left, right interface{}) (bool, error) {
+	ln, lIsNum := toFloat(left)
+	rn, rIsNum := toFloat(right)
+
+	switch op {
+	case "==", "!=":
+		var equal bool
+		if lIsNum && rIsNum {
+			equal = ln == rn
+		} else {
+			equal = reflect.DeepEqual(left, right)
+		}
+		if op == "==" {
+			return equal, nil
+		}
+		return !equal, nil
+	}
+
+	if lIsNum && rIsNum {
+		switch op {
+		case "<":
+			return ln < rn, nil
+		case "<=":
+			return ln <= rn, nil
+		case ">":
+			return ln > rn, nil
+		default:
+			return ln >= rn, nil
+		}
+	}
+
+	ls, lIsStr := left.(string)
+	rs, rIsStr := right.(string)
+	if lIsStr && rIsStr {
+		switch op {
+		case "<":
+			return ls < rs, nil
+		case "<=":
+			return ls <= rs, nil
+		case ">":
+			return ls > rs, nil
+		default:
+			return ls >= rs, nil
+		}
+	}
+
+	return false, fmt.Errorf("cannot compare %T %s %T", left, op, right)
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// truthy reports whether a value counts as true in a condition.
+func truthy(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	if b, ok := value.(bool); ok {
+		return b
+	}
+	if n, ok := toFloat(value); ok {
+		return n != 0
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String, reflect.Map, reflect.Slice, reflect.Array:
+		return v.Len() > 0
+	case reflect.Ptr, reflect.Interface:
+		return !v.IsNil()
+	}
+	return true
+}
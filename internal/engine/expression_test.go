@@ -0,0 +1,165 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateCondition(t *testing.T) {
+	data := map[string]interface{}{
+		"fetch_result": map[string]interface{}{
+			"status_code": 200,
+			"headers":     http.Header{"Content-Type": []string{"text/html"}},
+			"body":        map[string]interface{}{"ok": true, "count": float64(3)},
+		},
+		"parse_result": []map[string]interface{}{
+			{"title": "first"},
+		},
+		"name":  "listings",
+		"empty": "",
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"fetch_result.status_code == 200", true},
+		{"fetch_result.status_code != 200", false},
+		{"fetch_result.status_code >= 400", false},
+		{"fetch_result.body.count > 2 && fetch_result.body.ok", true},
+		{"fetch_result.body.ok == true", true},
+		{"fetch_result.headers.Content-Type[0] == 'text/html'", true},
+		{"parse_result[0].title == \"first\"", true},
+		{"parse_result[1].title == null", true},
+		{"missing", false},
+		{"missing == null", true},
+		{"!missing", true},
+		{"empty || name == 'listings'", true},
+		{"name < 'm' && !(fetch_result.status_code == 500)", true},
+		{"-1 < 0", true},
+		{"missing != null && missing.status_code >= 200", false},
+		{"missing == null || missing.status_code >= 200", true},
+		{"fetch_result != null && fetch_result.status_code >= 200", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := EvaluateCondition(tt.expr, data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEvaluateCondition_Errors(t *testing.T) {
+	data := map[string]interface{}{"code": 200, "name": "x"}
+
+	for _, expr := range []string{
+		"",
+		"code = 200",
+		"code ==",
+		"(code == 200",
+		"code == 200)",
+		"name == 'unterminated",
+		"code > 'abc'",
+		"code[x] == 1",
+		"code == 200 && code > 'abc'",
+		"code == 200 || (code ==",
+		"missing && code ==",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := EvaluateCondition(expr, data)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func conditionalWorkflow(when string) WorkflowDefinition {
+	return WorkflowDefinition{
+		Name: "conditional",
+		Tasks: []Task{
+			{ID: "fetch", Type: "fetch", Config: map[string]interface{}{}},
+			{ID: "parse", Type: "mock", Config: map[string]interface{}{}, When: when},
+			{ID: "report", Type: "mock", Config: map[string]interface{}{}},
+		},
+	}
+}
+
+func conditionalRegistry() *Registry {
+	registry := NewRegistry()
+	registry.Register("fetch", &MockExecutor{Output: map[string]interface{}{"status_code": 404}})
+	registry.Register("mock", &MockExecutor{})
+	return registry
+}
+
+func TestEngine_Execute_SkipsTaskWhenConditionIsFalse(t *testing.T) {
+	registry := conditionalRegistry()
+
+	execCtx, err := NewEngine(registry).Execute(context.Background(),
+		conditionalWorkflow("fetch_result.status_code == 200"))
+
+	require.NoError(t, err)
+	_, exists := execCtx.Get("parse_result")
+	assert.False(t, exists)
+	_, exists = execCtx.Get("report_result")
+	assert.True(t, exists, "tasks after a skipped task still run")
+}
+
+func TestEngine_Execute_RunsTaskWhenConditionIsTrue(t *testing.T) {
+	registry := conditionalRegistry()
+
+	execCtx, err := NewEngine(registry).Execute(context.Background(),
+		conditionalWorkflow("fetch_result.status_code == 404"))
+
+	require.NoError(t, err)
+	_, exists := execCtx.Get("parse_result")
+	assert.True(t, exists)
+}
+
+func TestEngine_Execute_NullGuardSkipsTask(t *testing.T) {
+	registry := conditionalRegistry()
+
+	execCtx, err := NewEngine(registry).Execute(context.Background(),
+		conditionalWorkflow("missing_result != null && missing_result.status_code >= 200"))
+
+	require.NoError(t, err)
+	_, exists := execCtx.Get("parse_result")
+	assert.False(t, exists)
+}
+
+func TestEngine_Execute_InvalidConditionFailsTask(t *testing.T) {
+	registry := conditionalRegistry()
+
+	_, err := NewEngine(registry).Execute(context.Background(),
+		conditionalWorkflow("fetch_result.status_code ="))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "task parse has an invalid when condition")
+}
+
+func TestEngine_ExecuteWithLogging_RecordsSkippedTask(t *testing.T) {
+	registry := conditionalRegistry()
+	logger := newMemoryLogger()
+
+	record, err := NewEngine(registry).ExecuteWithLogging(context.Background(),
+		conditionalWorkflow("fetch_result.status_code == 200"), uuid.New(), logger, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "completed", record.Status)
+	assert.NotContains(t, string(record.ContextSnapshot), "parse_result")
+
+	statuses := make(map[string]string)
+	for _, tl := range logger.taskLogsFor(record.ID) {
+		statuses[tl.TaskID] = tl.Status
+	}
+	assert.Equal(t, map[string]string{
+		"fetch":  "success",
+		"parse":  "skipped",
+		"report": "success",
+	}, statuses)
+}
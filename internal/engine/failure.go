@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// taskError records which task caused a run to fail.
+type taskError struct {
+	taskID string
+	err    error
+}
+
+func (e *taskError) Error() string {
+	return e.err.Error()
+}
+
+func (e *taskError) Unwrap() error {
+	return e.err
+}
+
+// graphTask adapts execute to the scheduler callback. Tasks already completed
+// by a resumed execution are not run again. Failures of tasks with
+// continue_on_error are recorded under <id>_error in the context and do not
+// stop the run; other failures are tagged with the failing task's ID.
+func (r *Run) graphTask(ctx context.Context, execute func(ctx context.Context, i int, task Task) error) func(i int, task Task) error {
+	return func(i int, task Task) error {
+		if r.completed[task.ID] {
+			slog.Info("Task already completed in resumed execution", "id", task.ID)
+			return nil
+		}
+
+		err := execute(ctx, i, task)
+		if err == nil {
+			return nil
+		}
+		if task.ContinueOnError && ctx.Err() == nil {
+			slog.Warn("Task failed, continuing because of continue_on_error", "id", task.ID, "error", err)
+			r.context.Set(task.ID+"_error", err.Error())
+			r.tolerated.Store(true)
+			return nil
+		}
+		return &taskError{taskID: task.ID, err: err}
+	}
+}
+
+// runOnFailure executes the workflow's on_failure tasks after the run failed
+// with runErr. The failing task's ID and the error are exposed in the context
+// as failure.task_id and failure.error (task_id is empty if the failure did
+// not come from a task). The handlers are not run when the execution was
+// cancelled, and they are not stopped by the run's timeout having expired;
+// they get a fresh workflow timeout instead. Their own failures are only logged.
+func (r *Run) runOnFailure(
+	ctx context.Context,
+	workflow WorkflowDefinition,
+	runErr error,
+	execute func(ctx context.Context, i int, task Task) error,
+) {
+	if len(workflow.OnFailure) == 0 || errors.Is(ctx.Err(), context.Canceled) {
+		return
+	}
+
+	failedTaskID := ""
+	var te *taskError
+	if errors.As(runErr, &te) {
+		failedTaskID = te.taskID
+	}
+	r.context.Set("failure", map[string]interface{}{
+		"task_id": failedTaskID,
+		"error":   runErr.Error(),
+	})
+
+	slog.Info("Running on_failure tasks",
+		"workflow", workflow.Name,
+		"failed_task", failedTaskID,
+		"task_count", len(workflow.OnFailure),
+	)
+
+	ctx, cancel := withTimeoutSeconds(context.WithoutCancel(ctx), workflow.Timeout)
+	defer cancel()
+
+	graph, err := buildTaskGraph(workflow.OnFailure)
+	if err != nil {
+		slog.Error("Invalid on_failure task graph", "workflow", workflow.Name, "error", err)
+		return
+	}
+
+	err = graph.run(ctx, workflow.Parallelism, func(i int, task Task) error {
+		return execute(ctx, i, task)
+	})
+	if err != nil {
+		slog.Error("on_failure tasks failed", "workflow", workflow.Name, "error", err)
+	}
+}
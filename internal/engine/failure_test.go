@@ -0,0 +1,167 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failureEchoExecutor returns the "failure" context value.
+type failureEchoExecutor struct{}
+
+func (e *failureEchoExecutor) Execute(ctx context.Context, execCtx *ExecutionContext, config map[string]interface{}) TaskResult {
+	failure, _ := execCtx.Get("failure")
+	return TaskResult{Status: "success", Output: failure}
+}
+
+func failureRegistry() *Registry {
+	registry := NewRegistry()
+	registry.Register("mock", &MockExecutor{})
+	registry.Register("failing", &MockExecutor{ShouldFail: true, ErrorMsg: "boom"})
+	registry.Register("failure_echo", &failureEchoExecutor{})
+	return registry
+}
+
+func TestEngine_Execute_ContinueOnError(t *testing.T) {
+	execCtx, err := NewEngine(failureRegistry()).Execute(context.Background(), WorkflowDefinition{
+		Name: "tolerant",
+		Tasks: []Task{
+			{ID: "optional", Type: "failing", Config: map[string]interface{}{}, ContinueOnError: true},
+			{ID: "next", Type: "mock", Config: map[string]interface{}{}},
+		},
+	})
+
+	require.NoError(t, err)
+	failure, exists := execCtx.Get("optional_error")
+	assert.True(t, exists)
+	assert.Equal(t, "task optional failed: boom", failure)
+	_, exists = execCtx.Get("optional_result")
+	assert.False(t, exists)
+	_, exists = execCtx.Get("next_result")
+	assert.True(t, exists)
+}
+
+func TestEngine_Execute_OnFailureReceivesFailedTask(t *testing.T) {
+	execCtx, err := NewEngine(failureRegistry()).Execute(context.Background(), WorkflowDefinition{
+		Name: "with-cleanup",
+		Tasks: []Task{
+			{ID: "first", Type: "mock", Config: map[string]interface{}{}},
+			{ID: "broken", Type: "failing", Config: map[string]interface{}{}},
+			{ID: "never", Type: "mock", Config: map[string]interface{}{}},
+		},
+		OnFailure: []Task{
+			{ID: "notify", Type: "failure_echo", Config: map[string]interface{}{}},
+		},
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "task broken failed: boom")
+	_, exists := execCtx.Get("never_result")
+	assert.False(t, exists)
+
+	notified, exists := execCtx.Get("notify_result")
+	require.True(t, exists)
+	assert.Equal(t, map[string]interface{}{
+		"task_id": "broken",
+		"error":   "task broken failed: boom",
+	}, notified)
+}
+
+func TestEngine_Execute_OnFailureNotRunOnSuccess(t *testing.T) {
+	execCtx, err := NewEngine(failureRegistry()).Execute(context.Background(), WorkflowDefinition{
+		Name:      "no-cleanup",
+		Tasks:     []Task{{ID: "ok", Type: "mock", Config: map[string]interface{}{}}},
+		OnFailure: []Task{{ID: "notify", Type: "failure_echo", Config: map[string]interface{}{}}},
+	})
+
+	require.NoError(t, err)
+	_, exists := execCtx.Get("notify_result")
+	assert.False(t, exists)
+	_, exists = execCtx.Get("failure")
+	assert.False(t, exists)
+}
+
+func TestEngine_Execute_OnFailureRunsAfterWorkflowTimeout(t *testing.T) {
+	registry := failureRegistry()
+	registry.Register("block", &blockingExecutor{})
+
+	execCtx, err := NewEngine(registry).Execute(context.Background(), WorkflowDefinition{
+		Name:      "timed-out",
+		Timeout:   1,
+		Tasks:     []Task{{ID: "slow", Type: "block", Config: map[string]interface{}{}}},
+		OnFailure: []Task{{ID: "notify", Type: "failure_echo", Config: map[string]interface{}{}}},
+	})
+
+	require.Error(t, err)
+	notified, exists := execCtx.Get("notify_result")
+	require.True(t, exists)
+	assert.Equal(t, "slow", notified.(map[string]interface{})["task_id"])
+}
+
+func TestEngine_ExecuteWithLogging_CompletedWithErrors(t *testing.T) {
+	logger := newMemoryLogger()
+
+	record, err := NewEngine(failureRegistry()).ExecuteWithLogging(context.Background(), WorkflowDefinition{
+		Name: "tolerant",
+		Tasks: []Task{
+			{ID: "optional", Type: "failing", Config: map[string]interface{}{}, ContinueOnError: true},
+			{ID: "next", Type: "mock", Config: map[string]interface{}{}},
+		},
+	}, uuid.New(), logger, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "completed_with_errors", record.Status)
+	assert.Contains(t, string(record.ContextSnapshot), `"optional_error"`)
+
+	statuses := make(map[string]string)
+	for _, tl := range logger.taskLogsFor(record.ID) {
+		statuses[tl.TaskID] = tl.Status
+	}
+	assert.Equal(t, map[string]string{"optional": "failed", "next": "success"}, statuses)
+}
+
+func TestEngine_ExecuteWithLogging_OnFailureIsLogged(t *testing.T) {
+	logger := newMemoryLogger()
+
+	record, err := NewEngine(failureRegistry()).ExecuteWithLogging(context.Background(), WorkflowDefinition{
+		Name:      "with-cleanup",
+		Tasks:     []Task{{ID: "broken", Type: "failing", Config: map[string]interface{}{}}},
+		OnFailure: []Task{{ID: "notify", Type: "failure_echo", Config: map[string]interface{}{}}},
+	}, uuid.New(), logger, nil)
+
+	require.Error(t, err)
+	assert.Equal(t, "failed", record.Status)
+	assert.Contains(t, string(record.ContextSnapshot), `"failure"`)
+
+	statuses := make(map[string]string)
+	for _, tl := range logger.taskLogsFor(record.ID) {
+		statuses[tl.TaskID] = tl.Status
+	}
+	assert.Equal(t, map[string]string{"broken": "failed", "notify": "success"}, statuses)
+}
+
+func TestEngine_ExecuteWithLogging_OnFailureSkippedWhenCancelled(t *testing.T) {
+	registry := failureRegistry()
+	blocker := &blockingExecutor{Started: make(chan struct{})}
+	registry.Register("block", blocker)
+	logger := newMemoryLogger()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-blocker.Started
+		cancel()
+	}()
+
+	record, err := NewEngine(registry).ExecuteWithLogging(ctx, WorkflowDefinition{
+		Name:      "cancelled",
+		Tasks:     []Task{{ID: "slow", Type: "block", Config: map[string]interface{}{}}},
+		OnFailure: []Task{{ID: "notify", Type: "failure_echo", Config: map[string]interface{}{}}},
+	}, uuid.New(), logger, nil)
+
+	require.Error(t, err)
+	assert.Equal(t, "cancelled", record.Status)
+	assert.Len(t, logger.taskLogsFor(record.ID), 1)
+}
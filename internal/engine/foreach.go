@@ -0,0 +1,147 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ForEach configures a task that runs once per element of an array in the
+// execution context. Each run sees the workflow context plus "item" (the
+// element) and "index" (its position), so templates can use
+// {{.context.item}} and {{.context.index}}. Values written to the context by
+// a single item are not visible to other items or later tasks; the outputs
+// are collected, in item order, into an array stored under <id>_result.
+type ForEach struct {
+	// Items is the context path of the array to iterate, e.g. "parse_listings_result"
+	// or "fetch_result.body.items" (see EvaluateCondition for the path syntax)
+	Items string `json:"items"`
+	// Parallelism caps how many items run at once (default: DefaultParallelism)
+	Parallelism int `json:"parallelism,omitempty"`
+	// FailFast stops starting new items and cancels running ones after the
+	// first item fails. Otherwise every item runs and the failures are reported together.
+	FailFast bool `json:"fail_fast,omitempty"`
+}
+
+// executeForEach runs a foreach task over its items. Each item is retried
+// independently according to the task's RetryPolicy. The returned result is
+// successful only if every item succeeded; its Output always holds the
+// collected item outputs (nil for items that failed or never ran).
+func (r *Run) executeForEach(ctx context.Context, executor TaskExecutor, task Task) TaskResult {
+	items, err := r.forEachItems(task)
+	if err != nil {
+		return TaskResult{Status: "failed", Error: err.Error()}
+	}
+
+	parallelism := task.ForEach.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism
+	}
+
+	slog.Info("Starting foreach task",
+		"id", task.ID,
+		"type", task.Type,
+		"items", len(items),
+		"parallelism", parallelism,
+	)
+
+	itemsCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]interface{}, len(items))
+	failures := make([]string, len(items))
+	var firstFailure string
+	var once sync.Once
+
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for index, item := range items {
+		sem <- struct{}{}
+		if itemsCtx.Err() != nil {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func(index int, item interface{}) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, err := r.executeWithRetry(itemsCtx, executor, task, r.itemContext(item, index))
+			results[index] = result.Output
+			if err == nil {
+				return
+			}
+
+			message := result.Error
+			if message == "" {
+				message = err.Error()
+			}
+			failures[index] = fmt.Sprintf("item %d: %s", index, message)
+			once.Do(func() { firstFailure = failures[index] })
+			if task.ForEach.FailFast {
+				cancel()
+			}
+		}(index, item)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return TaskResult{Status: "failed", Output: results, Error: fmt.Sprintf("foreach stopped: %v", ctx.Err())}
+	}
+
+	var failed []string
+	for _, failure := range failures {
+		if failure != "" {
+			failed = append(failed, failure)
+		}
+	}
+	if len(failed) == 0 {
+		return TaskResult{Status: "success", Output: results}
+	}
+	if task.ForEach.FailFast {
+		return TaskResult{Status: "failed", Output: results, Error: firstFailure}
+	}
+	return TaskResult{
+		Status: "failed",
+		Output: results,
+		Error:  fmt.Sprintf("%d of %d items failed: %s", len(failed), len(items), strings.Join(failed, "; ")),
+	}
+}
+
+// forEachItems resolves the array a foreach task iterates over.
+func (r *Run) forEachItems(task Task) ([]interface{}, error) {
+	value, err := ResolvePath(r.context.GetAll(), task.ForEach.Items)
+	if err != nil {
+		return nil, fmt.Errorf("invalid foreach items: %w", err)
+	}
+	if value == nil {
+		return nil, fmt.Errorf("foreach items '%s' not found in context", task.ForEach.Items)
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("foreach items '%s' is not an array (got %T)", task.ForEach.Items, value)
+	}
+
+	items := make([]interface{}, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items, nil
+}
+
+// itemContext builds the ExecutionContext one foreach item runs with: a copy
+// of the run's context plus "item" and "index".
+func (r *Run) itemContext(item interface{}, index int) *ExecutionContext {
+	itemCtx := NewExecutionContext()
+	for key, value := range r.context.GetAll() {
+		itemCtx.Set(key, value)
+	}
+	itemCtx.Set("item", item)
+	itemCtx.Set("index", index)
+	return itemCtx
+}
@@ -0,0 +1,198 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// itemExecutor returns "<index>:<item>" for each foreach item and fails for
+// items equal to "bad". It counts the calls it receives.
+type itemExecutor struct {
+	delay time.Duration
+	calls int32
+}
+
+func (e *itemExecutor) Execute(ctx context.Context, execCtx *ExecutionContext, config map[string]interface{}) TaskResult {
+	atomic.AddInt32(&e.calls, 1)
+	item, _ := execCtx.Get("item")
+	index, _ := execCtx.Get("index")
+	execCtx.Set("scratch", item)
+
+	select {
+	case <-time.After(e.delay):
+	case <-ctx.Done():
+		return TaskResult{Status: "failed", Error: ctx.Err().Error()}
+	}
+
+	if item == "bad" {
+		return TaskResult{Status: "failed", Error: fmt.Sprintf("bad item at %v", index)}
+	}
+	return TaskResult{Status: "success", Output: fmt.Sprintf("%v:%v", index, item)}
+}
+
+func forEachWorkflow(forEach *ForEach) WorkflowDefinition {
+	return WorkflowDefinition{
+		Name: "foreach",
+		Tasks: []Task{
+			{ID: "each", Type: "item", Config: map[string]interface{}{}, ForEach: forEach},
+		},
+	}
+}
+
+func runForEach(t *testing.T, executor TaskExecutor, items interface{}, forEach *ForEach) (*ExecutionContext, error) {
+	t.Helper()
+	registry := NewRegistry()
+	registry.Register("item", executor)
+	run := NewEngine(registry).NewRun(nil)
+	run.Context().Set("listing", map[string]interface{}{"links": items})
+	err := run.Execute(context.Background(), forEachWorkflow(forEach))
+	return run.Context(), err
+}
+
+func TestEngine_Execute_ForEachParallelismLimit(t *testing.T) {
+	tracker := &concurrencyExecutor{delay: 20 * time.Millisecond}
+	registry := NewRegistry()
+	registry.Register("track", tracker)
+
+	run := NewEngine(registry).NewRun(nil)
+	run.Context().Set("names", []string{"a", "b", "c", "d"})
+	err := run.Execute(context.Background(), WorkflowDefinition{
+		Name: "foreach",
+		Tasks: []Task{{
+			ID:      "each",
+			Type:    "track",
+			Config:  map[string]interface{}{"name": "item"},
+			ForEach: &ForEach{Items: "names", Parallelism: 2},
+		}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&tracker.peak))
+
+	result, _ := run.Context().Get("each_result")
+	assert.Len(t, result, 4)
+}
+
+func TestEngine_Execute_ForEachExposesItemAndIndex(t *testing.T) {
+	execCtx, err := runForEach(t, &itemExecutor{}, []interface{}{"x", "y", "z"}, &ForEach{Items: "listing.links"})
+
+	require.NoError(t, err)
+	result, _ := execCtx.Get("each_result")
+	assert.Equal(t, []interface{}{"0:x", "1:y", "2:z"}, result)
+
+	_, exists := execCtx.Get("item")
+	assert.False(t, exists, "item must not leak into the workflow context")
+	_, exists = execCtx.Get("scratch")
+	assert.False(t, exists, "values set by an item must not leak into the workflow context")
+}
+
+func TestEngine_Execute_ForEachEmptyList(t *testing.T) {
+	executor := &itemExecutor{}
+	execCtx, err := runForEach(t, executor, []interface{}{}, &ForEach{Items: "listing.links"})
+
+	require.NoError(t, err)
+	result, exists := execCtx.Get("each_result")
+	assert.True(t, exists)
+	assert.Equal(t, []interface{}{}, result)
+	assert.Zero(t, atomic.LoadInt32(&executor.calls))
+}
+
+func TestEngine_Execute_ForEachInvalidItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		items   interface{}
+		path    string
+		message string
+	}{
+		{name: "missing", items: nil, path: "listing.missing", message: "foreach items 'listing.missing' not found in context"},
+		{name: "not an array", items: "text", path: "listing.links", message: "foreach items 'listing.links' is not an array"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runForEach(t, &itemExecutor{}, tt.items, &ForEach{Items: tt.path})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestEngine_Execute_ForEachReportsAllFailures(t *testing.T) {
+	executor := &itemExecutor{}
+	execCtx, err := runForEach(t, executor, []interface{}{"bad", "ok", "bad"}, &ForEach{Items: "listing.links", Parallelism: 1})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "task each failed: 2 of 3 items failed: item 0: bad item at 0; item 2: bad item at 2")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&executor.calls))
+	_, exists := execCtx.Get("each_result")
+	assert.False(t, exists)
+}
+
+func TestEngine_Execute_ForEachFailFast(t *testing.T) {
+	executor := &itemExecutor{delay: 10 * time.Millisecond}
+	_, err := runForEach(t, executor, []interface{}{"ok", "bad", "ok", "ok", "ok"},
+		&ForEach{Items: "listing.links", Parallelism: 1, FailFast: true})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "task each failed: item 1: bad item at 1")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&executor.calls))
+}
+
+func TestEngine_Execute_ForEachRetriesItems(t *testing.T) {
+	flaky := &flakyExecutor{Failures: 1, ErrorMsg: "temporary"}
+	registry := NewRegistry()
+	registry.Register("flaky", flaky)
+
+	run := NewEngine(registry).NewRun(nil)
+	run.Context().Set("ids", []int{1, 2})
+	err := run.Execute(context.Background(), WorkflowDefinition{
+		Name: "foreach-retry",
+		Tasks: []Task{{
+			ID:      "each",
+			Type:    "flaky",
+			Config:  map[string]interface{}{},
+			Retry:   &RetryPolicy{MaxAttempts: 2, DelayMs: 1},
+			ForEach: &ForEach{Items: "ids", Parallelism: 1},
+		}},
+	})
+
+	require.NoError(t, err)
+	// Only the first item's first attempt fails
+	assert.Equal(t, int32(3), atomic.LoadInt32(&flaky.calls))
+	result, _ := run.Context().Get("each_result")
+	assert.Equal(t, []interface{}{"recovered", "recovered"}, result)
+}
+
+func TestEngine_ExecuteWithLogging_ForEachLogsOneTaskLog(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("item", &itemExecutor{})
+	registry.Register("mock", &MockExecutor{Output: []interface{}{"a", "b"}})
+	logger := newMemoryLogger()
+
+	record, err := NewEngine(registry).ExecuteWithLogging(context.Background(), WorkflowDefinition{
+		Name: "foreach-logging",
+		Tasks: []Task{
+			{ID: "list", Type: "mock", Config: map[string]interface{}{}},
+			{ID: "each", Type: "item", Config: map[string]interface{}{}, ForEach: &ForEach{Items: "list_result"}},
+		},
+	}, uuid.New(), logger, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "completed", record.Status)
+
+	logs := logger.taskLogsFor(record.ID)
+	require.Len(t, logs, 2)
+	for _, tl := range logs {
+		assert.Equal(t, "success", tl.Status)
+		if tl.TaskID == "each" {
+			assert.JSONEq(t, `["0:a", "1:b"]`, string(tl.Output))
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// inputTypes lists the supported InputParameter types
+var inputTypes = map[string]bool{
+	"string":  true,
+	"number":  true,
+	"integer": true,
+	"boolean": true,
+	"object":  true,
+	"array":   true,
+}
+
+// ResolveInputs validates values against the workflow's declared inputs and
+// returns them with defaults applied for inputs that were not provided.
+// Values are typically decoded from JSON; any Go numeric type counts as a
+// number, string-keyed maps as objects and slices as arrays. Undeclared values are
+// rejected, except for workflows that declare no inputs at all, which
+// receive values unchanged. All problems are reported in a single error.
+func (w WorkflowDefinition) ResolveInputs(values map[string]interface{}) (map[string]interface{}, error) {
+	resolved := make(map[string]interface{}, len(values))
+	if len(w.Inputs) == 0 {
+		for name, value := range values {
+			resolved[name] = value
+		}
+		return resolved, nil
+	}
+
+	var problems []string
+	declared := make(map[string]bool, len(w.Inputs))
+	for _, param := range w.Inputs {
+		declared[param.Name] = true
+		if !inputTypes[param.Type] {
+			problems = append(problems, fmt.Sprintf("input '%s' has unsupported type '%s'", param.Name, param.Type))
+			continue
+		}
+
+		value, provided := values[param.Name]
+		if !provided || value == nil {
+			switch {
+			case param.Default != nil:
+				resolved[param.Name] = param.Default
+			case param.Required:
+				problems = append(problems, fmt.Sprintf("input '%s' is required", param.Name))
+			}
+			continue
+		}
+
+		if !hasInputType(value, param.Type) {
+			problems = append(problems, fmt.Sprintf("input '%s' must be of type %s (got %T)", param.Name, param.Type, value))
+			continue
+		}
+		resolved[param.Name] = value
+	}
+
+	var unknown []string
+	for name := range values {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		problems = append(problems, fmt.Sprintf("unknown input '%s'", name))
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid inputs: %s", strings.Join(problems, "; "))
+	}
+	return resolved, nil
+}
+
+// hasInputType reports whether a JSON-decoded value matches an input type.
+func hasInputType(value interface{}, inputType string) bool {
+	switch inputType {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "integer":
+		f, ok := toFloat(value)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		v := reflect.ValueOf(value)
+		return v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String
+	case "array":
+		kind := reflect.ValueOf(value).Kind()
+		return kind == reflect.Slice || kind == reflect.Array
+	}
+	return false
+}
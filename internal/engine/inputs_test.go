@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func inputsDefinition() WorkflowDefinition {
+	return WorkflowDefinition{
+		Name: "with-inputs",
+		Inputs: []InputParameter{
+			{Name: "url", Type: "string", Required: true},
+			{Name: "limit", Type: "integer", Default: float64(10)},
+			{Name: "ratio", Type: "number"},
+			{Name: "verbose", Type: "boolean"},
+			{Name: "headers", Type: "object"},
+			{Name: "ids", Type: "array"},
+		},
+	}
+}
+
+func TestResolveInputs(t *testing.T) {
+	resolved, err := inputsDefinition().ResolveInputs(map[string]interface{}{
+		"url":     "http://example.com",
+		"ratio":   0.5,
+		"verbose": true,
+		"headers": map[string]interface{}{"Accept": "text/html"},
+		"ids":     []interface{}{float64(1), float64(2)},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"url":     "http://example.com",
+		"limit":   float64(10),
+		"ratio":   0.5,
+		"verbose": true,
+		"headers": map[string]interface{}{"Accept": "text/html"},
+		"ids":     []interface{}{float64(1), float64(2)},
+	}, resolved)
+}
+
+func TestResolveInputs_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]interface{}
+		wantErr string
+	}{
+		{"missing required", map[string]interface{}{}, "input 'url' is required"},
+		{"null required", map[string]interface{}{"url": nil}, "input 'url' is required"},
+		{"wrong type", map[string]interface{}{"url": 42}, "input 'url' must be of type string (got int)"},
+		{"fractional integer", map[string]interface{}{"url": "x", "limit": 1.5}, "input 'limit' must be of type integer"},
+		{"unknown", map[string]interface{}{"url": "x", "b": 1, "a": 2}, "unknown input 'a'; unknown input 'b'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := inputsDefinition().ResolveInputs(tt.values)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestResolveInputs_UnsupportedType(t *testing.T) {
+	workflow := WorkflowDefinition{Inputs: []InputParameter{{Name: "when", Type: "date"}}}
+
+	_, err := workflow.ResolveInputs(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "input 'when' has unsupported type 'date'")
+}
+
+func TestResolveInputs_NoDeclaredInputs(t *testing.T) {
+	resolved, err := WorkflowDefinition{}.ResolveInputs(map[string]interface{}{"anything": "goes"})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"anything": "goes"}, resolved)
+}
@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// loggingMiddleware records a run through an ExecutionLogger and publishes
+// its progress to an EventBus. As a RunHook it creates the Execution record
+// and stores the final status and context snapshot; its task middleware
+// records every attempt as a TaskLog.
+type loggingMiddleware struct {
+	logger ExecutionLogger
+	events *EventBus
+
+	// existing is set when the Execution record was created beforehand
+	// (e.g. queued as pending) and only needs updating
+	existing    bool
+	resumedFrom *uuid.UUID
+
+	// execution is the record of the run, set once it has been created
+	execution *ExecutionRecord
+}
+
+// BeforeRun creates or updates the Execution record with status "running".
+func (l *loggingMiddleware) BeforeRun(ctx context.Context, run *RunInfo) (context.Context, error) {
+	execution := &ExecutionRecord{
+		ID:                *run.ExecutionID,
+		WorkflowID:        run.WorkflowID,
+		ParentExecutionID: parentExecutionID(ctx),
+		ResumedFromID:     l.resumedFrom,
+		Status:            "running",
+		StartedAt:         run.StartedAt,
+	}
+	if l.existing {
+		if err := l.logger.UpdateExecution(execution); err != nil {
+			// If update fails, try to create (execution might not exist yet)
+			if err := l.logger.CreateExecution(execution); err != nil {
+				return ctx, fmt.Errorf("failed to create/update execution record: %w", err)
+			}
+		}
+	} else if err := l.logger.CreateExecution(execution); err != nil {
+		return ctx, fmt.Errorf("failed to create execution record: %w", err)
+	}
+	l.execution = execution
+
+	l.events.Publish(Event{
+		Type:        EventExecutionStarted,
+		ExecutionID: execution.ID,
+		Status:      execution.Status,
+		Timestamp:   execution.StartedAt,
+	})
+	return ctx, nil
+}
+
+// AfterRun records the run's final status and context snapshot.
+func (l *loggingMiddleware) AfterRun(ctx context.Context, run *RunInfo) error {
+	execution := l.execution
+	if snapshotJSON, err := json.Marshal(run.Context.GetAll()); err == nil {
+		execution.ContextSnapshot = datatypes.JSON(snapshotJSON)
+	}
+	completedAt := run.CompletedAt
+	execution.CompletedAt = &completedAt
+	execution.Status = run.Status
+
+	updateErr := l.logger.UpdateExecution(execution)
+	finished := Event{
+		Type:        EventExecutionFinished,
+		ExecutionID: execution.ID,
+		Status:      execution.Status,
+		DurationMs:  completedAt.Sub(execution.StartedAt).Milliseconds(),
+		Timestamp:   completedAt,
+	}
+	if run.Err != nil {
+		finished.Error = run.Err.Error()
+	}
+	l.events.Publish(finished)
+
+	if updateErr != nil {
+		slog.Error("Failed to update execution", "error", updateErr, "execution_id", execution.ID)
+		return fmt.Errorf("failed to update execution: %w", updateErr)
+	}
+	return nil
+}
+
+// wrapTask is the task middleware: it records each attempt as a TaskLog,
+// created as "running" before the attempt and updated with its outcome.
+// Skipped tasks get a single "skipped" TaskLog.
+func (l *loggingMiddleware) wrapTask(next TaskHandler) TaskHandler {
+	return func(ctx context.Context, call TaskCall) TaskResult {
+		taskLog := &TaskLogRecord{
+			ID:          uuid.New(),
+			ExecutionID: *call.ExecutionID,
+			TaskID:      call.Task.ID,
+			TaskType:    call.Task.Type,
+			Status:      "running",
+			Attempt:     call.Attempt,
+			StartedAt:   time.Now().UTC(),
+		}
+
+		// Serialize task config as input
+		if configJSON, err := json.Marshal(call.Task.Config); err == nil {
+			taskLog.Input = datatypes.JSON(configJSON)
+		}
+
+		if call.Skip {
+			result := next(ctx, call)
+			taskLog.Status = "skipped"
+			taskLog.CompletedAt = taskLog.StartedAt
+			if err := l.logger.CreateTaskLog(taskLog); err != nil {
+				slog.Error("Failed to create task log", "error", err, "task_id", call.Task.ID)
+			}
+			l.publishTaskEvent(EventTaskSkipped, taskLog)
+			return result
+		}
+
+		// Log task start; execution continues even if logging fails
+		if err := l.logger.CreateTaskLog(taskLog); err != nil {
+			slog.Error("Failed to create task log", "error", err, "task_id", call.Task.ID)
+		}
+		l.publishTaskEvent(EventTaskStarted, taskLog)
+
+		result := next(ctx, call)
+
+		// Update TaskLog with result
+		taskLog.CompletedAt = time.Now().UTC()
+		if result.Status == "success" {
+			taskLog.Status = "success"
+		} else {
+			taskLog.Status = "failed"
+			if errors.Is(context.Cause(ctx), ErrInterrupted) {
+				taskLog.Status = "interrupted"
+			} else if errors.Is(ctx.Err(), context.Canceled) {
+				taskLog.Status = "cancelled"
+			}
+			taskLog.Error = result.Error
+		}
+
+		// Serialize output
+		if result.Output != nil {
+			if outputJSON, err := json.Marshal(result.Output); err == nil {
+				taskLog.Output = datatypes.JSON(outputJSON)
+			}
+		}
+
+		if err := l.logger.UpdateTaskLog(taskLog); err != nil {
+			slog.Error("Failed to update task log", "error", err, "task_id", call.Task.ID)
+		}
+
+		if taskLog.Status == "success" {
+			l.publishTaskEvent(EventTaskCompleted, taskLog)
+		} else {
+			l.publishTaskEvent(EventTaskFailed, taskLog)
+		}
+		return result
+	}
+}
+
+// publishTaskEvent publishes an event of the given type for taskLog. Events
+// for finished attempts carry the attempt's duration.
+func (l *loggingMiddleware) publishTaskEvent(eventType string, taskLog *TaskLogRecord) {
+	event := Event{
+		Type:        eventType,
+		ExecutionID: taskLog.ExecutionID,
+		TaskID:      taskLog.TaskID,
+		TaskType:    taskLog.TaskType,
+		Attempt:     taskLog.Attempt,
+		Status:      taskLog.Status,
+		Error:       taskLog.Error,
+		Timestamp:   taskLog.StartedAt,
+	}
+	if !taskLog.CompletedAt.IsZero() {
+		event.DurationMs = taskLog.CompletedAt.Sub(taskLog.StartedAt).Milliseconds()
+		event.Timestamp = taskLog.CompletedAt
+	}
+	l.events.Publish(event)
+}
@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskCall describes one attempt of a task to middleware.
+type TaskCall struct {
+	ExecutionID *uuid.UUID // nil when the run is not logged
+	Index       int        // position of the task in its task list
+	Task        Task
+	Attempt     int
+	// Skip is set when the task's when condition is false: the attempt
+	// returns a "skipped" result without executing the task
+	Skip bool
+	// Context is the run's ExecutionContext
+	Context *ExecutionContext
+}
+
+// TaskHandler performs a task attempt and returns its result.
+type TaskHandler func(ctx context.Context, call TaskCall) TaskResult
+
+// Middleware wraps every task attempt of a run: it receives the next handler
+// in the chain and returns a handler that typically does some work, calls
+// next, and inspects or adjusts the result. The innermost handler calls
+// TaskExecutor.Execute; for foreach tasks one attempt covers all items.
+// Attempts that never reach an executor go through the chain too: skipped
+// tasks (see TaskCall.Skip), invalid when conditions and unknown task types,
+// the latter two as failed results.
+type Middleware func(next TaskHandler) TaskHandler
+
+// RunInfo describes a workflow run to RunHooks. Status, Err and CompletedAt
+// are set before AfterRun is called.
+type RunInfo struct {
+	Workflow    WorkflowDefinition
+	WorkflowID  uuid.UUID  // zero when the run is not logged
+	ExecutionID *uuid.UUID // nil when the run is not logged
+	Context     *ExecutionContext
+	StartedAt   time.Time
+
+	// Status is the execution status (see Run.executionStatus)
+	Status      string
+	Err         error
+	CompletedAt time.Time
+}
+
+// RunHook is notified before and after each workflow run, including
+// sub-workflow runs.
+type RunHook interface {
+	// BeforeRun is called before any task runs. It returns the context the
+	// run continues with, typically ctx itself or a context derived from it
+	// (e.g. carrying a tracing span); the tasks and the following hooks get
+	// that context. An error aborts the run: the hooks that were already
+	// called get AfterRun with status "failed", and the error is returned to
+	// the caller.
+	BeforeRun(ctx context.Context, run *RunInfo) (context.Context, error)
+	// AfterRun is called once the run has finished, in the reverse order of
+	// BeforeRun, with the context returned by the last BeforeRun called. The
+	// first error returned replaces the run's error.
+	AfterRun(ctx context.Context, run *RunInfo) error
+}
+
+// Use adds middleware wrapping the task attempts of runs created afterwards.
+// The first middleware added is the outermost. Runs with logging always wrap
+// the chain in their own logging middleware, so the attempts they record
+// reflect what the engine's middleware returned.
+func (e *Engine) Use(middleware ...Middleware) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.middleware = append(e.middleware, middleware...)
+}
+
+// AddHook registers a RunHook for runs created afterwards. Hooks are called
+// in the order they were added.
+func (e *Engine) AddHook(hook RunHook) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.hooks = append(e.hooks, hook)
+}
+
+// wrapTask wraps final in middleware, the first one being the outermost.
+func wrapTask(middleware []Middleware, final TaskHandler) TaskHandler {
+	handler := final
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
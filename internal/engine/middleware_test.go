@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callRecorder collects what middleware and hooks observe, in order.
+type callRecorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (c *callRecorder) record(format string, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, fmt.Sprintf(format, args...))
+}
+
+func (c *callRecorder) middleware(name string) Middleware {
+	return func(next TaskHandler) TaskHandler {
+		return func(ctx context.Context, call TaskCall) TaskResult {
+			c.record("%s before %s#%d", name, call.Task.ID, call.Attempt)
+			result := next(ctx, call)
+			c.record("%s after %s#%d %s", name, call.Task.ID, call.Attempt, result.Status)
+			return result
+		}
+	}
+}
+
+// recordingHook records its calls; BeforeRun fails with err if set.
+type recordingHook struct {
+	name     string
+	recorder *callRecorder
+	err      error
+}
+
+func (h *recordingHook) BeforeRun(ctx context.Context, run *RunInfo) (context.Context, error) {
+	h.recorder.record("%s BeforeRun %s", h.name, run.Workflow.Name)
+	return ctx, h.err
+}
+
+func (h *recordingHook) AfterRun(ctx context.Context, run *RunInfo) error {
+	h.recorder.record("%s AfterRun %s", h.name, run.Status)
+	return nil
+}
+
+func TestEngine_MiddlewareWrapsEveryAttempt(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("flaky", &flakyExecutor{Failures: 1, ErrorMsg: "temporary"})
+	registry.Register("mock", &MockExecutor{})
+	engine := NewEngine(registry)
+	recorder := &callRecorder{}
+	engine.Use(recorder.middleware("outer"), recorder.middleware("inner"))
+
+	_, err := engine.Execute(context.Background(), WorkflowDefinition{
+		Name: "middleware",
+		Tasks: []Task{
+			{ID: "flaky", Type: "flaky", Retry: &RetryPolicy{MaxAttempts: 2, DelayMs: 1}},
+			{ID: "skipped", Type: "mock", When: "flaky_result == 'never'"},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"outer before flaky#1",
+		"inner before flaky#1",
+		"inner after flaky#1 failed",
+		"outer after flaky#1 failed",
+		"outer before flaky#2",
+		"inner before flaky#2",
+		"inner after flaky#2 success",
+		"outer after flaky#2 success",
+		"outer before skipped#1",
+		"inner before skipped#1",
+		"inner after skipped#1 skipped",
+		"outer after skipped#1 skipped",
+	}, recorder.calls)
+}
+
+func TestEngine_MiddlewareResultIsLogged(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("mock", &MockExecutor{Output: map[string]interface{}{"token": "s3cret"}})
+	engine := NewEngine(registry)
+	engine.Use(func(next TaskHandler) TaskHandler {
+		return func(ctx context.Context, call TaskCall) TaskResult {
+			result := next(ctx, call)
+			result.Output = map[string]interface{}{"token": "[redacted]"}
+			return result
+		}
+	})
+
+	logger := newMemoryLogger()
+	execution, err := engine.ExecuteWithLogging(context.Background(), WorkflowDefinition{
+		Name:  "redacted",
+		Tasks: []Task{{ID: "login", Type: "mock"}},
+	}, uuid.New(), logger, nil)
+	require.NoError(t, err)
+
+	logs := logger.taskLogsFor(execution.ID)
+	require.Len(t, logs, 1)
+	assert.JSONEq(t, `{"token": "[redacted]"}`, string(logs[0].Output))
+}
+
+func TestEngine_HooksRunAroundWorkflow(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("mock", &MockExecutor{})
+	engine := NewEngine(registry)
+	recorder := &callRecorder{}
+	engine.AddHook(&recordingHook{name: "first", recorder: recorder})
+	engine.AddHook(&recordingHook{name: "second", recorder: recorder})
+	engine.Use(recorder.middleware("mw"))
+
+	_, err := engine.Execute(context.Background(), WorkflowDefinition{
+		Name:  "hooked",
+		Tasks: []Task{{ID: "task1", Type: "mock"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"first BeforeRun hooked",
+		"second BeforeRun hooked",
+		"mw before task1#1",
+		"mw after task1#1 success",
+		"second AfterRun completed",
+		"first AfterRun completed",
+	}, recorder.calls)
+}
+
+func TestEngine_FailingBeforeRunAbortsRun(t *testing.T) {
+	mock := &flakyExecutor{}
+	registry := NewRegistry()
+	registry.Register("mock", mock)
+	engine := NewEngine(registry)
+	recorder := &callRecorder{}
+	engine.AddHook(&recordingHook{name: "first", recorder: recorder})
+	engine.AddHook(&recordingHook{name: "quota", recorder: recorder, err: errors.New("quota exceeded")})
+
+	logger := newMemoryLogger()
+	execution, err := engine.ExecuteWithLogging(context.Background(), WorkflowDefinition{
+		Name:  "aborted",
+		Tasks: []Task{{ID: "task1", Type: "mock"}},
+	}, uuid.New(), logger, nil)
+	assert.EqualError(t, err, "quota exceeded")
+	assert.Equal(t, int32(0), mock.calls)
+
+	// Hooks that already ran, including logging, see the run fail
+	assert.Equal(t, []string{
+		"first BeforeRun aborted",
+		"quota BeforeRun aborted",
+		"first AfterRun failed",
+	}, recorder.calls)
+	require.NotNil(t, execution)
+	assert.Equal(t, "failed", logger.executions[execution.ID].Status)
+}
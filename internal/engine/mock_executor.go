@@ -1,5 +1,7 @@
 package engine
 
+import "context"
+
 // MockExecutor is a test implementation of TaskExecutor.
 // It can be configured to return success or failure for testing purposes.
 // This executor is intended for testing only and should not be used in production.
@@ -15,7 +17,7 @@ type MockExecutor struct {
 // Execute implements the TaskExecutor interface for testing.
 // Returns a successful TaskResult with the configured Output, or a failed
 // TaskResult with the configured ErrorMsg if ShouldFail is true.
-func (m *MockExecutor) Execute(ctx *ExecutionContext, config map[string]interface{}) TaskResult {
+func (m *MockExecutor) Execute(ctx context.Context, execCtx *ExecutionContext, config map[string]interface{}) TaskResult {
 	if m.ShouldFail {
 		return TaskResult{
 			Status: "failed",
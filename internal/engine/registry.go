@@ -2,6 +2,7 @@ package engine
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 )
 
@@ -55,3 +56,39 @@ func (r *Registry) List() []string {
 	}
 	return types
 }
+
+// TaskTypeInfo describes a registered task type. Schema is nil when the
+// executor does not implement SchemaProvider.
+type TaskTypeInfo struct {
+	Type   string      `json:"type"`
+	Schema *TaskSchema `json:"schema"`
+}
+
+// Describe returns information about a registered task type.
+// Returns an error if the task type is not registered.
+func (r *Registry) Describe(taskType string) (TaskTypeInfo, error) {
+	executor, err := r.Get(taskType)
+	if err != nil {
+		return TaskTypeInfo{}, err
+	}
+	info := TaskTypeInfo{Type: taskType}
+	if provider, ok := executor.(SchemaProvider); ok {
+		schema := provider.Schema()
+		info.Schema = &schema
+	}
+	return info, nil
+}
+
+// DescribeAll returns information about every registered task type, sorted by type.
+func (r *Registry) DescribeAll() []TaskTypeInfo {
+	types := r.List()
+	sort.Strings(types)
+
+	infos := make([]TaskTypeInfo, 0, len(types))
+	for _, taskType := range types {
+		if info, err := r.Describe(taskType); err == nil {
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
@@ -174,3 +174,40 @@ func TestRegistry_ConcurrentRegisterAndGet(t *testing.T) {
 	types := registry.List()
 	assert.GreaterOrEqual(t, len(types), 11)
 }
+
+// describedExecutor is a MockExecutor that provides a schema.
+type describedExecutor struct {
+	MockExecutor
+}
+
+func (d *describedExecutor) Schema() TaskSchema {
+	return TaskSchema{
+		Description: "described",
+		Config:      map[string]interface{}{"type": "object", "required": []string{"url"}},
+	}
+}
+
+func TestRegistry_Describe(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("plain", &MockExecutor{})
+	registry.Register("described", &describedExecutor{})
+
+	info, err := registry.Describe("described")
+	assert.NoError(t, err)
+	assert.Equal(t, "described", info.Type)
+	if assert.NotNil(t, info.Schema) {
+		assert.Equal(t, "described", info.Schema.Description)
+	}
+
+	info, err = registry.Describe("plain")
+	assert.NoError(t, err)
+	assert.Nil(t, info.Schema)
+
+	_, err = registry.Describe("missing")
+	assert.Error(t, err)
+
+	all := registry.DescribeAll()
+	assert.Len(t, all, 2)
+	assert.Equal(t, "described", all[0].Type)
+	assert.Equal(t, "plain", all[1].Type)
+}
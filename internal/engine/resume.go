@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ResumeState describes a previous execution that a new run continues from.
+type ResumeState struct {
+	// ExecutionID is the execution being resumed
+	ExecutionID uuid.UUID
+	// Context is the previous execution's context snapshot
+	Context map[string]interface{}
+	// CompletedTasks lists the IDs of tasks that succeeded and are not run again
+	CompletedTasks []string
+}
+
+// ResumeWithLogging executes workflow as a new logged execution that picks
+// up where a previous one stopped. The run's context is restored from
+// state.Context and the tasks in state.CompletedTasks are treated as already
+// done, so execution restarts at the first task that did not succeed. The
+// new execution records state.ExecutionID as ResumedFromID. Tracking and
+// cancellation work as in ExecuteWithLogging.
+func (e *Engine) ResumeWithLogging(
+	ctx context.Context,
+	workflow WorkflowDefinition,
+	workflowID uuid.UUID,
+	logger ExecutionLogger,
+	executionID *uuid.UUID,
+	state ResumeState,
+) (*ExecutionRecord, error) {
+	run := e.NewRun(logger)
+	run.resume(state)
+	return e.executeWithLogging(ctx, run, workflow, workflowID, executionID)
+}
+
+// resume prepares the run to continue state. Leftovers of the previous
+// failure (the "failure" key and <id>_error of tasks that will run again)
+// are not restored.
+func (r *Run) resume(state ResumeState) {
+	r.completed = make(map[string]bool, len(state.CompletedTasks))
+	for _, id := range state.CompletedTasks {
+		r.completed[id] = true
+	}
+	resumedFrom := state.ExecutionID
+	r.resumedFrom = &resumedFrom
+
+	for key, value := range state.Context {
+		if key == "failure" {
+			continue
+		}
+		if id, isError := strings.CutSuffix(key, "_error"); isError && !r.completed[id] {
+			continue
+		}
+		r.context.Set(key, value)
+	}
+
+	slog.Info("Resuming execution",
+		"resumed_from", state.ExecutionID,
+		"completed_tasks", len(state.CompletedTasks),
+	)
+}
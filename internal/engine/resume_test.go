@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingExecutor counts its executions and succeeds.
+type countingExecutor struct {
+	calls atomic.Int32
+}
+
+func (c *countingExecutor) Execute(ctx context.Context, execCtx *ExecutionContext, config map[string]interface{}) TaskResult {
+	c.calls.Add(1)
+	return TaskResult{Status: "success", Output: config["value"]}
+}
+
+func TestEngine_ResumeWithLogging(t *testing.T) {
+	done := &countingExecutor{}
+	retried := &countingExecutor{}
+	registry := NewRegistry()
+	registry.Register("done", done)
+	registry.Register("retried", retried)
+	logger := newMemoryLogger()
+	previous := uuid.New()
+
+	record, err := NewEngine(registry).ResumeWithLogging(context.Background(), WorkflowDefinition{
+		Name: "resumable",
+		Tasks: []Task{
+			{ID: "first", Type: "done", Config: map[string]interface{}{"value": "again"}},
+			{ID: "optional", Type: "retried", Config: map[string]interface{}{"value": "fixed"}, ContinueOnError: true},
+			{ID: "second", Type: "retried", Config: map[string]interface{}{"value": "second"}},
+		},
+	}, uuid.New(), logger, nil, ResumeState{
+		ExecutionID: previous,
+		Context: map[string]interface{}{
+			"first_result":   "before",
+			"optional_error": "task optional failed: boom",
+			"failure":        map[string]interface{}{"task_id": "second"},
+		},
+		CompletedTasks: []string{"first"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "completed", record.Status)
+	require.NotNil(t, record.ResumedFromID)
+	assert.Equal(t, previous, *record.ResumedFromID)
+	assert.Equal(t, int32(0), done.calls.Load())
+	assert.Equal(t, int32(2), retried.calls.Load())
+	assert.JSONEq(t, `{"first_result": "before", "optional_result": "fixed", "second_result": "second"}`,
+		string(record.ContextSnapshot))
+
+	var ran []string
+	for _, tl := range logger.taskLogsFor(record.ID) {
+		ran = append(ran, tl.TaskID)
+	}
+	assert.ElementsMatch(t, []string{"optional", "second"}, ran)
+}
@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Backoff strategies supported by RetryPolicy.
+const (
+	BackoffFixed       = "fixed"
+	BackoffExponential = "exponential"
+)
+
+// RetryPolicy configures how a failed task is retried (FR8).
+// A nil policy means the task is attempted exactly once.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one
+	MaxAttempts int `json:"max_attempts"`
+	// Backoff is "fixed" (default) or "exponential"
+	Backoff string `json:"backoff,omitempty"`
+	// DelayMs is the delay before the first retry in milliseconds
+	DelayMs int `json:"delay_ms,omitempty"`
+	// MaxDelayMs caps the computed delay, jitter included (0 means no cap)
+	MaxDelayMs int `json:"max_delay_ms,omitempty"`
+	// Multiplier is the exponential growth factor (default: 2)
+	Multiplier float64 `json:"multiplier,omitempty"`
+	// Jitter adds a random extra delay of up to Jitter*delay (0.0 - 1.0)
+	Jitter float64 `json:"jitter,omitempty"`
+	// RetryOn restricts which failures are retried; empty retries every failure
+	RetryOn *RetryOn `json:"retry_on,omitempty"`
+}
+
+// RetryOn lists the failure matchers of a RetryPolicy. A failure is retried
+// if it matches any of them.
+type RetryOn struct {
+	// Errors matches substrings of TaskResult.Error
+	Errors []string `json:"errors,omitempty"`
+	// StatusCodes matches the "status_code" reported in the task output,
+	// e.g. by http_request for 4xx/5xx responses
+	StatusCodes []int `json:"status_codes,omitempty"`
+}
+
+// maxAttempts returns the total number of attempts allowed by the policy.
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// shouldRetry reports whether a failed attempt should be followed by another one.
+func (p *RetryPolicy) shouldRetry(attempt int, result TaskResult) bool {
+	if attempt >= p.maxAttempts() {
+		return false
+	}
+	return p.RetryOn.matches(result)
+}
+
+// validate checks the policy's settings.
+func (p *RetryPolicy) validate() error {
+	switch {
+	case p.MaxAttempts < 0:
+		return fmt.Errorf("max_attempts must not be negative")
+	case p.Backoff != "" && p.Backoff != BackoffFixed && p.Backoff != BackoffExponential:
+		return fmt.Errorf("unknown backoff '%s' (expected '%s' or '%s')", p.Backoff, BackoffFixed, BackoffExponential)
+	case p.DelayMs < 0 || p.MaxDelayMs < 0:
+		return fmt.Errorf("delays must not be negative")
+	case p.Multiplier < 0:
+		return fmt.Errorf("multiplier must not be negative")
+	case p.Jitter < 0 || p.Jitter > 1:
+		return fmt.Errorf("jitter must be between 0 and 1")
+	}
+	return nil
+}
+
+// delay returns how long to wait after the given failed attempt. Jitter is
+// added before MaxDelayMs caps the delay, and an uncapped exponential delay
+// stops growing at the longest time.Duration.
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	base := float64(p.DelayMs)
+	if p.Backoff == BackoffExponential && base > 0 {
+		multiplier := p.Multiplier
+		if multiplier <= 0 {
+			multiplier = 2
+		}
+		base *= math.Pow(multiplier, float64(attempt-1))
+	}
+	if p.Jitter > 0 {
+		base += base * math.Min(p.Jitter, 1) * rand.Float64()
+	}
+	if p.MaxDelayMs > 0 && base > float64(p.MaxDelayMs) {
+		base = float64(p.MaxDelayMs)
+	}
+	delay := base * float64(time.Millisecond)
+	if delay >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(delay)
+}
+
+// matches reports whether a failed result matches any of the matchers.
+// A nil or empty RetryOn matches every failure.
+func (r *RetryOn) matches(result TaskResult) bool {
+	if r == nil || (len(r.Errors) == 0 && len(r.StatusCodes) == 0) {
+		return true
+	}
+
+	for _, substr := range r.Errors {
+		if strings.Contains(result.Error, substr) {
+			return true
+		}
+	}
+
+	if statusCode, ok := resultStatusCode(result); ok {
+		for _, code := range r.StatusCodes {
+			if code == statusCode {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// resultStatusCode extracts "status_code" from a map-shaped task output.
+func resultStatusCode(result TaskResult) (int, bool) {
+	output, ok := result.Output.(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	switch code := output["status_code"].(type) {
+	case int:
+		return code, true
+	case float64:
+		return int(code), true
+	}
+	return 0, false
+}
@@ -0,0 +1,203 @@
+package engine
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyExecutor fails the first Failures calls and succeeds afterwards.
+type flakyExecutor struct {
+	Failures int32
+	ErrorMsg string
+	Output   interface{}
+	calls    int32
+}
+
+func (f *flakyExecutor) Execute(ctx context.Context, execCtx *ExecutionContext, config map[string]interface{}) TaskResult {
+	if atomic.AddInt32(&f.calls, 1) <= f.Failures {
+		return TaskResult{Status: "failed", Output: f.Output, Error: f.ErrorMsg}
+	}
+	return TaskResult{Status: "success", Output: "recovered"}
+}
+
+func TestRetryPolicy_MaxAttempts(t *testing.T) {
+	var nilPolicy *RetryPolicy
+	assert.Equal(t, 1, nilPolicy.maxAttempts())
+	assert.Equal(t, 1, (&RetryPolicy{}).maxAttempts())
+	assert.Equal(t, 3, (&RetryPolicy{MaxAttempts: 3}).maxAttempts())
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	fixed := &RetryPolicy{DelayMs: 100}
+	assert.Equal(t, 100*time.Millisecond, fixed.delay(1))
+	assert.Equal(t, 100*time.Millisecond, fixed.delay(3))
+
+	exponential := &RetryPolicy{Backoff: BackoffExponential, DelayMs: 100, MaxDelayMs: 500}
+	assert.Equal(t, 100*time.Millisecond, exponential.delay(1))
+	assert.Equal(t, 200*time.Millisecond, exponential.delay(2))
+	assert.Equal(t, 400*time.Millisecond, exponential.delay(3))
+	assert.Equal(t, 500*time.Millisecond, exponential.delay(4))
+
+	tripled := &RetryPolicy{Backoff: BackoffExponential, DelayMs: 10, Multiplier: 3}
+	assert.Equal(t, 90*time.Millisecond, tripled.delay(3))
+}
+
+func TestRetryPolicy_DelayWithJitter(t *testing.T) {
+	policy := &RetryPolicy{DelayMs: 100, Jitter: 0.5}
+
+	for i := 0; i < 20; i++ {
+		delay := policy.delay(1)
+		assert.GreaterOrEqual(t, delay, 100*time.Millisecond)
+		assert.LessOrEqual(t, delay, 150*time.Millisecond)
+	}
+}
+
+func TestRetryPolicy_DelayLimits(t *testing.T) {
+	// Jitter never takes the delay past the cap
+	capped := &RetryPolicy{Backoff: BackoffExponential, DelayMs: 100, MaxDelayMs: 500, Jitter: 1}
+	for i := 0; i < 20; i++ {
+		assert.LessOrEqual(t, capped.delay(4), 500*time.Millisecond)
+	}
+
+	// Uncapped backoff doesn't overflow
+	uncapped := &RetryPolicy{Backoff: BackoffExponential, DelayMs: 1000, Jitter: 0.5}
+	assert.Equal(t, time.Duration(math.MaxInt64), uncapped.delay(100))
+	assert.Equal(t, time.Duration(math.MaxInt64), uncapped.delay(5000))
+	assert.Zero(t, (&RetryPolicy{Backoff: BackoffExponential}).delay(5000))
+}
+
+func TestRetryOn_Matches(t *testing.T) {
+	httpFailure := TaskResult{
+		Status: "failed",
+		Output: map[string]interface{}{"status_code": 503},
+		Error:  "HTTP 503: Service Unavailable",
+	}
+	decodedFailure := TaskResult{
+		Status: "failed",
+		Output: map[string]interface{}{"status_code": float64(429)},
+		Error:  "HTTP 429",
+	}
+	timeout := TaskResult{Status: "failed", Error: "request execution failed: context deadline exceeded"}
+
+	var any *RetryOn
+	assert.True(t, any.matches(timeout))
+	assert.True(t, (&RetryOn{}).matches(timeout))
+
+	byCode := &RetryOn{StatusCodes: []int{429, 503}}
+	assert.True(t, byCode.matches(httpFailure))
+	assert.True(t, byCode.matches(decodedFailure))
+	assert.False(t, byCode.matches(timeout))
+
+	byError := &RetryOn{Errors: []string{"deadline exceeded"}}
+	assert.True(t, byError.matches(timeout))
+	assert.False(t, byError.matches(httpFailure))
+}
+
+func TestEngine_Execute_RetriesUntilSuccess(t *testing.T) {
+	flaky := &flakyExecutor{Failures: 2, ErrorMsg: "temporary"}
+	registry := NewRegistry()
+	registry.Register("flaky", flaky)
+
+	execCtx, err := NewEngine(registry).Execute(context.Background(), WorkflowDefinition{
+		Name: "retry",
+		Tasks: []Task{{
+			ID:     "task1",
+			Type:   "flaky",
+			Config: map[string]interface{}{},
+			Retry:  &RetryPolicy{MaxAttempts: 3, DelayMs: 1},
+		}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&flaky.calls))
+	result, _ := execCtx.Get("task1_result")
+	assert.Equal(t, "recovered", result)
+}
+
+func TestEngine_Execute_RetriesExhausted(t *testing.T) {
+	flaky := &flakyExecutor{Failures: 5, ErrorMsg: "still down"}
+	registry := NewRegistry()
+	registry.Register("flaky", flaky)
+
+	_, err := NewEngine(registry).Execute(context.Background(), WorkflowDefinition{
+		Name: "retry",
+		Tasks: []Task{{
+			ID:     "task1",
+			Type:   "flaky",
+			Config: map[string]interface{}{},
+			Retry:  &RetryPolicy{MaxAttempts: 3, Backoff: BackoffExponential, DelayMs: 1},
+		}},
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "task task1 failed after 3 attempts: still down")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&flaky.calls))
+}
+
+func TestEngine_Execute_RetryOnDoesNotMatch(t *testing.T) {
+	flaky := &flakyExecutor{
+		Failures: 1,
+		ErrorMsg: "HTTP 404: Not Found",
+		Output:   map[string]interface{}{"status_code": 404},
+	}
+	registry := NewRegistry()
+	registry.Register("flaky", flaky)
+
+	_, err := NewEngine(registry).Execute(context.Background(), WorkflowDefinition{
+		Name: "retry",
+		Tasks: []Task{{
+			ID:     "task1",
+			Type:   "flaky",
+			Config: map[string]interface{}{},
+			Retry: &RetryPolicy{
+				MaxAttempts: 3,
+				DelayMs:     1,
+				RetryOn:     &RetryOn{StatusCodes: []int{503}},
+			},
+		}},
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "task task1 failed: HTTP 404")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&flaky.calls))
+}
+
+func TestEngine_ExecuteWithLogging_LogsEveryAttempt(t *testing.T) {
+	flaky := &flakyExecutor{Failures: 2, ErrorMsg: "temporary"}
+	registry := NewRegistry()
+	registry.Register("flaky", flaky)
+	logger := newMemoryLogger()
+
+	record, err := NewEngine(registry).ExecuteWithLogging(context.Background(), WorkflowDefinition{
+		Name: "retry",
+		Tasks: []Task{{
+			ID:     "task1",
+			Type:   "flaky",
+			Config: map[string]interface{}{},
+			Retry:  &RetryPolicy{MaxAttempts: 3, DelayMs: 1},
+		}},
+	}, uuid.New(), logger, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "completed", record.Status)
+
+	logs := logger.taskLogsFor(record.ID)
+	require.Len(t, logs, 3)
+	sort.Slice(logs, func(i, j int) bool { return logs[i].Attempt < logs[j].Attempt })
+
+	assert.Equal(t, 1, logs[0].Attempt)
+	assert.Equal(t, "failed", logs[0].Status)
+	assert.Equal(t, "temporary", logs[0].Error)
+	assert.Equal(t, 2, logs[1].Attempt)
+	assert.Equal(t, "failed", logs[1].Status)
+	assert.Equal(t, 3, logs[2].Attempt)
+	assert.Equal(t, "success", logs[2].Status)
+}
@@ -0,0 +1,396 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Run is a single, isolated workflow execution. It carries its own
+// ExecutionContext together with the registry, logger, middleware and hooks
+// it executes with, so concurrent runs created from the same Engine never
+// share state. A Run is intended to execute one workflow; create a new one
+// per execution.
+type Run struct {
+	context    *ExecutionContext
+	registry   *Registry
+	logger     ExecutionLogger
+	events     *EventBus // receives progress of logged runs; may be nil
+	middleware []Middleware
+	hooks      []RunHook
+
+	// tolerated is set once a task with continue_on_error has failed
+	tolerated atomic.Bool
+
+	// completed holds tasks already finished by the execution this run
+	// resumes (see ResumeState); they are not run again
+	completed   map[string]bool
+	resumedFrom *uuid.UUID
+}
+
+// newRun creates a Run with a fresh ExecutionContext.
+func newRun(registry *Registry, logger ExecutionLogger) *Run {
+	return &Run{
+		context:  NewExecutionContext(),
+		registry: registry,
+		logger:   logger,
+	}
+}
+
+// child creates a Run for a sub-workflow, sharing the run's registry, event
+// bus, middleware and hooks.
+func (r *Run) child(logger ExecutionLogger) *Run {
+	child := newRun(r.registry, logger)
+	child.events = r.events
+	child.middleware = r.middleware
+	child.hooks = r.hooks
+	return child
+}
+
+// Context returns the run's ExecutionContext. It can be used to seed data
+// before execution or to inspect task results afterwards.
+func (r *Run) Context() *ExecutionContext {
+	return r.context
+}
+
+// Execute processes a workflow by scheduling its tasks according to their
+// dependencies (see buildTaskGraph). Each task is looked up in the registry,
+// executed with the current context, and its result is stored for subsequent
+// tasks to access. The first failing task stops scheduling of new tasks,
+// unless it has continue_on_error, and triggers the on_failure tasks.
+// Cancelling ctx, or exceeding the workflow timeout, stops the in-flight tasks.
+func (r *Run) Execute(ctx context.Context, workflow WorkflowDefinition) error {
+	return r.run(ctx, &RunInfo{Workflow: workflow}, r.hooks, r.middleware)
+}
+
+// ExecuteWithLogging is Execute with full logging to database through the
+// run's ExecutionLogger. It creates an Execution record (or updates existing if executionID is provided),
+// logs each task attempt as a TaskLog, and updates the execution status.
+// If executionID is nil, a new execution will be created.
+// Cancelling ctx stops the in-flight tasks and records the execution as
+// "cancelled" ("interrupted" if the cause is ErrInterrupted).
+// A run whose only failures were in continue_on_error tasks is recorded as
+// "completed_with_errors"; any other failure runs the on_failure tasks.
+// Progress is published to the run's EventBus as it is recorded.
+func (r *Run) ExecuteWithLogging(
+	ctx context.Context,
+	workflow WorkflowDefinition,
+	workflowID uuid.UUID,
+	executionID *uuid.UUID,
+) (*ExecutionRecord, error) {
+	if r.logger == nil {
+		return nil, fmt.Errorf("run has no execution logger")
+	}
+
+	logging := &loggingMiddleware{
+		logger:      r.logger,
+		events:      r.events,
+		existing:    executionID != nil,
+		resumedFrom: r.resumedFrom,
+	}
+	id := uuid.New()
+	if executionID != nil {
+		id = *executionID
+	}
+
+	// Logging is outermost, so it records what the other middleware returned
+	hooks := append([]RunHook{logging}, r.hooks...)
+	middleware := append([]Middleware{logging.wrapTask}, r.middleware...)
+	err := r.run(ctx, &RunInfo{Workflow: workflow, WorkflowID: workflowID, ExecutionID: &id}, hooks, middleware)
+	return logging.execution, err
+}
+
+// run executes info.Workflow between the BeforeRun and AfterRun calls of
+// hooks, wrapping every task attempt in middleware.
+func (r *Run) run(ctx context.Context, info *RunInfo, hooks []RunHook, middleware []Middleware) error {
+	workflow := info.Workflow
+	info.Context = r.context
+	info.StartedAt = time.Now().UTC()
+
+	for i, hook := range hooks {
+		hookCtx, err := hook.BeforeRun(ctx, info)
+		if err != nil {
+			slog.Error("Workflow run aborted by hook", "workflow", workflow.Name, "error", err)
+			info.Status = "failed"
+			info.Err = err
+			info.CompletedAt = time.Now().UTC()
+			// Let the hooks already started finish, e.g. to record the failure
+			_ = afterRun(ctx, info, hooks[:i])
+			return err
+		}
+		ctx = hookCtx
+	}
+
+	slog.Info("Starting workflow execution",
+		"execution_id", info.ExecutionID,
+		"workflow", workflow.Name,
+		"task_count", len(workflow.Tasks),
+	)
+
+	runCtx, cancel := withTimeoutSeconds(ctx, workflow.Timeout)
+	defer cancel()
+	runCtx = r.withRunInfo(runCtx, info.ExecutionID)
+
+	executeTask := func(ctx context.Context, i int, task Task) error {
+		call := TaskCall{ExecutionID: info.ExecutionID, Index: i, Task: task, Context: r.context}
+		return r.executeTask(ctx, call, middleware)
+	}
+
+	graph, err := buildTaskGraph(workflow.Tasks)
+	if err != nil {
+		slog.Error("Invalid workflow task graph", "execution_id", info.ExecutionID, "workflow", workflow.Name, "error", err)
+	} else {
+		err = graph.run(runCtx, workflow.Parallelism, r.graphTask(runCtx, executeTask))
+	}
+	if err != nil {
+		r.runOnFailure(runCtx, workflow, err, executeTask)
+	}
+
+	info.CompletedAt = time.Now().UTC()
+	info.Status = r.executionStatus(runCtx, err)
+	info.Err = err
+	switch info.Status {
+	case "cancelled":
+		slog.Warn("Workflow execution cancelled", "execution_id", info.ExecutionID, "workflow", workflow.Name)
+	case "interrupted":
+		slog.Warn("Workflow execution interrupted", "execution_id", info.ExecutionID, "workflow", workflow.Name)
+	case "completed":
+		slog.Info("Workflow execution completed successfully",
+			"execution_id", info.ExecutionID,
+			"workflow", workflow.Name,
+			"total_tasks", len(workflow.Tasks),
+		)
+	case "completed_with_errors":
+		slog.Warn("Workflow execution completed with errors", "execution_id", info.ExecutionID, "workflow", workflow.Name)
+	}
+
+	if hookErr := afterRun(ctx, info, hooks); hookErr != nil {
+		return hookErr
+	}
+	return err
+}
+
+// afterRun calls AfterRun on hooks in reverse order and returns the first error.
+func afterRun(ctx context.Context, info *RunInfo, hooks []RunHook) error {
+	var firstErr error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].AfterRun(ctx, info); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// executeTask runs a single task against the run's context, retrying failed
+// attempts according to the task's RetryPolicy. Every attempt goes through
+// middleware. A task whose when condition is false gets a single skipped
+// attempt. Foreach tasks are fanned out over their items within one attempt,
+// each item being retried on its own.
+func (r *Run) executeTask(ctx context.Context, call TaskCall, middleware []Middleware) error {
+	task := call.Task
+	call.Attempt = 1
+
+	skip, err := r.shouldSkip(task)
+	if err != nil {
+		slog.Error("Invalid task condition", "execution_id", call.ExecutionID, "id", task.ID, "when", task.When, "error", err)
+		wrapTask(middleware, failedAttempt(err))(ctx, call)
+		return err
+	}
+	if skip {
+		slog.Info("Task skipped", "execution_id", call.ExecutionID, "id", task.ID, "type", task.Type, "when", task.When)
+		call.Skip = true
+		wrapTask(middleware, func(context.Context, TaskCall) TaskResult {
+			return TaskResult{Status: "skipped"}
+		})(ctx, call)
+		return nil
+	}
+
+	// Lookup executor from registry
+	executor, err := r.registry.Get(task.Type)
+	if err != nil {
+		slog.Error("Task executor not found", "type", task.Type, "error", err)
+		err = fmt.Errorf("task executor not found for type '%s': %w", task.Type, err)
+		wrapTask(middleware, failedAttempt(err))(ctx, call)
+		return err
+	}
+
+	attempt := wrapTask(middleware, func(ctx context.Context, call TaskCall) TaskResult {
+		if call.Task.ForEach != nil {
+			return r.executeForEach(ctx, executor, call.Task)
+		}
+		return r.executeAttempt(ctx, executor, call.Task, r.context)
+	})
+	for ; ; call.Attempt++ {
+		slog.Info("Processing task",
+			"execution_id", call.ExecutionID,
+			"index", call.Index,
+			"id", task.ID,
+			"type", task.Type,
+			"attempt", call.Attempt,
+		)
+
+		result := attempt(ctx, call)
+		if result.Status == "success" {
+			slog.Info("Task completed successfully",
+				"execution_id", call.ExecutionID,
+				"id", task.ID,
+				"type", task.Type,
+				"attempt", call.Attempt,
+			)
+			// Store result in context for subsequent tasks
+			r.context.Set(task.ID+"_result", result.Output)
+			return nil
+		}
+
+		slog.Error("Task failed",
+			"execution_id", call.ExecutionID,
+			"id", task.ID,
+			"type", task.Type,
+			"attempt", call.Attempt,
+			"error", result.Error,
+		)
+		if ctx.Err() != nil {
+			return taskCancelledError(ctx, task)
+		}
+		// Foreach items have already been retried individually
+		if task.ForEach != nil || !task.Retry.shouldRetry(call.Attempt, result) {
+			return taskFailedError(task, call.Attempt, result)
+		}
+		if err := waitBeforeRetry(ctx, task, call.Attempt); err != nil {
+			return taskCancelledError(ctx, task)
+		}
+	}
+}
+
+// failedAttempt returns a TaskHandler for an attempt that cannot execute the
+// task, failing with err.
+func failedAttempt(err error) TaskHandler {
+	return func(context.Context, TaskCall) TaskResult {
+		return TaskResult{Status: "failed", Error: err.Error()}
+	}
+}
+
+// executeWithRetry runs a task against execCtx, retrying failed attempts
+// according to the task's RetryPolicy. It returns the last result, and an
+// error if the task did not succeed. It is used for the items of foreach
+// tasks, which are not attempts of their own.
+func (r *Run) executeWithRetry(ctx context.Context, executor TaskExecutor, task Task, execCtx *ExecutionContext) (TaskResult, error) {
+	for attempt := 1; ; attempt++ {
+		// Execute task with the given context and config
+		result := r.executeAttempt(ctx, executor, task, execCtx)
+
+		if result.Status == "success" {
+			slog.Info("Task completed successfully",
+				"id", task.ID,
+				"type", task.Type,
+				"attempt", attempt,
+			)
+			return result, nil
+		}
+
+		slog.Error("Task failed",
+			"id", task.ID,
+			"type", task.Type,
+			"attempt", attempt,
+			"error", result.Error,
+		)
+		if ctx.Err() != nil {
+			return result, taskCancelledError(ctx, task)
+		}
+		if !task.Retry.shouldRetry(attempt, result) {
+			return result, taskFailedError(task, attempt, result)
+		}
+		if err := waitBeforeRetry(ctx, task, attempt); err != nil {
+			return result, taskCancelledError(ctx, task)
+		}
+	}
+}
+
+// shouldSkip evaluates the task's when condition against the run's context.
+// Tasks without a condition are never skipped.
+func (r *Run) shouldSkip(task Task) (bool, error) {
+	if task.When == "" {
+		return false, nil
+	}
+	ok, err := EvaluateCondition(task.When, r.context.GetAll())
+	if err != nil {
+		return false, fmt.Errorf("task %s has an invalid when condition: %w", task.ID, err)
+	}
+	return !ok, nil
+}
+
+// executeAttempt runs one attempt of a task against execCtx, bounded by the task timeout.
+func (r *Run) executeAttempt(ctx context.Context, executor TaskExecutor, task Task, execCtx *ExecutionContext) TaskResult {
+	ctx, cancel := withTimeoutSeconds(ctx, task.Timeout)
+	defer cancel()
+	return executor.Execute(ctx, execCtx, task.Config)
+}
+
+// taskFailedError builds the error returned when a task gives up.
+func taskFailedError(task Task, attempts int, result TaskResult) error {
+	if attempts > 1 {
+		return fmt.Errorf("task %s failed after %d attempts: %s", task.ID, attempts, result.Error)
+	}
+	return fmt.Errorf("task %s failed: %s", task.ID, result.Error)
+}
+
+// taskCancelledError builds the error returned when ctx stops a task.
+func taskCancelledError(ctx context.Context, task Task) error {
+	return fmt.Errorf("task %s stopped: %w", task.ID, ctx.Err())
+}
+
+// waitBeforeRetry sleeps for the backoff delay following a failed attempt.
+// It returns ctx.Err() if ctx is done before the delay elapses.
+func waitBeforeRetry(ctx context.Context, task Task, attempt int) error {
+	delay := task.Retry.delay(attempt)
+	slog.Warn("Retrying task",
+		"id", task.ID,
+		"type", task.Type,
+		"next_attempt", attempt+1,
+		"max_attempts", task.Retry.maxAttempts(),
+		"delay", delay,
+	)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// withTimeoutSeconds derives a context with the given timeout in seconds.
+// A non-positive timeout only makes the context cancellable.
+func withTimeoutSeconds(ctx context.Context, seconds int) (context.Context, context.CancelFunc) {
+	if seconds <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(seconds)*time.Second)
+}
+
+// executionStatus maps the outcome of a run to the Execution status:
+// "completed" ("completed_with_errors" if a task failed with
+// continue_on_error), "interrupted" when ctx was cancelled by Engine.Drain,
+// "cancelled" when it was cancelled otherwise, or "failed" otherwise
+// (including workflow timeouts).
+func (r *Run) executionStatus(ctx context.Context, err error) string {
+	switch {
+	case err == nil && r.tolerated.Load():
+		return "completed_with_errors"
+	case err == nil:
+		return "completed"
+	case errors.Is(context.Cause(ctx), ErrInterrupted):
+		return "interrupted"
+	case errors.Is(ctx.Err(), context.Canceled):
+		return "cancelled"
+	default:
+		return "failed"
+	}
+}
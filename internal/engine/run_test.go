@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoExecutor writes config["value"] to the shared "echo" key, yields, and
+// reads it back. If two runs shared an ExecutionContext the value read back
+// could belong to the other run.
+type echoExecutor struct{}
+
+func (e *echoExecutor) Execute(ctx context.Context, execCtx *ExecutionContext, config map[string]interface{}) TaskResult {
+	execCtx.Set("echo", config["value"])
+	time.Sleep(time.Millisecond)
+	val, _ := execCtx.Get("echo")
+	return TaskResult{Status: "success", Output: val}
+}
+
+// memoryLogger is a thread-safe in-memory ExecutionLogger for tests.
+type memoryLogger struct {
+	mu         sync.Mutex
+	executions map[uuid.UUID]ExecutionRecord
+	taskLogs   map[uuid.UUID]TaskLogRecord
+}
+
+func newMemoryLogger() *memoryLogger {
+	return &memoryLogger{
+		executions: make(map[uuid.UUID]ExecutionRecord),
+		taskLogs:   make(map[uuid.UUID]TaskLogRecord),
+	}
+}
+
+func (m *memoryLogger) CreateExecution(execution *ExecutionRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.executions[execution.ID] = *execution
+	return nil
+}
+
+func (m *memoryLogger) UpdateExecution(execution *ExecutionRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.executions[execution.ID]; !exists {
+		return fmt.Errorf("execution not found: %s", execution.ID)
+	}
+	m.executions[execution.ID] = *execution
+	return nil
+}
+
+func (m *memoryLogger) CreateTaskLog(taskLog *TaskLogRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.taskLogs[taskLog.ID] = *taskLog
+	return nil
+}
+
+func (m *memoryLogger) UpdateTaskLog(taskLog *TaskLogRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.taskLogs[taskLog.ID] = *taskLog
+	return nil
+}
+
+func (m *memoryLogger) taskLogsFor(executionID uuid.UUID) []TaskLogRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var logs []TaskLogRecord
+	for _, tl := range m.taskLogs {
+		if tl.ExecutionID == executionID {
+			logs = append(logs, tl)
+		}
+	}
+	return logs
+}
+
+func echoWorkflow(value int) WorkflowDefinition {
+	return WorkflowDefinition{
+		Name: fmt.Sprintf("echo-%d", value),
+		Tasks: []Task{
+			{ID: "echo1", Type: "echo", Config: map[string]interface{}{"value": value}},
+			{ID: "echo2", Type: "echo", Config: map[string]interface{}{"value": value}},
+		},
+	}
+}
+
+func TestRun_ContextIsSeededBeforeExecute(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("mock", &MockExecutor{})
+	run := NewEngine(registry).NewRun(nil)
+
+	run.Context().Set("seed", "value")
+	err := run.Execute(context.Background(), WorkflowDefinition{
+		Name:  "seeded",
+		Tasks: []Task{{ID: "task1", Type: "mock", Config: map[string]interface{}{}}},
+	})
+
+	assert.NoError(t, err)
+	seed, _ := run.Context().Get("seed")
+	assert.Equal(t, "value", seed)
+	_, exists := run.Context().Get("task1_result")
+	assert.True(t, exists)
+}
+
+func TestRun_ExecuteWithLoggingRequiresLogger(t *testing.T) {
+	run := NewEngine(nil).NewRun(nil)
+
+	record, err := run.ExecuteWithLogging(context.Background(), WorkflowDefinition{Name: "no-logger"}, uuid.New(), nil)
+
+	assert.Error(t, err)
+	assert.Nil(t, record)
+	assert.Contains(t, err.Error(), "no execution logger")
+}
+
+func TestEngine_ConcurrentExecute(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("echo", &echoExecutor{})
+	eng := NewEngine(registry)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(value int) {
+			defer wg.Done()
+			execCtx, err := eng.Execute(context.Background(), echoWorkflow(value))
+			assert.NoError(t, err)
+
+			result1, _ := execCtx.Get("echo1_result")
+			result2, _ := execCtx.Get("echo2_result")
+			assert.Equal(t, value, result1)
+			assert.Equal(t, value, result2)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestEngine_ConcurrentExecuteWithLogging(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("echo", &echoExecutor{})
+	eng := NewEngine(registry)
+	logger := newMemoryLogger()
+	workflowID := uuid.New()
+
+	const runs = 50
+	records := make([]*ExecutionRecord, runs)
+
+	var wg sync.WaitGroup
+	for i := 0; i < runs; i++ {
+		wg.Add(1)
+		go func(value int) {
+			defer wg.Done()
+			record, err := eng.ExecuteWithLogging(context.Background(), echoWorkflow(value), workflowID, logger, nil)
+			assert.NoError(t, err)
+			records[value] = record
+		}(i)
+	}
+	wg.Wait()
+
+	for value, record := range records {
+		require.NotNil(t, record)
+		assert.Equal(t, "completed", record.Status)
+		assert.JSONEq(t,
+			fmt.Sprintf(`{"echo": %d, "echo1_result": %d, "echo2_result": %d}`, value, value, value),
+			string(record.ContextSnapshot),
+		)
+		assert.Len(t, logger.taskLogsFor(record.ID), 2)
+	}
+}
@@ -0,0 +1,182 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DefaultParallelism is the number of tasks a run executes concurrently when
+// WorkflowDefinition.Parallelism is not set.
+const DefaultParallelism = 4
+
+// taskGraph is the dependency DAG of a workflow's tasks. Nodes are indexes
+// into tasks; dependents[i] lists the tasks waiting on task i.
+type taskGraph struct {
+	tasks      []Task
+	dependents [][]int
+	indegree   []int
+}
+
+// buildTaskGraph builds the dependency DAG for a list of tasks.
+// If no task declares depends_on, every task implicitly depends on the
+// previous one so existing workflows keep running strictly in order.
+// Returns an error for duplicate task IDs, unknown dependencies or cycles.
+func buildTaskGraph(tasks []Task) (*taskGraph, error) {
+	index := make(map[string]int, len(tasks))
+	explicit := false
+	for i, task := range tasks {
+		if _, exists := index[task.ID]; exists {
+			return nil, fmt.Errorf("duplicate task id '%s'", task.ID)
+		}
+		index[task.ID] = i
+		if len(task.DependsOn) > 0 {
+			explicit = true
+		}
+	}
+
+	graph := &taskGraph{
+		tasks:      tasks,
+		dependents: make([][]int, len(tasks)),
+		indegree:   make([]int, len(tasks)),
+	}
+
+	for i, task := range tasks {
+		if !explicit {
+			if i > 0 {
+				graph.addEdge(i-1, i)
+			}
+			continue
+		}
+		for _, dep := range task.DependsOn {
+			j, exists := index[dep]
+			if !exists {
+				return nil, fmt.Errorf("task '%s' depends on unknown task '%s'", task.ID, dep)
+			}
+			if j == i {
+				return nil, fmt.Errorf("task '%s' depends on itself", task.ID)
+			}
+			graph.addEdge(j, i)
+		}
+	}
+
+	if cycle := graph.findCycle(); len(cycle) > 0 {
+		return nil, fmt.Errorf("workflow contains a dependency cycle involving tasks: %s", strings.Join(cycle, ", "))
+	}
+
+	return graph, nil
+}
+
+// addEdge records that task to depends on task from.
+func (g *taskGraph) addEdge(from, to int) {
+	g.dependents[from] = append(g.dependents[from], to)
+	g.indegree[to]++
+}
+
+// findCycle runs Kahn's algorithm and returns the IDs of tasks that could
+// never become ready, which is empty when the graph is acyclic.
+func (g *taskGraph) findCycle() []string {
+	indegree := make([]int, len(g.indegree))
+	copy(indegree, g.indegree)
+
+	queue := make([]int, 0, len(g.tasks))
+	for i, d := range indegree {
+		if d == 0 {
+			queue = append(queue, i)
+		}
+	}
+
+	visited := 0
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		visited++
+		for _, j := range g.dependents[i] {
+			indegree[j]--
+			if indegree[j] == 0 {
+				queue = append(queue, j)
+			}
+		}
+	}
+
+	if visited == len(g.tasks) {
+		return nil
+	}
+
+	var cycle []string
+	for i, d := range indegree {
+		if d > 0 {
+			cycle = append(cycle, g.tasks[i].ID)
+		}
+	}
+	return cycle
+}
+
+// taskOutcome is sent back to the scheduler when a task goroutine finishes.
+type taskOutcome struct {
+	index int
+	err   error
+}
+
+// run executes the graph, starting each task once all of its dependencies
+// have succeeded and never running more than parallelism tasks at a time
+// (DefaultParallelism if parallelism <= 0). Ready tasks start in declaration
+// order. After the first failure, or once ctx is done, no new tasks are
+// started; tasks already in flight are awaited and the first error is
+// returned (ctx.Err() if tasks were left unstarted because ctx was done).
+func (g *taskGraph) run(ctx context.Context, parallelism int, runTask func(i int, task Task) error) error {
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism
+	}
+
+	indegree := make([]int, len(g.indegree))
+	copy(indegree, g.indegree)
+
+	ready := make([]int, 0, len(g.tasks))
+	for i, d := range indegree {
+		if d == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	outcomes := make(chan taskOutcome)
+	running := 0
+	completed := 0
+	var firstErr error
+
+	for {
+		for firstErr == nil && ctx.Err() == nil && len(ready) > 0 && running < parallelism {
+			i := ready[0]
+			ready = ready[1:]
+			running++
+			go func(i int) {
+				outcomes <- taskOutcome{index: i, err: runTask(i, g.tasks[i])}
+			}(i)
+		}
+
+		if running == 0 {
+			break
+		}
+
+		outcome := <-outcomes
+		running--
+		if outcome.err != nil {
+			if firstErr == nil {
+				firstErr = outcome.err
+			}
+			continue
+		}
+		completed++
+		for _, j := range g.dependents[outcome.index] {
+			indegree[j]--
+			if indegree[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+	}
+
+	if firstErr == nil && completed < len(g.tasks) {
+		firstErr = ctx.Err()
+	}
+	return firstErr
+}
@@ -0,0 +1,262 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// concurrencyExecutor records the peak number of concurrent Execute calls and
+// the order in which tasks (identified by config["name"]) started.
+type concurrencyExecutor struct {
+	delay   time.Duration
+	current int32
+	peak    int32
+
+	mu    sync.Mutex
+	order []string
+}
+
+func (c *concurrencyExecutor) Execute(ctx context.Context, execCtx *ExecutionContext, config map[string]interface{}) TaskResult {
+	c.mu.Lock()
+	c.order = append(c.order, config["name"].(string))
+	c.mu.Unlock()
+
+	n := atomic.AddInt32(&c.current, 1)
+	for {
+		peak := atomic.LoadInt32(&c.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(&c.peak, peak, n) {
+			break
+		}
+	}
+	time.Sleep(c.delay)
+	atomic.AddInt32(&c.current, -1)
+
+	return TaskResult{Status: "success", Output: config["name"]}
+}
+
+func (c *concurrencyExecutor) started() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.order...)
+}
+
+func dagTask(id string, deps ...string) Task {
+	return Task{
+		ID:        id,
+		Type:      "track",
+		Config:    map[string]interface{}{"name": id},
+		DependsOn: deps,
+	}
+}
+
+func TestBuildTaskGraph_ImplicitSequentialOrder(t *testing.T) {
+	graph, err := buildTaskGraph([]Task{dagTask("a"), dagTask("b"), dagTask("c")})
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 1}, graph.indegree)
+	assert.Equal(t, []int{1}, graph.dependents[0])
+	assert.Equal(t, []int{2}, graph.dependents[1])
+}
+
+func TestBuildTaskGraph_ExplicitDependencies(t *testing.T) {
+	graph, err := buildTaskGraph([]Task{
+		dagTask("a"),
+		dagTask("b"),
+		dagTask("c", "a", "b"),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 0, 2}, graph.indegree)
+}
+
+func TestBuildTaskGraph_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		tasks   []Task
+		message string
+	}{
+		{
+			name:    "duplicate id",
+			tasks:   []Task{dagTask("a"), dagTask("a")},
+			message: "duplicate task id 'a'",
+		},
+		{
+			name:    "unknown dependency",
+			tasks:   []Task{dagTask("a", "missing")},
+			message: "task 'a' depends on unknown task 'missing'",
+		},
+		{
+			name:    "self dependency",
+			tasks:   []Task{dagTask("a", "a")},
+			message: "task 'a' depends on itself",
+		},
+		{
+			name:    "cycle",
+			tasks:   []Task{dagTask("root"), dagTask("a", "c"), dagTask("b", "a"), dagTask("c", "b")},
+			message: "dependency cycle involving tasks: a, b, c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph, err := buildTaskGraph(tt.tasks)
+			assert.Nil(t, graph)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestEngine_Execute_IndependentTasksRunConcurrently(t *testing.T) {
+	tracker := &concurrencyExecutor{delay: 50 * time.Millisecond}
+	registry := NewRegistry()
+	registry.Register("track", tracker)
+
+	workflow := WorkflowDefinition{
+		Name:        "fan-out",
+		Parallelism: 3,
+		Tasks: []Task{
+			dagTask("page1", "start"),
+			dagTask("page2", "start"),
+			dagTask("page3", "start"),
+			dagTask("start"),
+			dagTask("merge", "page1", "page2", "page3"),
+		},
+	}
+
+	execCtx, err := NewEngine(registry).Execute(context.Background(), workflow)
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&tracker.peak))
+
+	order := tracker.started()
+	assert.Equal(t, "start", order[0])
+	assert.ElementsMatch(t, []string{"page1", "page2", "page3"}, order[1:4])
+	assert.Equal(t, "merge", order[4])
+
+	for _, id := range []string{"start", "page1", "page2", "page3", "merge"} {
+		result, exists := execCtx.Get(id + "_result")
+		assert.True(t, exists, id)
+		assert.Equal(t, id, result)
+	}
+}
+
+func TestEngine_Execute_ParallelismLimit(t *testing.T) {
+	tracker := &concurrencyExecutor{delay: 20 * time.Millisecond}
+	registry := NewRegistry()
+	registry.Register("track", tracker)
+
+	tasks := []Task{dagTask("root")}
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		tasks = append(tasks, dagTask(id, "root"))
+	}
+
+	_, err := NewEngine(registry).Execute(context.Background(), WorkflowDefinition{
+		Name:        "limited",
+		Parallelism: 2,
+		Tasks:       tasks,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&tracker.peak))
+}
+
+func TestEngine_Execute_ImplicitOrderIsSequential(t *testing.T) {
+	tracker := &concurrencyExecutor{delay: 5 * time.Millisecond}
+	registry := NewRegistry()
+	registry.Register("track", tracker)
+
+	_, err := NewEngine(registry).Execute(context.Background(), WorkflowDefinition{
+		Name:  "legacy",
+		Tasks: []Task{dagTask("a"), dagTask("b"), dagTask("c")},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&tracker.peak))
+	assert.Equal(t, []string{"a", "b", "c"}, tracker.started())
+}
+
+func TestEngine_Execute_FailureSkipsDependents(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("track", &concurrencyExecutor{})
+	registry.Register("failing", &MockExecutor{ShouldFail: true, ErrorMsg: "boom"})
+
+	failing := Task{ID: "broken", Type: "failing", Config: map[string]interface{}{}, DependsOn: []string{"root"}}
+	execCtx, err := NewEngine(registry).Execute(context.Background(), WorkflowDefinition{
+		Name: "failing-branch",
+		Tasks: []Task{
+			dagTask("root"),
+			failing,
+			dagTask("after", "broken"),
+		},
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "task broken failed: boom")
+	_, exists := execCtx.Get("root_result")
+	assert.True(t, exists)
+	_, exists = execCtx.Get("after_result")
+	assert.False(t, exists)
+}
+
+func TestEngine_Execute_RejectsCycle(t *testing.T) {
+	tracker := &concurrencyExecutor{}
+	registry := NewRegistry()
+	registry.Register("track", tracker)
+
+	_, err := NewEngine(registry).Execute(context.Background(), WorkflowDefinition{
+		Name:  "cyclic",
+		Tasks: []Task{dagTask("a", "b"), dagTask("b", "a")},
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dependency cycle")
+	assert.Empty(t, tracker.started())
+}
+
+func TestEngine_ExecuteWithLogging_RejectsCycle(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("track", &concurrencyExecutor{})
+	logger := newMemoryLogger()
+
+	record, err := NewEngine(registry).ExecuteWithLogging(context.Background(), WorkflowDefinition{
+		Name:  "cyclic",
+		Tasks: []Task{dagTask("a", "b"), dagTask("b", "a")},
+	}, uuid.New(), logger, nil)
+
+	require.Error(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "failed", record.Status)
+	assert.Empty(t, logger.taskLogsFor(record.ID))
+}
+
+func TestEngine_ExecuteWithLogging_ParallelBranches(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("track", &concurrencyExecutor{delay: 10 * time.Millisecond})
+	logger := newMemoryLogger()
+
+	record, err := NewEngine(registry).ExecuteWithLogging(context.Background(), WorkflowDefinition{
+		Name: "parallel-logging",
+		Tasks: []Task{
+			dagTask("a"),
+			dagTask("b"),
+			dagTask("c", "a", "b"),
+		},
+	}, uuid.New(), logger, nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, "completed", record.Status)
+
+	logs := logger.taskLogsFor(record.ID)
+	assert.Len(t, logs, 3)
+	for _, tl := range logs {
+		assert.Equal(t, "success", tl.Status)
+	}
+}
@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// checkSchema checks value against a JSON Schema and returns the problems
+// found, each prefixed with the path of the offending value. Only the
+// keywords needed for task configs are supported: type, required,
+// properties, additionalProperties (as a schema), items and enum. Unknown
+// keywords are ignored.
+func checkSchema(value interface{}, schema map[string]interface{}, path string) []string {
+	if schemaType, ok := schema["type"].(string); ok && !hasInputType(value, schemaType) {
+		return []string{fmt.Sprintf("%s must be of type %s (got %s)", path, schemaType, jsonTypeName(value))}
+	}
+
+	var problems []string
+	if enum, ok := schema["enum"]; ok && !inEnum(value, enum) {
+		problems = append(problems, fmt.Sprintf("%s must be one of %v", path, enum))
+	}
+
+	if object, ok := value.(map[string]interface{}); ok {
+		for _, key := range schemaStrings(schema["required"]) {
+			if v, exists := object[key]; !exists || v == nil {
+				problems = append(problems, fmt.Sprintf("%s.%s is required", path, key))
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		additional, _ := schema["additionalProperties"].(map[string]interface{})
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			propertySchema, declared := properties[key].(map[string]interface{})
+			if !declared {
+				propertySchema = additional
+			}
+			if object[key] != nil && propertySchema != nil {
+				problems = append(problems, checkSchema(object[key], propertySchema, path+"."+key)...)
+			}
+		}
+	}
+
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		if list, ok := value.([]interface{}); ok {
+			for i, item := range list {
+				problems = append(problems, checkSchema(item, items, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+
+	return problems
+}
+
+// schemaStrings reads a list of strings from a schema keyword, which may be
+// written in Go as []string or decoded from JSON as []interface{}.
+func schemaStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		strs := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				strs = append(strs, s)
+			}
+		}
+		return strs
+	}
+	return nil
+}
+
+// inEnum reports whether value is one of the values listed by an enum keyword.
+func inEnum(value interface{}, enum interface{}) bool {
+	list := reflect.ValueOf(enum)
+	if list.Kind() != reflect.Slice {
+		return true
+	}
+	for i := 0; i < list.Len(); i++ {
+		if equal, _ := compareValues("==", value, list.Index(i).Interface()); equal {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeName names the JSON type of a decoded value for error messages.
+func jsonTypeName(value interface{}) string {
+	switch {
+	case value == nil:
+		return "null"
+	case hasInputType(value, "boolean"):
+		return "boolean"
+	case hasInputType(value, "number"):
+		return "number"
+	case hasInputType(value, "string"):
+		return "string"
+	case hasInputType(value, "array"):
+		return "array"
+	case hasInputType(value, "object"):
+		return "object"
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", value), "*")
+}
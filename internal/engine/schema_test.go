@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSchema(t *testing.T) {
+	schema := map[string]interface{}{
+		"type":     "object",
+		"required": []string{"url"},
+		"properties": map[string]interface{}{
+			"url":     map[string]interface{}{"type": "string"},
+			"timeout": map[string]interface{}{"type": "integer"},
+			"mode":    map[string]interface{}{"type": "string", "enum": []string{"fast", "slow"}},
+			"selectors": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":     "object",
+					"required": []interface{}{"name"},
+				},
+			},
+		},
+		"additionalProperties": map[string]interface{}{"type": "boolean"},
+	}
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		problems []string
+	}{
+		{"valid", map[string]interface{}{"url": "x", "timeout": float64(5), "mode": "fast", "debug": true}, nil},
+		{"not an object", "x", []string{"config must be of type object (got string)"}},
+		{"missing required", map[string]interface{}{}, []string{"config.url is required"}},
+		{"null required", map[string]interface{}{"url": nil}, []string{"config.url is required"}},
+		{"wrong property type", map[string]interface{}{"url": "x", "timeout": "5"}, []string{"config.timeout must be of type integer (got string)"}},
+		{"enum", map[string]interface{}{"url": "x", "mode": "medium"}, []string{"config.mode must be one of [fast slow]"}},
+		{"additional property", map[string]interface{}{"url": "x", "debug": "yes"}, []string{"config.debug must be of type boolean (got string)"}},
+		{
+			"array items",
+			map[string]interface{}{"url": "x", "selectors": []interface{}{map[string]interface{}{"name": "a"}, map[string]interface{}{}}},
+			[]string{"config.selectors[1].name is required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.problems, checkSchema(tt.value, schema, "config"))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+// MaxWorkflowDepth limits how deeply workflows may nest through ExecuteChild,
+// counting the top-level workflow. It guards against workflows that
+// (directly or indirectly) invoke themselves.
+const MaxWorkflowDepth = 5
+
+// runInfoKey is the context key under which a Run stores its runInfo.
+type runInfoKey struct{}
+
+// runInfo describes the run a task is executing in. It travels with the
+// context.Context passed to TaskExecutor.Execute.
+type runInfo struct {
+	run         *Run
+	executionID *uuid.UUID // nil when the run is not logged
+	depth       int        // 1 for a top-level workflow
+}
+
+// withRunInfo returns ctx annotated with the run and its nesting depth.
+func (r *Run) withRunInfo(ctx context.Context, executionID *uuid.UUID) context.Context {
+	depth := 1
+	if parent, ok := ctx.Value(runInfoKey{}).(*runInfo); ok {
+		depth = parent.depth + 1
+	}
+	return context.WithValue(ctx, runInfoKey{}, &runInfo{run: r, executionID: executionID, depth: depth})
+}
+
+// parentExecutionID returns the ID of the logged execution ctx was derived
+// from, or nil for a top-level execution.
+func parentExecutionID(ctx context.Context) *uuid.UUID {
+	if parent, ok := ctx.Value(runInfoKey{}).(*runInfo); ok {
+		return parent.executionID
+	}
+	return nil
+}
+
+// WorkflowDepth reports how deeply the workflow running in ctx is nested:
+// 1 inside the tasks of a top-level workflow, 2 inside a sub-workflow, and
+// 0 outside of any workflow.
+func WorkflowDepth(ctx context.Context) int {
+	if info, ok := ctx.Value(runInfoKey{}).(*runInfo); ok {
+		return info.depth
+	}
+	return 0
+}
+
+// ExecuteChild runs workflow as a sub-workflow of the run ctx belongs to, so
+// ctx must be the context a TaskExecutor received. The child gets its own
+// Run with the parent's registry, logger, middleware and hooks, with input
+// validated against the workflow's declared inputs (see ResolveInputs) and
+// seeded into its context under "input". If the parent is logged, the child is logged as its
+// own execution with ParentExecutionID pointing at the parent.
+// It returns the child's context, which holds the child's task results.
+func ExecuteChild(
+	ctx context.Context,
+	workflow WorkflowDefinition,
+	workflowID uuid.UUID,
+	input map[string]interface{},
+) (*ExecutionContext, error) {
+	parent, ok := ctx.Value(runInfoKey{}).(*runInfo)
+	if !ok {
+		return nil, fmt.Errorf("sub-workflows can only be executed from within a running workflow")
+	}
+	if parent.depth >= MaxWorkflowDepth {
+		return nil, fmt.Errorf("maximum workflow nesting depth of %d exceeded", MaxWorkflowDepth)
+	}
+
+	slog.Info("Starting sub-workflow",
+		"workflow", workflow.Name,
+		"workflow_id", workflowID,
+		"depth", parent.depth+1,
+	)
+
+	input, err := workflow.ResolveInputs(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if parent.executionID == nil {
+		child := parent.run.child(nil)
+		child.context.Set("input", input)
+		return child.context, child.Execute(ctx, workflow)
+	}
+
+	child := parent.run.child(parent.run.logger)
+	child.context.Set("input", input)
+	_, err = child.ExecuteWithLogging(ctx, workflow, workflowID, nil)
+	return child.context, err
+}
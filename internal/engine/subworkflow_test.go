@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// childExecutor runs Child as a sub-workflow, passing config["input"] as input.
+type childExecutor struct {
+	Child WorkflowDefinition
+}
+
+func (c *childExecutor) Execute(ctx context.Context, execCtx *ExecutionContext, config map[string]interface{}) TaskResult {
+	input, _ := config["input"].(map[string]interface{})
+	childCtx, err := ExecuteChild(ctx, c.Child, uuid.New(), input)
+	if err != nil {
+		return TaskResult{Status: "failed", Error: err.Error()}
+	}
+	result, _ := childCtx.Get("echo_result")
+	return TaskResult{Status: "success", Output: result}
+}
+
+// inputEchoExecutor returns the "input" context value.
+type inputEchoExecutor struct{}
+
+func (e *inputEchoExecutor) Execute(ctx context.Context, execCtx *ExecutionContext, config map[string]interface{}) TaskResult {
+	input, _ := execCtx.Get("input")
+	return TaskResult{Status: "success", Output: input}
+}
+
+func childWorkflow() WorkflowDefinition {
+	return WorkflowDefinition{
+		Name:  "child",
+		Tasks: []Task{{ID: "echo", Type: "input_echo", Config: map[string]interface{}{}}},
+	}
+}
+
+func parentWorkflow() WorkflowDefinition {
+	return WorkflowDefinition{
+		Name: "parent",
+		Tasks: []Task{{
+			ID:     "call",
+			Type:   "child",
+			Config: map[string]interface{}{"input": map[string]interface{}{"user": "ada"}},
+		}},
+	}
+}
+
+func TestExecuteChild_RequiresRunningWorkflow(t *testing.T) {
+	_, err := ExecuteChild(context.Background(), childWorkflow(), uuid.New(), nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "within a running workflow")
+}
+
+func TestEngine_Execute_SubWorkflowReceivesInput(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("child", &childExecutor{Child: childWorkflow()})
+	registry.Register("input_echo", &inputEchoExecutor{})
+
+	execCtx, err := NewEngine(registry).Execute(context.Background(), parentWorkflow())
+
+	require.NoError(t, err)
+	result, _ := execCtx.Get("call_result")
+	assert.Equal(t, map[string]interface{}{"user": "ada"}, result)
+	_, exists := execCtx.Get("echo_result")
+	assert.False(t, exists, "child results must not leak into the parent context")
+}
+
+func TestEngine_Execute_SubWorkflowValidatesInput(t *testing.T) {
+	child := childWorkflow()
+	child.Inputs = []InputParameter{
+		{Name: "user", Type: "string", Required: true},
+		{Name: "role", Type: "string", Default: "viewer"},
+	}
+	registry := NewRegistry()
+	registry.Register("child", &childExecutor{Child: child})
+	registry.Register("input_echo", &inputEchoExecutor{})
+
+	execCtx, err := NewEngine(registry).Execute(context.Background(), parentWorkflow())
+	require.NoError(t, err)
+	result, _ := execCtx.Get("call_result")
+	assert.Equal(t, map[string]interface{}{"user": "ada", "role": "viewer"}, result)
+
+	child.Inputs[0].Type = "integer"
+	registry.Register("child", &childExecutor{Child: child})
+
+	_, err = NewEngine(registry).Execute(context.Background(), parentWorkflow())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "input 'user' must be of type integer")
+}
+
+func TestEngine_ExecuteWithLogging_LinksChildExecution(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("child", &childExecutor{Child: childWorkflow()})
+	registry.Register("input_echo", &inputEchoExecutor{})
+	logger := newMemoryLogger()
+
+	record, err := NewEngine(registry).ExecuteWithLogging(context.Background(), parentWorkflow(), uuid.New(), logger, nil)
+
+	require.NoError(t, err)
+	assert.Nil(t, record.ParentExecutionID)
+
+	var children []ExecutionRecord
+	logger.mu.Lock()
+	for _, execution := range logger.executions {
+		if execution.ID != record.ID {
+			children = append(children, execution)
+		}
+	}
+	logger.mu.Unlock()
+
+	require.Len(t, children, 1)
+	child := children[0]
+	require.NotNil(t, child.ParentExecutionID)
+	assert.Equal(t, record.ID, *child.ParentExecutionID)
+	assert.Equal(t, "completed", child.Status)
+	assert.Len(t, logger.taskLogsFor(child.ID), 1)
+}
+
+func TestEngine_Execute_SubWorkflowDepthLimit(t *testing.T) {
+	// A workflow whose only task runs the same workflow again
+	recursive := &childExecutor{}
+	recursive.Child = WorkflowDefinition{
+		Name:  "recursive",
+		Tasks: []Task{{ID: "again", Type: "recursive", Config: map[string]interface{}{}}},
+	}
+	registry := NewRegistry()
+	registry.Register("recursive", recursive)
+
+	_, err := NewEngine(registry).Execute(context.Background(), recursive.Child)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "maximum workflow nesting depth of 5 exceeded")
+}
@@ -4,6 +4,26 @@ package engine
 type WorkflowDefinition struct {
 	Name  string `json:"name"`
 	Tasks []Task `json:"tasks"`
+	// Parallelism caps how many independent tasks run at once (default: DefaultParallelism)
+	Parallelism int `json:"parallelism,omitempty"`
+	// Timeout is the maximum duration of the whole execution in seconds (0 means no limit)
+	Timeout int `json:"timeout,omitempty"`
+	// OnFailure lists tasks run when the workflow fails, e.g. for cleanup or
+	// notifications. They see failure.task_id and failure.error in the context.
+	OnFailure []Task `json:"on_failure,omitempty"`
+	// Inputs declares the parameters a caller may pass when running the
+	// workflow. Resolved values are available in the context under "input".
+	Inputs []InputParameter `json:"inputs,omitempty"`
+}
+
+// InputParameter declares a single workflow input
+type InputParameter struct {
+	Name string `json:"name"`
+	// Type is one of "string", "number", "integer", "boolean", "object" or "array"
+	Type     string `json:"type"`
+	Required bool   `json:"required,omitempty"`
+	// Default is used when the caller does not provide the input
+	Default interface{} `json:"default,omitempty"`
 }
 
 // Task represents a single executable task within a workflow
@@ -11,6 +31,21 @@ type Task struct {
 	ID     string                 `json:"id"`
 	Type   string                 `json:"type"`
 	Config map[string]interface{} `json:"config"`
+	// DependsOn lists task IDs that must succeed before this task starts.
+	// If no task in a workflow declares it, tasks run sequentially in order.
+	DependsOn []string `json:"depends_on,omitempty"`
+	// Retry configures retries of failed attempts (default: no retries)
+	Retry *RetryPolicy `json:"retry,omitempty"`
+	// Timeout is the maximum duration of each attempt in seconds (0 means no limit)
+	Timeout int `json:"timeout,omitempty"`
+	// When is a condition evaluated against the context before the task runs
+	// (see EvaluateCondition). If it is false the task is skipped and writes no result.
+	When string `json:"when,omitempty"`
+	// ForEach runs the task once per element of a context array (see ForEach)
+	ForEach *ForEach `json:"foreach,omitempty"`
+	// ContinueOnError lets the workflow go on when this task fails. The error
+	// is stored under <id>_error and the execution ends "completed_with_errors".
+	ContinueOnError bool `json:"continue_on_error,omitempty"`
 }
 
 // TaskResult represents the outcome of a task execution
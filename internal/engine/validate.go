@@ -0,0 +1,249 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ValidationError lists every problem Validate found in a workflow definition.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid workflow definition: " + strings.Join(e.Problems, "; ")
+}
+
+var (
+	// resultPathPattern matches config values that are context paths into a
+	// task result, e.g. "fetch_result" or "login_result.body.token"
+	resultPathPattern = regexp.MustCompile(`^([A-Za-z0-9_-]+)_result(?:[.\[]|$)`)
+	// resultTemplatePattern matches task results referenced in templates,
+	// e.g. {{.context.login_result.body.token}}
+	resultTemplatePattern = regexp.MustCompile(`\.context\.([A-Za-z0-9_]+)_result\b`)
+)
+
+// Validate checks a workflow definition without running it. It reports
+// duplicate or missing task IDs, dependency problems, task types that are
+// not registered in registry, task configs that do not match their
+// executor's schema (see SchemaProvider) or are rejected by its
+// ConfigValidator, invalid `when` conditions, foreach, retry and input
+// settings, and references to <id>_result keys of tasks that do not exist
+// in the workflow. Executor checks are skipped when registry is nil.
+// All problems are returned together in a *ValidationError; nil means the
+// definition is valid.
+func Validate(workflow WorkflowDefinition, registry *Registry) error {
+	var problems []string
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if workflow.Parallelism < 0 {
+		addf("parallelism must not be negative")
+	}
+	if workflow.Timeout < 0 {
+		addf("timeout must not be negative")
+	}
+	problems = append(problems, validateInputs(workflow.Inputs)...)
+
+	taskIDs := make(map[string]bool, len(workflow.Tasks)+len(workflow.OnFailure))
+	for _, task := range append(append([]Task(nil), workflow.Tasks...), workflow.OnFailure...) {
+		if task.ID == "" {
+			continue
+		}
+		if taskIDs[task.ID] {
+			addf("duplicate task id '%s'", task.ID)
+		}
+		taskIDs[task.ID] = true
+	}
+
+	for _, group := range [][]Task{workflow.Tasks, workflow.OnFailure} {
+		for i, task := range group {
+			problems = append(problems, validateTask(i, task, taskIDs, registry)...)
+		}
+		if hasUniqueIDs(group) {
+			if _, err := buildTaskGraph(group); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// validateTask checks a single task. i is its position, used to name tasks
+// that have no ID.
+func validateTask(i int, task Task, taskIDs map[string]bool, registry *Registry) []string {
+	var problems []string
+	name := fmt.Sprintf("task '%s'", task.ID)
+	if task.ID == "" {
+		name = fmt.Sprintf("task %d", i)
+		problems = append(problems, fmt.Sprintf("%s has no id", name))
+	}
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, name+" "+fmt.Sprintf(format, args...))
+	}
+
+	// nil checks every config string for context paths
+	var pathKeys []string
+	switch {
+	case task.Type == "":
+		addf("has no type")
+	case registry != nil:
+		executor, err := registry.Get(task.Type)
+		if err != nil {
+			addf("has unknown type '%s'", task.Type)
+			break
+		}
+		if provider, ok := executor.(ContextPathProvider); ok {
+			pathKeys = append([]string{}, provider.ContextPathKeys()...)
+		}
+		var configProblems []string
+		if provider, ok := executor.(SchemaProvider); ok {
+			configProblems = checkSchema(task.Config, provider.Schema().Config, "config")
+		}
+		for _, problem := range configProblems {
+			addf("has invalid config: %s", problem)
+		}
+		if validator, ok := executor.(ConfigValidator); ok && len(configProblems) == 0 {
+			if err := validator.ValidateConfig(task.Config); err != nil {
+				addf("has invalid config: %v", err)
+			}
+		}
+	}
+
+	if task.Timeout < 0 {
+		addf("timeout must not be negative")
+	}
+	if task.Retry != nil {
+		if err := task.Retry.validate(); err != nil {
+			addf("has invalid retry policy: %v", err)
+		}
+	}
+
+	var refs []string
+	if task.When != "" {
+		paths, err := conditionPaths(task.When)
+		if err != nil {
+			addf("has an invalid when condition: %v", err)
+		}
+		refs = append(refs, paths...)
+	}
+	if task.ForEach != nil {
+		if task.ForEach.Items == "" {
+			addf("foreach has no items")
+		}
+		if task.ForEach.Parallelism < 0 {
+			addf("foreach parallelism must not be negative")
+		}
+		refs = append(refs, task.ForEach.Items)
+	}
+
+	for _, id := range resultReferences(refs, task.Config, pathKeys) {
+		if !taskIDs[id] {
+			addf("references '%s_result' but the workflow has no task '%s'", id, id)
+		}
+	}
+	return problems
+}
+
+// validateInputs checks the declared workflow inputs.
+func validateInputs(inputs []InputParameter) []string {
+	var problems []string
+	seen := make(map[string]bool, len(inputs))
+	for i, param := range inputs {
+		if param.Name == "" {
+			problems = append(problems, fmt.Sprintf("input %d has no name", i))
+			continue
+		}
+		if seen[param.Name] {
+			problems = append(problems, fmt.Sprintf("duplicate input '%s'", param.Name))
+		}
+		seen[param.Name] = true
+
+		if !inputTypes[param.Type] {
+			problems = append(problems, fmt.Sprintf("input '%s' has unsupported type '%s'", param.Name, param.Type))
+		} else if param.Default != nil && !hasInputType(param.Default, param.Type) {
+			problems = append(problems, fmt.Sprintf("input '%s' default must be of type %s", param.Name, param.Type))
+		}
+	}
+	return problems
+}
+
+// resultReferences returns the task IDs whose <id>_result key is referenced
+// by the given context paths, by templates anywhere in config, or by the
+// string values under pathKeys in config (all of them if pathKeys is nil),
+// in order of appearance and without duplicates.
+func resultReferences(paths []string, config map[string]interface{}, pathKeys []string) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for _, path := range paths {
+		if m := resultPathPattern.FindStringSubmatch(path); m != nil {
+			add(m[1])
+		}
+	}
+
+	var walk func(value interface{}, isPath bool)
+	walk = func(value interface{}, isPath bool) {
+		switch v := value.(type) {
+		case string:
+			if strings.Contains(v, "{{") {
+				for _, m := range resultTemplatePattern.FindAllStringSubmatch(v, -1) {
+					add(m[1])
+				}
+			} else if m := resultPathPattern.FindStringSubmatch(v); m != nil && isPath {
+				add(m[1])
+			}
+		case map[string]interface{}:
+			for _, key := range sortedKeys(v) {
+				walk(v[key], isPath)
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item, isPath)
+			}
+		}
+	}
+	isPathKey := make(map[string]bool, len(pathKeys))
+	for _, key := range pathKeys {
+		isPathKey[key] = true
+	}
+	for _, key := range sortedKeys(config) {
+		walk(config[key], pathKeys == nil || isPathKey[key])
+	}
+	return ids
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// hasUniqueIDs reports whether every task has a distinct, non-empty ID.
+func hasUniqueIDs(tasks []Task) bool {
+	seen := make(map[string]bool, len(tasks))
+	for _, task := range tasks {
+		if task.ID == "" || seen[task.ID] {
+			return false
+		}
+		seen[task.ID] = true
+	}
+	return true
+}
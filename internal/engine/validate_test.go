@@ -0,0 +1,221 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validatingExecutor rejects configs without a "url".
+type validatingExecutor struct {
+	MockExecutor
+}
+
+func (v *validatingExecutor) ValidateConfig(config map[string]interface{}) error {
+	if _, ok := config["url"].(string); !ok {
+		return errors.New("missing or invalid 'url' in configuration")
+	}
+	return nil
+}
+
+func validationRegistry() *Registry {
+	registry := NewRegistry()
+	registry.Register("mock", &MockExecutor{})
+	registry.Register("fetch", &validatingExecutor{})
+	return registry
+}
+
+func validationProblems(t *testing.T, workflow WorkflowDefinition) []string {
+	t.Helper()
+	err := Validate(workflow, validationRegistry())
+	require.Error(t, err)
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	return validationErr.Problems
+}
+
+func TestValidate_ValidWorkflow(t *testing.T) {
+	err := Validate(WorkflowDefinition{
+		Name:   "valid",
+		Inputs: []InputParameter{{Name: "url", Type: "string", Default: "http://example.com"}},
+		Tasks: []Task{
+			{ID: "fetch", Type: "fetch", Config: map[string]interface{}{"url": "{{.context.input.url}}"}},
+			{
+				ID:        "details",
+				Type:      "fetch",
+				Config:    map[string]interface{}{"url": "{{.context.item}}", "source": "fetch_result.body"},
+				DependsOn: []string{"fetch"},
+				When:      "fetch_result.status_code == 200",
+				ForEach:   &ForEach{Items: "fetch_result.body.links"},
+				Retry:     &RetryPolicy{MaxAttempts: 3, Backoff: BackoffExponential},
+			},
+		},
+		OnFailure: []Task{
+			{ID: "notify", Type: "mock", Config: map[string]interface{}{"message": "{{.context.failure.error}}"}},
+		},
+	}, validationRegistry())
+
+	assert.NoError(t, err)
+}
+
+func TestValidate_ReportsAllProblems(t *testing.T) {
+	problems := validationProblems(t, WorkflowDefinition{
+		Name: "broken",
+		Tasks: []Task{
+			{ID: "fetch", Type: "fetch", Config: map[string]interface{}{}},
+			{ID: "fetch", Type: "mock", Config: map[string]interface{}{}},
+			{ID: "parse", Type: "html", Config: map[string]interface{}{}},
+		},
+	})
+
+	assert.Equal(t, []string{
+		"duplicate task id 'fetch'",
+		"task 'fetch' has invalid config: missing or invalid 'url' in configuration",
+		"task 'parse' has unknown type 'html'",
+	}, problems)
+}
+
+func TestValidate_TaskProblems(t *testing.T) {
+	tests := []struct {
+		name    string
+		task    Task
+		problem string
+	}{
+		{"missing id", Task{Type: "mock"}, "task 0 has no id"},
+		{"missing type", Task{ID: "a"}, "task 'a' has no type"},
+		{"invalid when", Task{ID: "a", Type: "mock", When: "x =="}, "task 'a' has an invalid when condition"},
+		{"foreach without items", Task{ID: "a", Type: "mock", ForEach: &ForEach{}}, "task 'a' foreach has no items"},
+		{"bad backoff", Task{ID: "a", Type: "mock", Retry: &RetryPolicy{Backoff: "linear"}}, "unknown backoff 'linear'"},
+		{"negative timeout", Task{ID: "a", Type: "mock", Timeout: -1}, "task 'a' timeout must not be negative"},
+		{"unknown dependency", Task{ID: "a", Type: "mock", DependsOn: []string{"b"}}, "task 'a' depends on unknown task 'b'"},
+		{
+			"unknown result in config",
+			Task{ID: "a", Type: "mock", Config: map[string]interface{}{"data_source": "missing_result"}},
+			"task 'a' references 'missing_result' but the workflow has no task 'missing'",
+		},
+		{
+			"unknown result in template",
+			Task{ID: "a", Type: "mock", Config: map[string]interface{}{"body": `{"token": "{{.context.login_result.token}}"}`}},
+			"task 'a' references 'login_result'",
+		},
+		{"unknown result in when", Task{ID: "a", Type: "mock", When: "check_result.ok"}, "task 'a' references 'check_result'"},
+		{"unknown result in foreach", Task{ID: "a", Type: "mock", ForEach: &ForEach{Items: "list_result"}}, "task 'a' references 'list_result'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := validationProblems(t, WorkflowDefinition{Name: "wf", Tasks: []Task{tt.task}})
+			require.Len(t, problems, 1)
+			assert.Contains(t, problems[0], tt.problem)
+		})
+	}
+}
+
+// pathExecutor only reads the context paths under "source".
+type pathExecutor struct {
+	MockExecutor
+}
+
+func (p *pathExecutor) ContextPathKeys() []string {
+	return []string{"source"}
+}
+
+func TestValidate_ContextPathKeys(t *testing.T) {
+	registry := validationRegistry()
+	registry.Register("path", &pathExecutor{})
+
+	err := Validate(WorkflowDefinition{
+		Name: "wf",
+		Tasks: []Task{{ID: "a", Type: "path", Config: map[string]interface{}{
+			"source":  map[string]interface{}{"token": "login_result.token"},
+			"results": "child_result.value",
+			"body":    "{{.context.fetch_result.body}}",
+		}}},
+	}, registry)
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []string{
+		"task 'a' references 'fetch_result' but the workflow has no task 'fetch'",
+		"task 'a' references 'login_result' but the workflow has no task 'login'",
+	}, validationErr.Problems)
+}
+
+func TestValidate_WorkflowProblems(t *testing.T) {
+	problems := validationProblems(t, WorkflowDefinition{
+		Name:        "wf",
+		Parallelism: -1,
+		Inputs: []InputParameter{
+			{Name: "url", Type: "string"},
+			{Name: "url", Type: "string"},
+			{Name: "limit", Type: "integer", Default: "ten"},
+		},
+		Tasks: []Task{
+			{ID: "a", Type: "mock", DependsOn: []string{"b"}},
+			{ID: "b", Type: "mock", DependsOn: []string{"a"}},
+		},
+	})
+
+	assert.Equal(t, []string{
+		"parallelism must not be negative",
+		"duplicate input 'url'",
+		"input 'limit' default must be of type integer",
+		"workflow contains a dependency cycle involving tasks: a, b",
+	}, problems)
+}
+
+func TestValidate_NilRegistrySkipsExecutorChecks(t *testing.T) {
+	err := Validate(WorkflowDefinition{
+		Name:  "wf",
+		Tasks: []Task{{ID: "a", Type: "anything", Config: map[string]interface{}{}}},
+	}, nil)
+
+	assert.NoError(t, err)
+}
+
+func TestValidate_ValidWorkflowRuns(t *testing.T) {
+	workflow := WorkflowDefinition{
+		Name: "wf",
+		Tasks: []Task{
+			{ID: "fetch", Type: "fetch", Config: map[string]interface{}{"url": "http://example.com"}},
+			{ID: "after", Type: "mock", Config: map[string]interface{}{"source": "fetch_result"}},
+		},
+	}
+	require.NoError(t, Validate(workflow, validationRegistry()))
+
+	_, err := NewEngine(validationRegistry()).Execute(context.Background(), workflow)
+	assert.NoError(t, err)
+}
+
+// schemaValidatingExecutor has both a schema and a ConfigValidator.
+type schemaValidatingExecutor struct {
+	validatingExecutor
+}
+
+func (s *schemaValidatingExecutor) Schema() TaskSchema {
+	return TaskSchema{Config: map[string]interface{}{
+		"type":       "object",
+		"required":   []string{"url"},
+		"properties": map[string]interface{}{"timeout": map[string]interface{}{"type": "integer"}},
+	}}
+}
+
+func TestValidate_ChecksConfigSchema(t *testing.T) {
+	registry := validationRegistry()
+	registry.Register("schema", &schemaValidatingExecutor{})
+
+	err := Validate(WorkflowDefinition{
+		Name:  "wf",
+		Tasks: []Task{{ID: "a", Type: "schema", Config: map[string]interface{}{"timeout": "soon"}}},
+	}, registry)
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, []string{
+		"task 'a' has invalid config: config.url is required",
+		"task 'a' has invalid config: config.timeout must be of type integer (got string)",
+	}, validationErr.Problems)
+}
@@ -0,0 +1,195 @@
+// Package metrics exposes Prometheus metrics for the engine, the execution
+// queue, outbound HTTP requests and the HTTP API.
+package metrics
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "automation_hub"
+
+// Metrics holds the collectors of one process. Each Metrics has its own
+// registry, so several can coexist (e.g. in tests).
+type Metrics struct {
+	registry *prometheus.Registry
+
+	executions   *prometheus.CounterVec
+	inFlight     prometheus.Gauge
+	taskDuration *prometheus.HistogramVec
+	taskRetries  *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	apiRequests  *prometheus.CounterVec
+	apiDuration  *prometheus.HistogramVec
+}
+
+// New creates Metrics with all collectors registered, including the Go
+// runtime and process collectors.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		executions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "executions_total",
+			Help:      "Finished workflow runs, including sub-workflows, by workflow and final status.",
+		}, []string{"workflow", "status"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "executions_in_flight",
+			Help:      "Workflow runs, including sub-workflows, currently executing in this process.",
+		}),
+		taskDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "task_duration_seconds",
+			Help:      "Duration of task attempts by task type and result status.",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300},
+		}, []string{"task_type", "status"}),
+		taskRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "task_retries_total",
+			Help:      "Task attempts after the first one, by task type.",
+		}, []string{"task_type"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of outbound http_request calls by host and status code (\"error\" if no response was received).",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"host", "status_code"}),
+		apiRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "api_requests_total",
+			Help:      "HTTP API requests by method, route and status code.",
+		}, []string{"method", "route", "status_code"}),
+		apiDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "api_request_duration_seconds",
+			Help:      "HTTP API request latency by method and route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.executions,
+		m.inFlight,
+		m.taskDuration,
+		m.taskRetries,
+		m.httpDuration,
+		m.apiRequests,
+		m.apiDuration,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Instrument records the runs and task attempts of e through a RunHook and a
+// Middleware. Call it before e starts executing workflows.
+func (m *Metrics) Instrument(e *engine.Engine) {
+	e.AddHook(&runHook{metrics: m})
+	e.Use(m.taskMiddleware)
+}
+
+// ObserveQueue exports the number of queued executions, read from q on every scrape.
+func (m *Metrics) ObserveQueue(q repository.ExecutionQueue) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "queue_depth",
+		Help:      "Executions waiting in the queue for a worker.",
+	}, func() float64 {
+		depth, err := q.Depth()
+		if err != nil {
+			// Errors are already logged by Depth
+			return math.NaN()
+		}
+		return float64(depth)
+	}))
+}
+
+// runHook counts runs as an engine.RunHook
+type runHook struct {
+	metrics *Metrics
+}
+
+func (h *runHook) BeforeRun(ctx context.Context, run *engine.RunInfo) (context.Context, error) {
+	h.metrics.inFlight.Inc()
+	return ctx, nil
+}
+
+func (h *runHook) AfterRun(ctx context.Context, run *engine.RunInfo) error {
+	h.metrics.inFlight.Dec()
+	h.metrics.executions.WithLabelValues(run.Workflow.Name, run.Status).Inc()
+	return nil
+}
+
+// taskMiddleware times task attempts and counts retries. Skipped tasks are
+// not recorded.
+func (m *Metrics) taskMiddleware(next engine.TaskHandler) engine.TaskHandler {
+	return func(ctx context.Context, call engine.TaskCall) engine.TaskResult {
+		if call.Skip {
+			return next(ctx, call)
+		}
+		if call.Attempt > 1 {
+			m.taskRetries.WithLabelValues(call.Task.Type).Inc()
+		}
+
+		start := time.Now()
+		result := next(ctx, call)
+		m.taskDuration.WithLabelValues(call.Task.Type, result.Status).Observe(time.Since(start).Seconds())
+		return result
+	}
+}
+
+// InstrumentTransport wraps next (http.DefaultTransport if nil) so that the
+// latency of every outbound request is recorded.
+func (m *Metrics) InstrumentTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next.RoundTrip(req)
+		status := "error"
+		if err == nil {
+			status = strconv.Itoa(resp.StatusCode)
+		}
+		m.httpDuration.WithLabelValues(req.URL.Host, status).Observe(time.Since(start).Seconds())
+		return resp, err
+	})
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// GinMiddleware records API requests. Routes are labelled by their pattern
+// (e.g. /executions/:id), or "unmatched" for requests that matched no route.
+func (m *Metrics) GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.apiRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		m.apiDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,133 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// scrape returns the metrics exposed by m in the Prometheus text format
+func scrape(t *testing.T, m *Metrics) string {
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	return w.Body.String()
+}
+
+// flakyExecutor fails its first call and succeeds afterwards
+type flakyExecutor struct {
+	calls int32
+}
+
+func (f *flakyExecutor) Execute(ctx context.Context, execCtx *engine.ExecutionContext, config map[string]interface{}) engine.TaskResult {
+	if atomic.AddInt32(&f.calls, 1) == 1 {
+		return engine.TaskResult{Status: "failed", Error: "temporary"}
+	}
+	return engine.TaskResult{Status: "success"}
+}
+
+// fixedQueue is an ExecutionQueue reporting a fixed depth
+type fixedQueue struct {
+	repository.ExecutionQueue
+	depth int64
+}
+
+func (q *fixedQueue) Depth() (int64, error) {
+	return q.depth, nil
+}
+
+func TestInstrument_RecordsRunsAndTasks(t *testing.T) {
+	registry := engine.NewRegistry()
+	registry.Register("flaky", &flakyExecutor{})
+	registry.Register("mock", &engine.MockExecutor{ShouldFail: true, ErrorMsg: "boom"})
+	executionEngine := engine.NewEngine(registry)
+	m := New()
+	m.Instrument(executionEngine)
+
+	_, err := executionEngine.Execute(context.Background(), engine.WorkflowDefinition{
+		Name: "sync",
+		Tasks: []engine.Task{
+			{ID: "fetch", Type: "flaky", Retry: &engine.RetryPolicy{MaxAttempts: 2, DelayMs: 1}},
+			{ID: "skipped", Type: "mock", When: "fetch_result == 'never'"},
+		},
+	})
+	require.NoError(t, err)
+	_, err = executionEngine.Execute(context.Background(), engine.WorkflowDefinition{
+		Name:  "broken",
+		Tasks: []engine.Task{{ID: "fail", Type: "mock"}},
+	})
+	require.Error(t, err)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `automation_hub_executions_total{status="completed",workflow="sync"} 1`)
+	assert.Contains(t, body, `automation_hub_executions_total{status="failed",workflow="broken"} 1`)
+	assert.Contains(t, body, `automation_hub_executions_in_flight 0`)
+	assert.Contains(t, body, `automation_hub_task_retries_total{task_type="flaky"} 1`)
+	assert.Contains(t, body, `automation_hub_task_duration_seconds_count{status="failed",task_type="flaky"} 1`)
+	assert.Contains(t, body, `automation_hub_task_duration_seconds_count{status="success",task_type="flaky"} 1`)
+	assert.Contains(t, body, `automation_hub_task_duration_seconds_count{status="failed",task_type="mock"} 1`)
+	assert.NotContains(t, body, `status="skipped"`)
+}
+
+func TestInstrumentTransport_RecordsLatencyByHostAndStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	m := New()
+	client := &http.Client{Transport: m.InstrumentTransport(nil), Timeout: time.Second}
+	resp, err := client.Get(server.URL + "/brew")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	_, err = client.Get(closed.URL)
+	require.Error(t, err)
+
+	body := scrape(t, m)
+	host := strings.TrimPrefix(server.URL, "http://")
+	assert.Contains(t, body, `automation_hub_http_request_duration_seconds_count{host="`+host+`",status_code="418"} 1`)
+	assert.Contains(t, body, `automation_hub_http_request_duration_seconds_count{host="`+strings.TrimPrefix(closed.URL, "http://")+`",status_code="error"} 1`)
+}
+
+func TestGinMiddleware_RecordsRequestsByRoute(t *testing.T) {
+	m := New()
+	router := gin.New()
+	router.Use(m.GinMiddleware())
+	router.GET("/items/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	for _, path := range []string{"/items/1", "/items/2", "/missing/" + uuid.NewString()} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `automation_hub_api_requests_total{method="GET",route="/items/:id",status_code="204"} 2`)
+	assert.Contains(t, body, `automation_hub_api_requests_total{method="GET",route="unmatched",status_code="404"} 1`)
+	assert.Contains(t, body, `automation_hub_api_request_duration_seconds_count{method="GET",route="/items/:id"} 2`)
+}
+
+func TestObserveQueue_ReportsDepth(t *testing.T) {
+	m := New()
+	m.ObserveQueue(&fixedQueue{depth: 3})
+
+	assert.Contains(t, scrape(t, m), "automation_hub_queue_depth 3")
+}
@@ -0,0 +1,161 @@
+// Package queue runs queued executions on a bounded pool of workers.
+package queue
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultPoolSize is the number of executions a Pool runs at once
+	DefaultPoolSize = 4
+	// DefaultPollInterval is how often idle workers look for queued executions
+	DefaultPollInterval = time.Second
+	// DefaultHeartbeatInterval is how often a running execution's heartbeat is refreshed
+	DefaultHeartbeatInterval = 10 * time.Second
+	// DefaultStaleAfter is how old a heartbeat may get before the execution
+	// is considered orphaned and requeued
+	DefaultStaleAfter = time.Minute
+)
+
+// Handler runs a claimed execution to completion, recording its outcome
+type Handler func(ctx context.Context, execution *repository.Execution)
+
+// Config configures a Pool. Zero values select the defaults above.
+type Config struct {
+	Size              int
+	PollInterval      time.Duration
+	HeartbeatInterval time.Duration
+	StaleAfter        time.Duration
+	// Cancel stops a running execution whose cancellation was requested
+	// through the queue, usually engine.Engine.Cancel. It returns false if
+	// the execution isn't running yet, and is called again on the next
+	// heartbeat. Requests are ignored when Cancel is nil.
+	Cancel func(executionID uuid.UUID) bool
+}
+
+// Pool runs queued executions on a fixed number of workers. Any number of
+// pools, in any number of processes, can share one ExecutionQueue.
+type Pool struct {
+	queue    repository.ExecutionQueue
+	handle   Handler
+	config   Config
+	workerID string
+}
+
+// NewPool creates a Pool that runs executions claimed from queue with handle
+func NewPool(queue repository.ExecutionQueue, handle Handler, config Config) *Pool {
+	if config.Size < 1 {
+		config.Size = DefaultPoolSize
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+	if config.HeartbeatInterval <= 0 {
+		config.HeartbeatInterval = DefaultHeartbeatInterval
+	}
+	if config.StaleAfter <= 0 {
+		config.StaleAfter = DefaultStaleAfter
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "worker"
+	}
+	return &Pool{
+		queue:    queue,
+		handle:   handle,
+		config:   config,
+		workerID: fmt.Sprintf("%s-%s", host, uuid.NewString()[:8]),
+	}
+}
+
+// Run requeues executions orphaned by crashed workers, then runs queued
+// executions until ctx is cancelled. Stale executions are looked for again
+// every StaleAfter, since other replicas may crash too. Run returns once every
+// in-flight execution has finished; executions are not interrupted when ctx
+// is cancelled.
+func (p *Pool) Run(ctx context.Context) {
+	slog.Info("Starting worker pool", "worker_id", p.workerID, "size", p.config.Size)
+	p.requeueStale()
+
+	var wg sync.WaitGroup
+	for i := 0; i < p.config.Size; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx)
+		}()
+	}
+
+	ticker := time.NewTicker(p.config.StaleAfter)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			slog.Info("Worker pool stopped", "worker_id", p.workerID)
+			return
+		case <-ticker.C:
+			p.requeueStale()
+		}
+	}
+}
+
+// work claims and runs executions until ctx is cancelled
+func (p *Pool) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		execution, err := p.queue.Claim(p.workerID)
+		if err == nil && execution != nil {
+			p.process(ctx, execution)
+			continue
+		}
+
+		// Queue empty or unavailable; wait before polling again
+		select {
+		case <-ctx.Done():
+		case <-time.After(p.config.PollInterval):
+		}
+	}
+}
+
+// process runs a claimed execution, refreshing its heartbeat until it
+// finishes and cancelling it if that is requested
+func (p *Pool) process(ctx context.Context, execution *repository.Execution) {
+	done := make(chan struct{})
+	heartbeatStopped := make(chan struct{})
+	go func() {
+		defer close(heartbeatStopped)
+		ticker := time.NewTicker(p.config.HeartbeatInterval)
+		defer ticker.Stop()
+		cancelled := false
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// A failed heartbeat is retried on the next tick
+				cancelRequested, err := p.queue.Heartbeat(execution.ID, p.workerID)
+				if err == nil && cancelRequested && !cancelled && p.config.Cancel != nil {
+					cancelled = p.config.Cancel(execution.ID)
+				}
+			}
+		}
+	}()
+
+	p.handle(context.WithoutCancel(ctx), execution)
+	close(done)
+	<-heartbeatStopped
+}
+
+// requeueStale puts executions whose worker stopped heartbeating back in the queue
+func (p *Pool) requeueStale() {
+	// Errors are already logged by RequeueStale
+	_, _ = p.queue.RequeueStale(time.Now().UTC().Add(-p.config.StaleAfter))
+}
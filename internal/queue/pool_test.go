@@ -0,0 +1,284 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryQueue is an in-memory ExecutionQueue for tests
+type memoryQueue struct {
+	mu         sync.Mutex
+	order      []uuid.UUID
+	executions map[uuid.UUID]*repository.Execution
+	heartbeats int
+}
+
+func newMemoryQueue() *memoryQueue {
+	return &memoryQueue{executions: make(map[uuid.UUID]*repository.Execution)}
+}
+
+func (q *memoryQueue) add(status string, heartbeatAt *time.Time) uuid.UUID {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	id := uuid.New()
+	q.order = append(q.order, id)
+	q.executions[id] = &repository.Execution{ID: id, Status: status, HeartbeatAt: heartbeatAt}
+	return id
+}
+
+func (q *memoryQueue) status(id uuid.UUID) string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.executions[id].Status
+}
+
+func (q *memoryQueue) Claim(workerID string) (*repository.Execution, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, id := range q.order {
+		execution := q.executions[id]
+		if execution.Status == "pending" {
+			now := time.Now()
+			execution.Status = "running"
+			execution.ClaimedBy = workerID
+			execution.HeartbeatAt = &now
+			claimed := *execution
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (q *memoryQueue) Heartbeat(id uuid.UUID, workerID string) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	q.executions[id].HeartbeatAt = &now
+	q.heartbeats++
+	return q.executions[id].CancelRequestedAt != nil, nil
+}
+
+func (q *memoryQueue) RequeueStale(before time.Time) ([]uuid.UUID, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var ids []uuid.UUID
+	for _, id := range q.order {
+		execution := q.executions[id]
+		if execution.Status == "running" && (execution.HeartbeatAt == nil || execution.HeartbeatAt.Before(before)) {
+			if execution.CancelRequestedAt != nil {
+				execution.Status = "cancelled"
+				continue
+			}
+			execution.Status = "pending"
+			execution.HeartbeatAt = nil
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (q *memoryQueue) CancelPending(id uuid.UUID) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.executions[id].Status != "pending" {
+		return false, nil
+	}
+	q.executions[id].Status = "cancelled"
+	return true, nil
+}
+
+func (q *memoryQueue) RequestCancel(id uuid.UUID) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.executions[id].Status != "running" {
+		return false, nil
+	}
+	now := time.Now()
+	q.executions[id].CancelRequestedAt = &now
+	return true, nil
+}
+
+func (q *memoryQueue) Depth() (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var depth int64
+	for _, execution := range q.executions {
+		if execution.Status == "pending" {
+			depth++
+		}
+	}
+	return depth, nil
+}
+
+// complete returns a Handler that marks executions completed after delay,
+// tracking how many run at once.
+func complete(q *memoryQueue, delay time.Duration, running, maxRunning *int32) Handler {
+	return func(ctx context.Context, execution *repository.Execution) {
+		n := atomic.AddInt32(running, 1)
+		for {
+			max := atomic.LoadInt32(maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(maxRunning, max, n) {
+				break
+			}
+		}
+		time.Sleep(delay)
+		atomic.AddInt32(running, -1)
+
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.executions[execution.ID].Status = "completed"
+	}
+}
+
+func TestPool_RunsQueuedExecutionsWithBoundedConcurrency(t *testing.T) {
+	q := newMemoryQueue()
+	var ids []uuid.UUID
+	for i := 0; i < 6; i++ {
+		ids = append(ids, q.add("pending", nil))
+	}
+
+	var running, maxRunning int32
+	pool := NewPool(q, complete(q, 20*time.Millisecond, &running, &maxRunning), Config{Size: 2, PollInterval: 5 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pool.Run(ctx)
+
+	assert.Eventually(t, func() bool {
+		for _, id := range ids {
+			if q.status(id) != "completed" {
+				return false
+			}
+		}
+		return true
+	}, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxRunning))
+}
+
+func TestPool_PicksUpExecutionsQueuedLater(t *testing.T) {
+	q := newMemoryQueue()
+	var running, maxRunning int32
+	pool := NewPool(q, complete(q, 0, &running, &maxRunning), Config{Size: 1, PollInterval: 5 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pool.Run(ctx)
+
+	time.Sleep(20 * time.Millisecond)
+	id := q.add("pending", nil)
+
+	assert.Eventually(t, func() bool { return q.status(id) == "completed" }, time.Second, 5*time.Millisecond)
+}
+
+func TestPool_RequeuesStaleExecutionsOnStart(t *testing.T) {
+	q := newMemoryQueue()
+	expired := time.Now().Add(-2 * time.Minute)
+	fresh := time.Now()
+	stale := q.add("running", &expired)
+	orphaned := q.add("running", nil)
+	alive := q.add("running", &fresh)
+
+	var running, maxRunning int32
+	pool := NewPool(q, complete(q, 0, &running, &maxRunning), Config{Size: 1, PollInterval: 5 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pool.Run(ctx)
+
+	assert.Eventually(t, func() bool {
+		return q.status(stale) == "completed" && q.status(orphaned) == "completed"
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "running", q.status(alive))
+}
+
+func TestPool_HeartbeatsWhileRunning(t *testing.T) {
+	q := newMemoryQueue()
+	id := q.add("pending", nil)
+
+	release := make(chan struct{})
+	handle := func(ctx context.Context, execution *repository.Execution) {
+		<-release
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.executions[execution.ID].Status = "completed"
+	}
+	pool := NewPool(q, handle, Config{Size: 1, PollInterval: 5 * time.Millisecond, HeartbeatInterval: 5 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pool.Run(ctx)
+
+	assert.Eventually(t, func() bool {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		return q.heartbeats >= 3
+	}, time.Second, 5*time.Millisecond)
+	close(release)
+	assert.Eventually(t, func() bool { return q.status(id) == "completed" }, time.Second, 5*time.Millisecond)
+}
+
+func TestPool_CancelsRequestedExecutions(t *testing.T) {
+	q := newMemoryQueue()
+	id := q.add("pending", nil)
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	handle := func(ctx context.Context, execution *repository.Execution) {
+		close(started)
+		<-cancelled
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.executions[execution.ID].Status = "cancelled"
+	}
+	var cancels int32
+	cancelExecution := func(executionID uuid.UUID) bool {
+		assert.Equal(t, id, executionID)
+		if atomic.AddInt32(&cancels, 1) == 1 {
+			close(cancelled)
+		}
+		return true
+	}
+	pool := NewPool(q, handle, Config{Size: 1, PollInterval: 5 * time.Millisecond, HeartbeatInterval: 5 * time.Millisecond, Cancel: cancelExecution})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pool.Run(ctx)
+
+	<-started
+	requested, err := q.RequestCancel(id)
+	assert.NoError(t, err)
+	assert.True(t, requested)
+
+	assert.Eventually(t, func() bool { return q.status(id) == "cancelled" }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&cancels))
+}
+
+func TestPool_StopWaitsForInFlightExecutions(t *testing.T) {
+	q := newMemoryQueue()
+	id := q.add("pending", nil)
+
+	started := make(chan struct{})
+	handle := func(ctx context.Context, execution *repository.Execution) {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		// The execution is not interrupted when the pool stops
+		assert.NoError(t, ctx.Err())
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.executions[execution.ID].Status = "completed"
+	}
+	pool := NewPool(q, handle, Config{Size: 1, PollInterval: 5 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		pool.Run(ctx)
+		close(stopped)
+	}()
+
+	<-started
+	cancel()
+	<-stopped
+	assert.Equal(t, "completed", q.status(id))
+}
@@ -85,7 +85,7 @@ func InitDB() error {
 func AutoMigrate() error {
 	slog.Info("Running database migrations")
 
-	if err := DB.AutoMigrate(&Workflow{}, &Execution{}, &TaskLog{}); err != nil {
+	if err := DB.AutoMigrate(&Workflow{}, &Execution{}, &TaskLog{}, &Schedule{}, &WebhookTrigger{}, &Secret{}); err != nil {
 		return fmt.Errorf("migration failed: %w", err)
 	}
 
@@ -21,12 +21,14 @@ func NewExecutionLoggerAdapter(execRepo ExecutionRepository, taskLogRepo TaskLog
 // CreateExecution creates an execution record
 func (a *ExecutionLoggerAdapter) CreateExecution(exec *engine.ExecutionRecord) error {
 	execution := &Execution{
-		ID:              exec.ID,
-		WorkflowID:      exec.WorkflowID,
-		Status:          exec.Status,
-		ContextSnapshot: exec.ContextSnapshot,
-		StartedAt:       exec.StartedAt,
-		CompletedAt:     exec.CompletedAt,
+		ID:                exec.ID,
+		WorkflowID:        exec.WorkflowID,
+		ParentExecutionID: exec.ParentExecutionID,
+		ResumedFromID:     exec.ResumedFromID,
+		Status:            exec.Status,
+		ContextSnapshot:   exec.ContextSnapshot,
+		StartedAt:         exec.StartedAt,
+		CompletedAt:       exec.CompletedAt,
 	}
 	return a.execRepo.Create(execution)
 }
@@ -34,12 +36,14 @@ func (a *ExecutionLoggerAdapter) CreateExecution(exec *engine.ExecutionRecord) e
 // UpdateExecution updates an execution record
 func (a *ExecutionLoggerAdapter) UpdateExecution(exec *engine.ExecutionRecord) error {
 	execution := &Execution{
-		ID:              exec.ID,
-		WorkflowID:      exec.WorkflowID,
-		Status:          exec.Status,
-		ContextSnapshot: exec.ContextSnapshot,
-		StartedAt:      exec.StartedAt,
-		CompletedAt:    exec.CompletedAt,
+		ID:                exec.ID,
+		WorkflowID:        exec.WorkflowID,
+		ParentExecutionID: exec.ParentExecutionID,
+		ResumedFromID:     exec.ResumedFromID,
+		Status:            exec.Status,
+		ContextSnapshot:   exec.ContextSnapshot,
+		StartedAt:         exec.StartedAt,
+		CompletedAt:       exec.CompletedAt,
 	}
 	return a.execRepo.Update(execution)
 }
@@ -52,6 +56,7 @@ func (a *ExecutionLoggerAdapter) CreateTaskLog(taskLog *engine.TaskLogRecord) er
 		TaskID:      taskLog.TaskID,
 		TaskType:    taskLog.TaskType,
 		Status:      taskLog.Status,
+		Attempt:     taskLog.Attempt,
 		Input:       taskLog.Input,
 		Output:      taskLog.Output,
 		Error:       taskLog.Error,
@@ -69,6 +74,7 @@ func (a *ExecutionLoggerAdapter) UpdateTaskLog(taskLog *engine.TaskLogRecord) er
 		TaskID:      taskLog.TaskID,
 		TaskType:    taskLog.TaskType,
 		Status:      taskLog.Status,
+		Attempt:     taskLog.Attempt,
 		Input:       taskLog.Input,
 		Output:      taskLog.Output,
 		Error:       taskLog.Error,
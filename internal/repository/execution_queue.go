@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExecutionQueue treats pending top-level executions as a job queue. Rows are
+// claimed with FOR UPDATE SKIP LOCKED, so several API replicas can share the
+// queue without running an execution twice.
+type ExecutionQueue interface {
+	// Claim marks the oldest pending execution as running on workerID and
+	// returns it, or returns nil if the queue is empty.
+	Claim(workerID string) (*Execution, error)
+	// Heartbeat records that workerID is still running the execution and
+	// reports whether its cancellation was requested with RequestCancel.
+	Heartbeat(id uuid.UUID, workerID string) (bool, error)
+	// RequeueStale puts running executions whose heartbeat is older than
+	// before back in the queue, failing any sub-workflow executions they had
+	// in flight. Those whose cancellation was requested are cancelled
+	// instead. It returns the IDs of the requeued executions.
+	RequeueStale(before time.Time) ([]uuid.UUID, error)
+	// CancelPending marks an execution that no worker has claimed yet as
+	// cancelled. It returns false if the execution is no longer pending.
+	CancelPending(id uuid.UUID) (bool, error)
+	// RequestCancel asks the worker running a claimed execution, on whichever
+	// replica, to cancel it; the worker sees the request on its next
+	// heartbeat. It returns false if the execution is not running.
+	RequestCancel(id uuid.UUID) (bool, error)
+	// Depth returns the number of executions waiting to be claimed.
+	Depth() (int64, error)
+}
+
+// GormExecutionQueue implements ExecutionQueue on the executions table
+type GormExecutionQueue struct {
+	db *gorm.DB
+}
+
+// NewExecutionQueue creates a new execution queue
+func NewExecutionQueue(db *gorm.DB) ExecutionQueue {
+	return &GormExecutionQueue{db: db}
+}
+
+// Claim marks the oldest pending execution as running on workerID
+func (q *GormExecutionQueue) Claim(workerID string) (*Execution, error) {
+	var claimed *Execution
+	err := q.db.Transaction(func(tx *gorm.DB) error {
+		var execution Execution
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND parent_execution_id IS NULL", "pending").
+			Order("created_at").
+			Limit(1).
+			Find(&execution)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		now := time.Now().UTC()
+		if err := tx.Model(&Execution{}).Where("id = ?", execution.ID).Updates(map[string]interface{}{
+			"status":       "running",
+			"claimed_by":   workerID,
+			"heartbeat_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		execution.Status = "running"
+		execution.ClaimedBy = workerID
+		execution.HeartbeatAt = &now
+		claimed = &execution
+		return nil
+	})
+	if err != nil {
+		slog.Error("Failed to claim execution", "error", err, "worker", workerID)
+		return nil, fmt.Errorf("failed to claim execution: %w", err)
+	}
+
+	if claimed != nil {
+		slog.Info("Execution claimed", "id", claimed.ID, "worker", workerID)
+	}
+	return claimed, nil
+}
+
+// Heartbeat records that workerID is still running the execution and
+// returns whether its cancellation was requested
+func (q *GormExecutionQueue) Heartbeat(id uuid.UUID, workerID string) (bool, error) {
+	var execution Execution
+	if err := q.db.Model(&execution).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "cancel_requested_at"}}}).
+		Where("id = ? AND claimed_by = ?", id, workerID).
+		Update("heartbeat_at", time.Now().UTC()).Error; err != nil {
+		slog.Error("Failed to record execution heartbeat", "error", err, "id", id)
+		return false, fmt.Errorf("failed to record execution heartbeat: %w", err)
+	}
+	return execution.CancelRequestedAt != nil, nil
+}
+
+// RequeueStale puts running executions whose heartbeat is older than before
+// back in the queue. Executions without a heartbeat were started before the
+// queue existed and are requeued too.
+func (q *GormExecutionQueue) RequeueStale(before time.Time) ([]uuid.UUID, error) {
+	var requeued, cancelled []uuid.UUID
+	err := q.db.Transaction(func(tx *gorm.DB) error {
+		var stale []Execution
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Select("id", "cancel_requested_at").
+			Where("status = ? AND parent_execution_id IS NULL AND (heartbeat_at IS NULL OR heartbeat_at < ?)", "running", before).
+			Find(&stale).Error; err != nil {
+			return err
+		}
+		if len(stale) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(stale))
+		for _, execution := range stale {
+			ids = append(ids, execution.ID)
+			if execution.CancelRequestedAt != nil {
+				cancelled = append(cancelled, execution.ID)
+			} else {
+				requeued = append(requeued, execution.ID)
+			}
+		}
+
+		now := time.Now().UTC()
+		if len(requeued) > 0 {
+			if err := tx.Model(&Execution{}).Where("id IN ?", requeued).Updates(map[string]interface{}{
+				"status":       "pending",
+				"claimed_by":   "",
+				"heartbeat_at": nil,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		// Their cancellation was requested; there is no point running them again
+		if len(cancelled) > 0 {
+			if err := tx.Model(&Execution{}).Where("id IN ?", cancelled).Updates(map[string]interface{}{
+				"status":       "cancelled",
+				"completed_at": now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		// Sub-workflows run inside their parent's worker, so they died with it
+		return tx.Model(&Execution{}).
+			Where("parent_execution_id IN ? AND status IN ?", ids, []string{"pending", "running"}).
+			Updates(map[string]interface{}{
+				"status":       "failed",
+				"completed_at": now,
+			}).Error
+	})
+	if err != nil {
+		slog.Error("Failed to requeue stale executions", "error", err)
+		return nil, fmt.Errorf("failed to requeue stale executions: %w", err)
+	}
+
+	if len(requeued) > 0 {
+		slog.Warn("Requeued stale executions", "count", len(requeued), "ids", requeued)
+	}
+	if len(cancelled) > 0 {
+		slog.Warn("Cancelled stale executions", "count", len(cancelled), "ids", cancelled)
+	}
+	return requeued, nil
+}
+
+// CancelPending marks an execution that no worker has claimed yet as cancelled
+func (q *GormExecutionQueue) CancelPending(id uuid.UUID) (bool, error) {
+	result := q.db.Model(&Execution{}).
+		Where("id = ? AND status = ?", id, "pending").
+		Updates(map[string]interface{}{
+			"status":       "cancelled",
+			"completed_at": time.Now().UTC(),
+		})
+	if result.Error != nil {
+		slog.Error("Failed to cancel pending execution", "error", result.Error, "id", id)
+		return false, fmt.Errorf("failed to cancel pending execution: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RequestCancel records a cancellation request for a running top-level
+// execution; sub-workflow executions are cancelled with their parent
+func (q *GormExecutionQueue) RequestCancel(id uuid.UUID) (bool, error) {
+	result := q.db.Model(&Execution{}).
+		Where("id = ? AND status = ? AND parent_execution_id IS NULL", id, "running").
+		Update("cancel_requested_at", time.Now().UTC())
+	if result.Error != nil {
+		slog.Error("Failed to request execution cancellation", "error", result.Error, "id", id)
+		return false, fmt.Errorf("failed to request execution cancellation: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Depth returns the number of executions waiting to be claimed
+func (q *GormExecutionQueue) Depth() (int64, error) {
+	var depth int64
+	if err := q.db.Model(&Execution{}).
+		Where("status = ? AND parent_execution_id IS NULL", "pending").
+		Count(&depth).Error; err != nil {
+		slog.Error("Failed to count queued executions", "error", err)
+		return 0, fmt.Errorf("failed to count queued executions: %w", err)
+	}
+	return depth, nil
+}
@@ -95,11 +95,13 @@ func (r *GormExecutionRepository) GetAll() ([]*Execution, error) {
 	return executions, nil
 }
 
-// Update updates an existing execution
+// Update updates an existing execution. The queue columns (input, trigger,
+// claimed_by, heartbeat_at and cancel_requested_at) are left alone;
+// ExecutionQueue manages them.
 func (r *GormExecutionRepository) Update(execution *Execution) error {
 	slog.Info("Updating execution", "id", execution.ID, "status", execution.Status)
 
-	if err := r.db.Save(execution).Error; err != nil {
+	if err := r.db.Omit(queueColumns...).Save(execution).Error; err != nil {
 		slog.Error("Failed to update execution", "error", err, "id", execution.ID)
 		return fmt.Errorf("failed to update execution: %w", err)
 	}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -32,18 +33,29 @@ func (Workflow) TableName() string {
 
 // Execution represents a workflow execution in the database
 type Execution struct {
-	ID              uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
-	WorkflowID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"workflow_id"`
-	Workflow        Workflow       `gorm:"foreignKey:WorkflowID" json:"workflow,omitempty"`
-	Status          string         `gorm:"type:varchar(50);not null;default:'pending'" json:"status"` // pending, running, completed, failed
-	ContextSnapshot datatypes.JSON `gorm:"type:jsonb" json:"context_snapshot,omitempty"`
-	StartedAt       time.Time      `gorm:"not null" json:"started_at"`
-	CompletedAt     *time.Time     `gorm:"default:null" json:"completed_at,omitempty"`
-	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
-	TaskLogs        []TaskLog      `gorm:"foreignKey:ExecutionID" json:"task_logs,omitempty"`
+	ID                uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	WorkflowID        uuid.UUID      `gorm:"type:uuid;not null;index" json:"workflow_id"`
+	Workflow          Workflow       `gorm:"foreignKey:WorkflowID" json:"workflow,omitempty"`
+	ParentExecutionID *uuid.UUID     `gorm:"type:uuid;index" json:"parent_execution_id,omitempty"`      // set for sub-workflow executions
+	ResumedFromID     *uuid.UUID     `gorm:"type:uuid;index" json:"resumed_from_id,omitempty"`          // set for executions that resume a previous one
+	Status            string         `gorm:"type:varchar(50);not null;default:'pending'" json:"status"` // pending, running, completed, completed_with_errors, failed, cancelled, interrupted
+	ContextSnapshot   datatypes.JSON `gorm:"type:jsonb" json:"context_snapshot,omitempty"`
+	StartedAt         time.Time      `gorm:"not null" json:"started_at"`
+	CompletedAt       *time.Time     `gorm:"default:null" json:"completed_at,omitempty"`
+	Input             datatypes.JSON `gorm:"type:jsonb" json:"input,omitempty"`             // resolved input values, set when queued
+	Trigger           datatypes.JSON `gorm:"type:jsonb" json:"trigger,omitempty"`           // what started the execution, e.g. a webhook request
+	TraceContext      datatypes.JSON `gorm:"type:jsonb" json:"-"`                           // trace context of the request that queued the execution
+	ClaimedBy         string         `gorm:"type:varchar(255)" json:"claimed_by,omitempty"` // worker running the execution
+	HeartbeatAt       *time.Time     `gorm:"index" json:"heartbeat_at,omitempty"`           // refreshed while a worker runs the execution
+	CancelRequestedAt *time.Time     `json:"cancel_requested_at,omitempty"`                 // set to have the worker running the execution cancel it
+	CreatedAt         time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	TaskLogs          []TaskLog      `gorm:"foreignKey:ExecutionID" json:"task_logs,omitempty"`
 }
 
+// queueColumns are the Execution columns owned by ExecutionQueue
+var queueColumns = []string{"input", "trigger", "trace_context", "claimed_by", "heartbeat_at", "cancel_requested_at"}
+
 // BeforeCreate GORM hook to generate UUID
 func (e *Execution) BeforeCreate(tx *gorm.DB) error {
 	if e.ID == uuid.Nil {
@@ -64,7 +76,8 @@ type TaskLog struct {
 	Execution   Execution      `gorm:"foreignKey:ExecutionID" json:"execution,omitempty"`
 	TaskID      string         `gorm:"type:varchar(255);not null" json:"task_id"`
 	TaskType    string         `gorm:"type:varchar(100)" json:"task_type"`
-	Status      string         `gorm:"type:varchar(50);not null" json:"status"` // success, failed
+	Status      string         `gorm:"type:varchar(50);not null" json:"status"` // running, success, failed, cancelled, interrupted, skipped
+	Attempt     int            `gorm:"not null;default:1" json:"attempt"`
 	Input       datatypes.JSON `gorm:"type:jsonb" json:"input,omitempty"`
 	Output      datatypes.JSON `gorm:"type:jsonb" json:"output,omitempty"`
 	Error       string         `gorm:"type:text" json:"error,omitempty"`
@@ -85,3 +98,105 @@ func (t *TaskLog) BeforeCreate(tx *gorm.DB) error {
 func (TaskLog) TableName() string {
 	return "task_logs"
 }
+
+// Schedule policies
+const (
+	// MissedRunSkip drops fire times missed while the scheduler was not running
+	MissedRunSkip = "skip"
+	// MissedRunCatchUp runs once for every missed fire time
+	MissedRunCatchUp = "catch_up"
+	// OverlapSkip skips a fire time while the schedule's previous execution is still pending or running
+	OverlapSkip = "skip"
+	// OverlapAllow starts executions regardless of previous ones
+	OverlapAllow = "allow"
+)
+
+// Schedule runs a workflow on a cron expression
+type Schedule struct {
+	ID              uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	WorkflowID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"workflow_id"`
+	Workflow        Workflow       `gorm:"foreignKey:WorkflowID" json:"-"`
+	CronExpression  string         `gorm:"type:varchar(255);not null" json:"cron_expression"`
+	Timezone        string         `gorm:"type:varchar(100);not null;default:'UTC'" json:"timezone"`
+	Enabled         bool           `gorm:"not null" json:"enabled"`
+	Inputs          datatypes.JSON `gorm:"type:jsonb" json:"inputs,omitempty"`                                // default input values for each run
+	MissedRunPolicy string         `gorm:"type:varchar(50);not null;default:'skip'" json:"missed_run_policy"` // skip, catch_up
+	OverlapPolicy   string         `gorm:"type:varchar(50);not null;default:'skip'" json:"overlap_policy"`    // skip, allow
+	NextRunAt       *time.Time     `gorm:"index" json:"next_run_at,omitempty"`
+	LastRunAt       *time.Time     `json:"last_run_at,omitempty"`
+	LastExecutionID *uuid.UUID     `gorm:"type:uuid" json:"last_execution_id,omitempty"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// BeforeCreate GORM hook to generate UUID
+func (s *Schedule) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (Schedule) TableName() string {
+	return "schedules"
+}
+
+// WebhookTrigger starts a workflow when a request is POSTed to /hooks/:token
+type WebhookTrigger struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	WorkflowID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"workflow_id"`
+	Workflow        Workflow   `gorm:"foreignKey:WorkflowID" json:"-"`
+	Token           string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"token"`
+	SigningSecret   string     `gorm:"type:text" json:"-"` // when set, requests must carry an HMAC-SHA256 signature; sealed when secrets are configured
+	Enabled         bool       `gorm:"not null" json:"enabled"`
+	LastTriggeredAt *time.Time `json:"last_triggered_at,omitempty"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// BeforeCreate GORM hook to generate UUID
+func (w *WebhookTrigger) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (WebhookTrigger) TableName() string {
+	return "webhook_triggers"
+}
+
+// MarshalJSON leaves the signing secret out, reporting only whether there is one
+func (w WebhookTrigger) MarshalJSON() ([]byte, error) {
+	type webhookTrigger WebhookTrigger
+	return json.Marshal(struct {
+		webhookTrigger
+		HasSigningSecret bool `json:"has_signing_secret"`
+	}{webhookTrigger(w), w.SigningSecret != ""})
+}
+
+// Secret is a named value that task configs reference as {{secret "name"}}.
+// The value is stored encrypted with the master key and never returned by
+// the API; the name cannot change since it is part of the encryption.
+type Secret struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	Name      string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"name"`
+	Value     []byte    `gorm:"type:bytea;not null" json:"-"` // AES-GCM nonce followed by the ciphertext
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// BeforeCreate GORM hook to generate UUID
+func (s *Secret) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (Secret) TableName() string {
+	return "secrets"
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ScheduleRepository interface defines schedule data operations
+type ScheduleRepository interface {
+	Create(schedule *Schedule) error
+	GetByID(id uuid.UUID) (*Schedule, error)
+	GetAll() ([]*Schedule, error)
+	// ClaimDue calls advance for each enabled schedule whose next run is at
+	// or before now, saves the changes advance made to it and returns the
+	// claimed schedules once they are committed. Schedules are locked until
+	// then and those locked by another caller are skipped, so any number of
+	// schedulers can share the table. Callers start the runs only after
+	// ClaimDue returns, so a failed claim never leaves a run started for a
+	// schedule whose next_run_at didn't move.
+	ClaimDue(now time.Time, advance func(schedule *Schedule)) ([]*Schedule, error)
+	// RecordRun stores the time and execution of a schedule's latest run
+	RecordRun(id uuid.UUID, ranAt time.Time, executionID uuid.UUID) error
+	Update(schedule *Schedule) error
+	Delete(id uuid.UUID) error
+}
+
+// GormScheduleRepository implements ScheduleRepository using GORM
+type GormScheduleRepository struct {
+	db *gorm.DB
+}
+
+// NewScheduleRepository creates a new schedule repository
+func NewScheduleRepository(db *gorm.DB) ScheduleRepository {
+	return &GormScheduleRepository{db: db}
+}
+
+// Create inserts a new schedule
+func (r *GormScheduleRepository) Create(schedule *Schedule) error {
+	slog.Info("Creating schedule", "workflow_id", schedule.WorkflowID, "cron", schedule.CronExpression)
+
+	if err := r.db.Create(schedule).Error; err != nil {
+		slog.Error("Failed to create schedule", "error", err, "workflow_id", schedule.WorkflowID)
+		return fmt.Errorf("failed to create schedule: %w", err)
+	}
+
+	slog.Info("Schedule created successfully", "id", schedule.ID)
+	return nil
+}
+
+// GetByID retrieves a schedule by ID
+func (r *GormScheduleRepository) GetByID(id uuid.UUID) (*Schedule, error) {
+	slog.Info("Retrieving schedule by ID", "id", id)
+
+	var schedule Schedule
+	if err := r.db.Where("id = ?", id).First(&schedule).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.Warn("Schedule not found", "id", id)
+			return nil, fmt.Errorf("schedule not found: %s", id)
+		}
+		slog.Error("Failed to retrieve schedule", "error", err, "id", id)
+		return nil, fmt.Errorf("failed to retrieve schedule: %w", err)
+	}
+
+	return &schedule, nil
+}
+
+// GetAll retrieves all schedules
+func (r *GormScheduleRepository) GetAll() ([]*Schedule, error) {
+	slog.Info("Retrieving all schedules")
+
+	var schedules []*Schedule
+	if err := r.db.Order("created_at DESC").Find(&schedules).Error; err != nil {
+		slog.Error("Failed to retrieve schedules", "error", err)
+		return nil, fmt.Errorf("failed to retrieve schedules: %w", err)
+	}
+
+	slog.Info("Schedules retrieved successfully", "count", len(schedules))
+	return schedules, nil
+}
+
+// ClaimDue locks the due schedules with FOR UPDATE SKIP LOCKED and saves
+// what advance changed in the same transaction
+func (r *GormScheduleRepository) ClaimDue(now time.Time, advance func(schedule *Schedule)) ([]*Schedule, error) {
+	var schedules []*Schedule
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("enabled = ? AND next_run_at <= ?", true, now).
+			Order("next_run_at").
+			Find(&schedules).Error; err != nil {
+			return err
+		}
+
+		for _, schedule := range schedules {
+			advance(schedule)
+			if err := tx.Save(schedule).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Failed to claim due schedules", "error", err)
+		return nil, fmt.Errorf("failed to claim due schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// RecordRun updates only the last run columns, so it doesn't overwrite
+// changes made to the schedule since it was claimed
+func (r *GormScheduleRepository) RecordRun(id uuid.UUID, ranAt time.Time, executionID uuid.UUID) error {
+	if err := r.db.Model(&Schedule{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_run_at":       ranAt,
+		"last_execution_id": executionID,
+	}).Error; err != nil {
+		slog.Error("Failed to record schedule run", "error", err, "id", id)
+		return fmt.Errorf("failed to record schedule run: %w", err)
+	}
+	return nil
+}
+
+// Update updates an existing schedule
+func (r *GormScheduleRepository) Update(schedule *Schedule) error {
+	slog.Info("Updating schedule", "id", schedule.ID)
+
+	if err := r.db.Save(schedule).Error; err != nil {
+		slog.Error("Failed to update schedule", "error", err, "id", schedule.ID)
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+
+	slog.Info("Schedule updated successfully", "id", schedule.ID)
+	return nil
+}
+
+// Delete deletes a schedule by ID
+func (r *GormScheduleRepository) Delete(id uuid.UUID) error {
+	slog.Info("Deleting schedule", "id", id)
+
+	result := r.db.Delete(&Schedule{}, id)
+	if result.Error != nil {
+		slog.Error("Failed to delete schedule", "error", result.Error, "id", id)
+		return fmt.Errorf("failed to delete schedule: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		slog.Warn("Schedule not found for deletion", "id", id)
+		return fmt.Errorf("schedule not found: %s", id)
+	}
+
+	slog.Info("Schedule deleted successfully", "id", id)
+	return nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SecretRepository interface defines secret data operations. Values are
+// stored as given; encrypting them is up to the caller (see secrets.Store).
+type SecretRepository interface {
+	Create(secret *Secret) error
+	GetByID(id uuid.UUID) (*Secret, error)
+	GetByName(name string) (*Secret, error)
+	GetAll() ([]*Secret, error)
+	Update(secret *Secret) error
+	Delete(id uuid.UUID) error
+}
+
+// GormSecretRepository implements SecretRepository using GORM
+type GormSecretRepository struct {
+	db *gorm.DB
+}
+
+// NewSecretRepository creates a new secret repository
+func NewSecretRepository(db *gorm.DB) SecretRepository {
+	return &GormSecretRepository{db: db}
+}
+
+// Create inserts a new secret
+func (r *GormSecretRepository) Create(secret *Secret) error {
+	slog.Info("Creating secret", "name", secret.Name)
+
+	if err := r.db.Create(secret).Error; err != nil {
+		slog.Error("Failed to create secret", "error", err, "name", secret.Name)
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return fmt.Errorf("secret with name '%s' already exists", secret.Name)
+		}
+		return fmt.Errorf("failed to create secret: %w", err)
+	}
+
+	slog.Info("Secret created successfully", "id", secret.ID, "name", secret.Name)
+	return nil
+}
+
+// GetByID retrieves a secret by ID
+func (r *GormSecretRepository) GetByID(id uuid.UUID) (*Secret, error) {
+	slog.Info("Retrieving secret by ID", "id", id)
+
+	var secret Secret
+	if err := r.db.Where("id = ?", id).First(&secret).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.Warn("Secret not found", "id", id)
+			return nil, fmt.Errorf("secret not found: %s", id)
+		}
+		slog.Error("Failed to retrieve secret", "error", err, "id", id)
+		return nil, fmt.Errorf("failed to retrieve secret: %w", err)
+	}
+
+	return &secret, nil
+}
+
+// GetByName retrieves a secret by name
+func (r *GormSecretRepository) GetByName(name string) (*Secret, error) {
+	var secret Secret
+	if err := r.db.Where("name = ?", name).First(&secret).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("secret not found: %s", name)
+		}
+		slog.Error("Failed to retrieve secret by name", "error", err, "name", name)
+		return nil, fmt.Errorf("failed to retrieve secret: %w", err)
+	}
+
+	return &secret, nil
+}
+
+// GetAll retrieves all secrets
+func (r *GormSecretRepository) GetAll() ([]*Secret, error) {
+	slog.Info("Retrieving all secrets")
+
+	var secrets []*Secret
+	if err := r.db.Order("name").Find(&secrets).Error; err != nil {
+		slog.Error("Failed to retrieve secrets", "error", err)
+		return nil, fmt.Errorf("failed to retrieve secrets: %w", err)
+	}
+
+	slog.Info("Secrets retrieved successfully", "count", len(secrets))
+	return secrets, nil
+}
+
+// Update updates an existing secret
+func (r *GormSecretRepository) Update(secret *Secret) error {
+	slog.Info("Updating secret", "id", secret.ID)
+
+	if err := r.db.Save(secret).Error; err != nil {
+		slog.Error("Failed to update secret", "error", err, "id", secret.ID)
+		return fmt.Errorf("failed to update secret: %w", err)
+	}
+
+	slog.Info("Secret updated successfully", "id", secret.ID)
+	return nil
+}
+
+// Delete deletes a secret by ID
+func (r *GormSecretRepository) Delete(id uuid.UUID) error {
+	slog.Info("Deleting secret", "id", id)
+
+	result := r.db.Delete(&Secret{}, id)
+	if result.Error != nil {
+		slog.Error("Failed to delete secret", "error", result.Error, "id", id)
+		return fmt.Errorf("failed to delete secret: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		slog.Warn("Secret not found for deletion", "id", id)
+		return fmt.Errorf("secret not found: %s", id)
+	}
+
+	slog.Info("Secret deleted successfully", "id", id)
+	return nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebhookRepository interface defines webhook trigger data operations
+type WebhookRepository interface {
+	Create(webhook *WebhookTrigger) error
+	GetByID(id uuid.UUID) (*WebhookTrigger, error)
+	GetByToken(token string) (*WebhookTrigger, error)
+	GetAll() ([]*WebhookTrigger, error)
+	Update(webhook *WebhookTrigger) error
+	Delete(id uuid.UUID) error
+}
+
+// GormWebhookRepository implements WebhookRepository using GORM
+type GormWebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new webhook trigger repository
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &GormWebhookRepository{db: db}
+}
+
+// Create inserts a new webhook trigger
+func (r *GormWebhookRepository) Create(webhook *WebhookTrigger) error {
+	slog.Info("Creating webhook trigger", "workflow_id", webhook.WorkflowID)
+
+	if err := r.db.Create(webhook).Error; err != nil {
+		slog.Error("Failed to create webhook trigger", "error", err, "workflow_id", webhook.WorkflowID)
+		return fmt.Errorf("failed to create webhook trigger: %w", err)
+	}
+
+	slog.Info("Webhook trigger created successfully", "id", webhook.ID)
+	return nil
+}
+
+// GetByID retrieves a webhook trigger by ID
+func (r *GormWebhookRepository) GetByID(id uuid.UUID) (*WebhookTrigger, error) {
+	slog.Info("Retrieving webhook trigger by ID", "id", id)
+
+	var webhook WebhookTrigger
+	if err := r.db.Where("id = ?", id).First(&webhook).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			slog.Warn("Webhook trigger not found", "id", id)
+			return nil, fmt.Errorf("webhook trigger not found: %s", id)
+		}
+		slog.Error("Failed to retrieve webhook trigger", "error", err, "id", id)
+		return nil, fmt.Errorf("failed to retrieve webhook trigger: %w", err)
+	}
+
+	return &webhook, nil
+}
+
+// GetByToken retrieves a webhook trigger by its URL token. The token is not
+// logged since it grants the right to start the workflow.
+func (r *GormWebhookRepository) GetByToken(token string) (*WebhookTrigger, error) {
+	var webhook WebhookTrigger
+	if err := r.db.Where("token = ?", token).First(&webhook).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("webhook trigger not found")
+		}
+		slog.Error("Failed to retrieve webhook trigger by token", "error", err)
+		return nil, fmt.Errorf("failed to retrieve webhook trigger: %w", err)
+	}
+
+	return &webhook, nil
+}
+
+// GetAll retrieves all webhook triggers
+func (r *GormWebhookRepository) GetAll() ([]*WebhookTrigger, error) {
+	slog.Info("Retrieving all webhook triggers")
+
+	var webhooks []*WebhookTrigger
+	if err := r.db.Order("created_at DESC").Find(&webhooks).Error; err != nil {
+		slog.Error("Failed to retrieve webhook triggers", "error", err)
+		return nil, fmt.Errorf("failed to retrieve webhook triggers: %w", err)
+	}
+
+	slog.Info("Webhook triggers retrieved successfully", "count", len(webhooks))
+	return webhooks, nil
+}
+
+// Update updates an existing webhook trigger
+func (r *GormWebhookRepository) Update(webhook *WebhookTrigger) error {
+	slog.Info("Updating webhook trigger", "id", webhook.ID)
+
+	if err := r.db.Save(webhook).Error; err != nil {
+		slog.Error("Failed to update webhook trigger", "error", err, "id", webhook.ID)
+		return fmt.Errorf("failed to update webhook trigger: %w", err)
+	}
+
+	slog.Info("Webhook trigger updated successfully", "id", webhook.ID)
+	return nil
+}
+
+// Delete deletes a webhook trigger by ID
+func (r *GormWebhookRepository) Delete(id uuid.UUID) error {
+	slog.Info("Deleting webhook trigger", "id", id)
+
+	result := r.db.Delete(&WebhookTrigger{}, id)
+	if result.Error != nil {
+		slog.Error("Failed to delete webhook trigger", "error", result.Error, "id", id)
+		return fmt.Errorf("failed to delete webhook trigger: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		slog.Warn("Webhook trigger not found for deletion", "id", id)
+		return fmt.Errorf("webhook trigger not found: %s", id)
+	}
+
+	slog.Info("Webhook trigger deleted successfully", "id", id)
+	return nil
+}
@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustTime(t *testing.T, value string, loc *time.Location) time.Time {
+	t.Helper()
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+	require.NoError(t, err)
+	return parsed
+}
+
+func TestCronExpression_Next(t *testing.T) {
+	tests := []struct {
+		expr  string
+		after string
+		want  string
+	}{
+		{"* * * * *", "2026-03-10 08:15", "2026-03-10 08:16"},
+		{"*/15 * * * *", "2026-03-10 08:15", "2026-03-10 08:30"},
+		{"0 9 * * *", "2026-03-10 09:00", "2026-03-11 09:00"},
+		{"30 8-10/2 * * *", "2026-03-10 08:45", "2026-03-10 10:30"},
+		{"0 0 1 * *", "2026-01-31 12:00", "2026-02-01 00:00"},
+		{"0 12 * * MON-FRI", "2026-03-13 13:00", "2026-03-16 12:00"}, // Friday afternoon -> Monday
+		{"0 12 * * 7", "2026-03-10 00:00", "2026-03-15 12:00"},       // 7 is Sunday
+		{"0 0 13 * 5", "2026-03-01 00:00", "2026-03-06 00:00"},       // Friday or the 13th
+		{"0 0 29 FEB *", "2026-03-01 00:00", "2028-02-29 00:00"},
+		{"5,10 1 * * *", "2026-03-10 01:05", "2026-03-10 01:10"},
+		{"@hourly", "2026-03-10 08:15", "2026-03-10 09:00"},
+		{"@weekly", "2026-03-10 08:15", "2026-03-15 00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			cron, err := ParseCron(tt.expr)
+			require.NoError(t, err)
+
+			next := cron.Next(mustTime(t, tt.after, time.UTC))
+			assert.Equal(t, mustTime(t, tt.want, time.UTC), next)
+		})
+	}
+}
+
+func TestCronExpression_NextInTimezone(t *testing.T) {
+	loc, err := time.LoadLocation("America/Sao_Paulo")
+	require.NoError(t, err)
+	cron, err := ParseCron("0 9 * * *")
+	require.NoError(t, err)
+
+	next := cron.Next(mustTime(t, "2026-03-10 10:00", time.UTC).In(loc))
+
+	assert.Equal(t, mustTime(t, "2026-03-10 12:00", time.UTC), next.UTC())
+}
+
+func TestCronExpression_NextAcrossDSTGap(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	cron, err := ParseCron("30 * * * *")
+	require.NoError(t, err)
+
+	// 02:00-03:00 does not exist on 2026-03-08
+	next := cron.Next(mustTime(t, "2026-03-08 01:45", loc))
+
+	assert.Equal(t, mustTime(t, "2026-03-08 03:30", loc), next)
+}
+
+func TestCronExpression_NeverFires(t *testing.T) {
+	cron, err := ParseCron("0 0 30 2 *")
+	require.NoError(t, err)
+
+	assert.True(t, cron.Next(time.Now()).IsZero())
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	tests := []struct {
+		expr    string
+		wantErr string
+	}{
+		{"* * * *", "expected 5 fields, got 4"},
+		{"60 * * * *", "value 60 out of range 0-59 in minute field"},
+		{"* 5-2 * * *", "invalid range '5-2' in hour field"},
+		{"*/0 * * * *", "invalid step '0' in minute field"},
+		{"* * * JANUARY *", "invalid value 'JANUARY' in month field"},
+		{"@sometimes", "expected 5 fields, got 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseCron(tt.expr)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
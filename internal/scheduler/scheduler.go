@@ -0,0 +1,236 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultPollInterval is how often the scheduler looks for due schedules
+	DefaultPollInterval = 15 * time.Second
+	// MissedRunGrace is how late a fire time may be picked up and still
+	// count as on time rather than missed
+	MissedRunGrace = time.Minute
+	// MaxCatchUpRuns caps how many missed runs a catch_up schedule starts at once
+	MaxCatchUpRuns = 10
+)
+
+// RunFunc starts an execution of a workflow with the given input values and
+// returns the new execution's ID.
+type RunFunc func(workflowID uuid.UUID, input map[string]interface{}) (uuid.UUID, error)
+
+// Scheduler periodically starts the executions of due schedules. Schedules
+// are claimed through ScheduleRepository.ClaimDue, so every API replica can
+// run a Scheduler against the same database.
+type Scheduler struct {
+	schedules  repository.ScheduleRepository
+	executions repository.ExecutionRepository
+	run        RunFunc
+	interval   time.Duration
+	now        func() time.Time
+}
+
+// New creates a Scheduler that starts executions with run.
+func New(schedules repository.ScheduleRepository, executions repository.ExecutionRepository, run RunFunc) *Scheduler {
+	return &Scheduler{
+		schedules:  schedules,
+		executions: executions,
+		run:        run,
+		interval:   DefaultPollInterval,
+		now:        time.Now,
+	}
+}
+
+// NextRun validates a cron expression and timezone and returns the first
+// fire time after the given time, in UTC. It returns nil if the expression
+// never fires.
+func NextRun(cronExpression, timezone string, after time.Time) (*time.Time, error) {
+	cron, loc, err := parseSchedule(cronExpression, timezone)
+	if err != nil {
+		return nil, err
+	}
+	return nextRun(cron, loc, after), nil
+}
+
+// Run checks for due schedules every poll interval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	slog.Info("Starting scheduler", "interval", s.interval)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.tick()
+		select {
+		case <-ctx.Done():
+			slog.Info("Scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick fires every schedule that is due and not claimed by another Scheduler.
+// The claimed schedules' next_run_at is committed before any run starts, so
+// a failed claim doesn't start runs that the next tick would start again.
+func (s *Scheduler) tick() {
+	now := s.now().UTC()
+	fireTimes := make(map[uuid.UUID][]time.Time)
+	claimed, err := s.schedules.ClaimDue(now, func(schedule *repository.Schedule) {
+		fireTimes[schedule.ID] = s.advance(schedule, now)
+	})
+	if err != nil {
+		// Already logged by ClaimDue
+		return
+	}
+	for _, schedule := range claimed {
+		s.fire(schedule, fireTimes[schedule.ID], now)
+	}
+}
+
+// advance moves a due schedule's next_run_at past now and returns the fire
+// times to run according to its missed-run policy. Schedules with an invalid
+// cron expression or timezone are disabled.
+func (s *Scheduler) advance(schedule *repository.Schedule, now time.Time) []time.Time {
+	cron, loc, err := parseSchedule(schedule.CronExpression, schedule.Timezone)
+	if err != nil {
+		slog.Error("Disabling schedule with invalid cron expression", "schedule_id", schedule.ID, "error", err)
+		schedule.Enabled = false
+		schedule.NextRunAt = nil
+		return nil
+	}
+
+	// Collect every fire time up to now
+	var fireTimes []time.Time
+	next := schedule.NextRunAt
+	for next != nil && !next.After(now) {
+		fireTimes = append(fireTimes, *next)
+		next = nextRun(cron, loc, *next)
+	}
+	schedule.NextRunAt = next
+
+	return s.applyMissedRunPolicy(schedule, fireTimes, now)
+}
+
+// fire starts the runs of a claimed schedule for fireTimes according to its
+// overlap policy and records the last one started.
+func (s *Scheduler) fire(schedule *repository.Schedule, fireTimes []time.Time, now time.Time) {
+	started := false
+	for _, fireTime := range fireTimes {
+		if schedule.OverlapPolicy != repository.OverlapAllow && s.previousRunActive(schedule) {
+			slog.Warn("Skipping scheduled run, previous execution still active",
+				"schedule_id", schedule.ID,
+				"fire_time", fireTime,
+				"previous_execution_id", schedule.LastExecutionID,
+			)
+			continue
+		}
+
+		executionID, err := s.run(schedule.WorkflowID, s.inputs(schedule))
+		if err != nil {
+			slog.Error("Scheduled run failed to start", "schedule_id", schedule.ID, "fire_time", fireTime, "error", err)
+			continue
+		}
+		slog.Info("Started scheduled run",
+			"schedule_id", schedule.ID,
+			"workflow_id", schedule.WorkflowID,
+			"execution_id", executionID,
+			"fire_time", fireTime,
+		)
+		ranAt := now
+		schedule.LastRunAt = &ranAt
+		schedule.LastExecutionID = &executionID
+		started = true
+	}
+
+	if started {
+		// Errors are already logged by RecordRun
+		_ = s.schedules.RecordRun(schedule.ID, *schedule.LastRunAt, *schedule.LastExecutionID)
+	}
+}
+
+// applyMissedRunPolicy returns the fire times that should run. Fire times
+// later than MissedRunGrace count as missed: the skip policy drops them,
+// catch_up keeps them (at most MaxCatchUpRuns, the most recent ones).
+func (s *Scheduler) applyMissedRunPolicy(schedule *repository.Schedule, fireTimes []time.Time, now time.Time) []time.Time {
+	if schedule.MissedRunPolicy == repository.MissedRunCatchUp {
+		if len(fireTimes) > MaxCatchUpRuns {
+			slog.Warn("Too many missed runs, catching up on the most recent ones only",
+				"schedule_id", schedule.ID,
+				"missed", len(fireTimes),
+				"max", MaxCatchUpRuns,
+			)
+			fireTimes = fireTimes[len(fireTimes)-MaxCatchUpRuns:]
+		}
+		return fireTimes
+	}
+
+	var onTime []time.Time
+	for _, fireTime := range fireTimes {
+		if now.Sub(fireTime) <= MissedRunGrace {
+			onTime = append(onTime, fireTime)
+		}
+	}
+	if missed := len(fireTimes) - len(onTime); missed > 0 {
+		slog.Warn("Skipping missed scheduled runs", "schedule_id", schedule.ID, "missed", missed)
+	}
+	return onTime
+}
+
+// previousRunActive reports whether the schedule's last execution is still
+// pending or running.
+func (s *Scheduler) previousRunActive(schedule *repository.Schedule) bool {
+	if schedule.LastExecutionID == nil {
+		return false
+	}
+	execution, err := s.executions.GetByID(*schedule.LastExecutionID)
+	if err != nil {
+		slog.Warn("Failed to load previous scheduled execution", "schedule_id", schedule.ID, "error", err)
+		return false
+	}
+	return execution.Status == "pending" || execution.Status == "running"
+}
+
+// inputs decodes the schedule's default input values.
+func (s *Scheduler) inputs(schedule *repository.Schedule) map[string]interface{} {
+	if len(schedule.Inputs) == 0 {
+		return nil
+	}
+	var inputs map[string]interface{}
+	if err := json.Unmarshal(schedule.Inputs, &inputs); err != nil {
+		slog.Warn("Ignoring invalid schedule inputs", "schedule_id", schedule.ID, "error", err)
+		return nil
+	}
+	return inputs
+}
+
+// parseSchedule parses a schedule's cron expression and timezone.
+func parseSchedule(cronExpression, timezone string) (*CronExpression, *time.Location, error) {
+	cron, err := ParseCron(cronExpression)
+	if err != nil {
+		return nil, nil, err
+	}
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid timezone '%s': %w", timezone, err)
+	}
+	return cron, loc, nil
+}
+
+// nextRun returns the fire time after t in loc, as UTC, or nil if there is none.
+func nextRun(cron *CronExpression, loc *time.Location, t time.Time) *time.Time {
+	next := cron.Next(t.In(loc))
+	if next.IsZero() {
+		return nil
+	}
+	next = next.UTC()
+	return &next
+}
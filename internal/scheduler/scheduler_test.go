@@ -0,0 +1,329 @@
+package scheduler
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+// memoryScheduleRepository is an in-memory ScheduleRepository for tests.
+type memoryScheduleRepository struct {
+	mu        sync.Mutex
+	schedules map[uuid.UUID]repository.Schedule
+	claimErr  error // when set, ClaimDue fails without saving
+}
+
+func newMemoryScheduleRepository(schedules ...*repository.Schedule) *memoryScheduleRepository {
+	repo := &memoryScheduleRepository{schedules: make(map[uuid.UUID]repository.Schedule)}
+	for _, schedule := range schedules {
+		repo.Create(schedule)
+	}
+	return repo
+}
+
+func (m *memoryScheduleRepository) Create(schedule *repository.Schedule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if schedule.ID == uuid.Nil {
+		schedule.ID = uuid.New()
+	}
+	m.schedules[schedule.ID] = *schedule
+	return nil
+}
+
+func (m *memoryScheduleRepository) GetByID(id uuid.UUID) (*repository.Schedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	schedule, exists := m.schedules[id]
+	if !exists {
+		return nil, fmt.Errorf("schedule not found: %s", id)
+	}
+	return &schedule, nil
+}
+
+func (m *memoryScheduleRepository) GetAll() ([]*repository.Schedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var schedules []*repository.Schedule
+	for _, schedule := range m.schedules {
+		schedule := schedule
+		schedules = append(schedules, &schedule)
+	}
+	return schedules, nil
+}
+
+func (m *memoryScheduleRepository) ClaimDue(now time.Time, advance func(schedule *repository.Schedule)) ([]*repository.Schedule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var claimed []*repository.Schedule
+	for _, schedule := range m.schedules {
+		if schedule.Enabled && schedule.NextRunAt != nil && !schedule.NextRunAt.After(now) {
+			schedule := schedule
+			advance(&schedule)
+			claimed = append(claimed, &schedule)
+		}
+	}
+	if m.claimErr != nil {
+		return nil, m.claimErr
+	}
+	for _, schedule := range claimed {
+		m.schedules[schedule.ID] = *schedule
+	}
+	return claimed, nil
+}
+
+func (m *memoryScheduleRepository) RecordRun(id uuid.UUID, ranAt time.Time, executionID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	schedule, exists := m.schedules[id]
+	if !exists {
+		return fmt.Errorf("schedule not found: %s", id)
+	}
+	schedule.LastRunAt = &ranAt
+	schedule.LastExecutionID = &executionID
+	m.schedules[id] = schedule
+	return nil
+}
+
+func (m *memoryScheduleRepository) Update(schedule *repository.Schedule) error {
+	return m.Create(schedule)
+}
+
+func (m *memoryScheduleRepository) Delete(id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.schedules, id)
+	return nil
+}
+
+// memoryExecutionRepository is an in-memory ExecutionRepository for tests.
+type memoryExecutionRepository struct {
+	mu         sync.Mutex
+	executions map[uuid.UUID]repository.Execution
+}
+
+func newMemoryExecutionRepository() *memoryExecutionRepository {
+	return &memoryExecutionRepository{executions: make(map[uuid.UUID]repository.Execution)}
+}
+
+func (m *memoryExecutionRepository) Create(execution *repository.Execution) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if execution.ID == uuid.Nil {
+		execution.ID = uuid.New()
+	}
+	m.executions[execution.ID] = *execution
+	return nil
+}
+
+func (m *memoryExecutionRepository) GetByID(id uuid.UUID) (*repository.Execution, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	execution, exists := m.executions[id]
+	if !exists {
+		return nil, fmt.Errorf("execution not found: %s", id)
+	}
+	return &execution, nil
+}
+
+func (m *memoryExecutionRepository) GetByWorkflowID(workflowID uuid.UUID) ([]*repository.Execution, error) {
+	return nil, nil
+}
+
+func (m *memoryExecutionRepository) GetAll() ([]*repository.Execution, error) {
+	return nil, nil
+}
+
+func (m *memoryExecutionRepository) Update(execution *repository.Execution) error {
+	return m.Create(execution)
+}
+
+// recordingRunner records scheduled runs and creates executions with the given status.
+type recordingRunner struct {
+	executions *memoryExecutionRepository
+	status     string
+	inputs     []map[string]interface{}
+}
+
+func (r *recordingRunner) run(workflowID uuid.UUID, input map[string]interface{}) (uuid.UUID, error) {
+	r.inputs = append(r.inputs, input)
+	execution := &repository.Execution{WorkflowID: workflowID, Status: r.status}
+	r.executions.Create(execution)
+	return execution.ID, nil
+}
+
+var testNow = time.Date(2026, 3, 10, 12, 0, 20, 0, time.UTC)
+
+func newTestScheduler(schedule *repository.Schedule, status string) (*Scheduler, *memoryScheduleRepository, *recordingRunner) {
+	schedules := newMemoryScheduleRepository(schedule)
+	executions := newMemoryExecutionRepository()
+	runner := &recordingRunner{executions: executions, status: status}
+	s := New(schedules, executions, runner.run)
+	s.now = func() time.Time { return testNow }
+	return s, schedules, runner
+}
+
+func everyMinute(nextRunAt time.Time) *repository.Schedule {
+	return &repository.Schedule{
+		WorkflowID:     uuid.New(),
+		CronExpression: "* * * * *",
+		Timezone:       "UTC",
+		Enabled:        true,
+		NextRunAt:      &nextRunAt,
+	}
+}
+
+func TestScheduler_FiresDueSchedule(t *testing.T) {
+	schedule := everyMinute(testNow.Truncate(time.Minute))
+	schedule.Inputs = datatypes.JSON(`{"url": "http://example.com"}`)
+	s, schedules, runner := newTestScheduler(schedule, "completed")
+
+	s.tick()
+
+	require.Len(t, runner.inputs, 1)
+	assert.Equal(t, map[string]interface{}{"url": "http://example.com"}, runner.inputs[0])
+	stored, _ := schedules.GetByID(schedule.ID)
+	assert.Equal(t, time.Date(2026, 3, 10, 12, 1, 0, 0, time.UTC), *stored.NextRunAt)
+	assert.NotNil(t, stored.LastExecutionID)
+	assert.Equal(t, testNow, *stored.LastRunAt)
+}
+
+func TestScheduler_ReplicasShareSchedules(t *testing.T) {
+	schedule := everyMinute(testNow.Truncate(time.Minute))
+	s, schedules, runner := newTestScheduler(schedule, "completed")
+	replica := New(schedules, runner.executions, runner.run)
+	replica.now = s.now
+
+	var wg sync.WaitGroup
+	for _, scheduler := range []*Scheduler{s, replica} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scheduler.tick()
+		}()
+	}
+	wg.Wait()
+
+	// The first claim moves next_run_at past now
+	assert.Len(t, runner.inputs, 1)
+}
+
+func TestScheduler_FailedClaimStartsNoRuns(t *testing.T) {
+	schedule := everyMinute(testNow.Truncate(time.Minute))
+	s, schedules, runner := newTestScheduler(schedule, "completed")
+	schedules.claimErr = fmt.Errorf("connection reset")
+
+	s.tick()
+	s.tick()
+	assert.Empty(t, runner.inputs)
+
+	// Once claims succeed the schedule runs once
+	schedules.claimErr = nil
+	s.tick()
+	s.tick()
+	assert.Len(t, runner.inputs, 1)
+}
+
+func TestScheduler_SkipsMissedRuns(t *testing.T) {
+	schedule := everyMinute(testNow.Add(-10 * time.Minute).Truncate(time.Minute))
+	schedule.MissedRunPolicy = repository.MissedRunSkip
+	s, schedules, runner := newTestScheduler(schedule, "completed")
+
+	s.tick()
+
+	// only the fire time within MissedRunGrace (12:00) runs
+	assert.Len(t, runner.inputs, 1)
+	stored, _ := schedules.GetByID(schedule.ID)
+	assert.Equal(t, time.Date(2026, 3, 10, 12, 1, 0, 0, time.UTC), *stored.NextRunAt)
+}
+
+func TestScheduler_CatchesUpMissedRuns(t *testing.T) {
+	schedule := everyMinute(testNow.Add(-3 * time.Minute).Truncate(time.Minute))
+	schedule.MissedRunPolicy = repository.MissedRunCatchUp
+	schedule.OverlapPolicy = repository.OverlapAllow
+	s, _, runner := newTestScheduler(schedule, "running")
+
+	s.tick()
+
+	assert.Len(t, runner.inputs, 4) // 11:57, 11:58, 11:59 and 12:00
+}
+
+func TestScheduler_CatchUpIsCapped(t *testing.T) {
+	schedule := everyMinute(testNow.Add(-time.Hour).Truncate(time.Minute))
+	schedule.MissedRunPolicy = repository.MissedRunCatchUp
+	schedule.OverlapPolicy = repository.OverlapAllow
+	s, _, runner := newTestScheduler(schedule, "completed")
+
+	s.tick()
+
+	assert.Len(t, runner.inputs, MaxCatchUpRuns)
+}
+
+func TestScheduler_SkipsOverlappingRuns(t *testing.T) {
+	schedule := everyMinute(testNow.Truncate(time.Minute))
+	s, schedules, runner := newTestScheduler(schedule, "running")
+
+	s.tick()
+	require.Len(t, runner.inputs, 1)
+
+	// The next fire time comes while the first execution is still running
+	s.now = func() time.Time { return testNow.Add(time.Minute) }
+	s.tick()
+
+	assert.Len(t, runner.inputs, 1)
+	assert.Len(t, runner.executions.executions, 1)
+	stored, _ := schedules.GetByID(schedule.ID)
+	assert.Equal(t, time.Date(2026, 3, 10, 12, 2, 0, 0, time.UTC), *stored.NextRunAt)
+}
+
+func TestScheduler_AllowsOverlappingRuns(t *testing.T) {
+	schedule := everyMinute(testNow.Truncate(time.Minute))
+	schedule.OverlapPolicy = repository.OverlapAllow
+	s, _, runner := newTestScheduler(schedule, "running")
+
+	s.tick()
+	s.now = func() time.Time { return testNow.Add(time.Minute) }
+	s.tick()
+
+	assert.Len(t, runner.inputs, 2)
+}
+
+func TestScheduler_IgnoresDisabledAndFutureSchedules(t *testing.T) {
+	disabled := everyMinute(testNow.Truncate(time.Minute))
+	disabled.Enabled = false
+	s, schedules, runner := newTestScheduler(disabled, "completed")
+	schedules.Create(everyMinute(testNow.Add(time.Minute)))
+
+	s.tick()
+
+	assert.Empty(t, runner.inputs)
+}
+
+func TestScheduler_DisablesInvalidSchedule(t *testing.T) {
+	schedule := everyMinute(testNow.Truncate(time.Minute))
+	schedule.Timezone = "Mars/Olympus_Mons"
+	s, schedules, runner := newTestScheduler(schedule, "completed")
+
+	s.tick()
+
+	assert.Empty(t, runner.inputs)
+	stored, _ := schedules.GetByID(schedule.ID)
+	assert.False(t, stored.Enabled)
+	assert.Nil(t, stored.NextRunAt)
+}
+
+func TestNextRun(t *testing.T) {
+	next, err := NextRun("0 9 * * *", "America/Sao_Paulo", testNow)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 11, 12, 0, 0, 0, time.UTC), *next)
+
+	_, err = NextRun("0 9 * * *", "Nowhere/City", testNow)
+	assert.ErrorContains(t, err, "invalid timezone 'Nowhere/City'")
+}
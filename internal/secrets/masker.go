@@ -0,0 +1,184 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask replaces secret values in masked text.
+const Mask = "***"
+
+// Masker replaces known secret values with Mask. Values are added as they
+// are resolved, so only secrets used by this process are known.
+type Masker struct {
+	mu       sync.RWMutex
+	values   map[string]bool
+	replacer *strings.Replacer // nil until a value is added
+}
+
+// NewMasker creates a Masker that knows no values yet.
+func NewMasker() *Masker {
+	return &Masker{values: make(map[string]bool)}
+}
+
+// Add makes m mask value from now on. Empty values are ignored.
+func (m *Masker) Add(value string) {
+	if value == "" {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.values[value] {
+		return
+	}
+	m.values[value] = true
+
+	// Longest first, so a value containing another one is masked whole
+	values := make([]string, 0, len(m.values))
+	for v := range m.values {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	pairs := make([]string, 0, 2*len(values))
+	for _, v := range values {
+		pairs = append(pairs, v, Mask)
+	}
+	m.replacer = strings.NewReplacer(pairs...)
+}
+
+// active reports whether m knows any value.
+func (m *Masker) active() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.replacer != nil
+}
+
+// String returns s with the known values masked.
+func (m *Masker) String(s string) string {
+	m.mu.RLock()
+	replacer := m.replacer
+	m.mu.RUnlock()
+	if replacer == nil || s == "" {
+		return s
+	}
+	return replacer.Replace(s)
+}
+
+// Value returns a copy of value with the known values masked in every
+// string it contains, including map keys. Values of other types than the
+// ones produced by encoding/json and http.Header are masked through their
+// JSON form, so they are only converted when they contain a known value.
+func (m *Masker) Value(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return m.String(v)
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			masked[m.String(key)] = m.Value(item)
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(v))
+		for i, item := range v {
+			masked[i] = m.Value(item)
+		}
+		return masked
+	case http.Header:
+		// The response headers of http_request
+		masked := make(http.Header, len(v))
+		for key, items := range v {
+			masked[key] = m.Strings(items)
+		}
+		return masked
+	case []string:
+		return m.Strings(v)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return value
+		}
+		maskedData := m.String(string(data))
+		if maskedData == string(data) {
+			return value
+		}
+		var masked interface{}
+		if err := json.Unmarshal([]byte(maskedData), &masked); err != nil {
+			return Mask
+		}
+		return masked
+	}
+}
+
+// Strings returns a copy of values with the known values masked.
+func (m *Masker) Strings(values []string) []string {
+	masked := make([]string, len(values))
+	for i, v := range values {
+		masked[i] = m.String(v)
+	}
+	return masked
+}
+
+// LogHandler wraps next so that the known values are masked in log messages
+// and attribute values.
+func (m *Masker) LogHandler(next slog.Handler) slog.Handler {
+	return &maskingHandler{next: next, masker: m}
+}
+
+type maskingHandler struct {
+	next   slog.Handler
+	masker *Masker
+}
+
+func (h *maskingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *maskingHandler) Handle(ctx context.Context, record slog.Record) error {
+	masked := slog.NewRecord(record.Time, record.Level, h.masker.String(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		masked.AddAttrs(h.maskAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, masked)
+}
+
+func (h *maskingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	masked := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		masked[i] = h.maskAttr(attr)
+	}
+	return &maskingHandler{next: h.next.WithAttrs(masked), masker: h.masker}
+}
+
+func (h *maskingHandler) WithGroup(name string) slog.Handler {
+	return &maskingHandler{next: h.next.WithGroup(name), masker: h.masker}
+}
+
+// maskAttr masks the value of attr. Values other than strings and groups
+// (e.g. errors) are masked in their string form if it contains a known value.
+func (h *maskingHandler) maskAttr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, h.masker.String(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		masked := make([]any, len(group))
+		for i, item := range group {
+			masked[i] = h.maskAttr(item)
+		}
+		return slog.Group(attr.Key, masked...)
+	case slog.KindAny:
+		text := value.String()
+		if maskedText := h.masker.String(text); maskedText != text {
+			return slog.String(attr.Key, maskedText)
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
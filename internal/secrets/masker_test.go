@@ -0,0 +1,60 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMasker_MasksKnownValues(t *testing.T) {
+	masker := NewMasker()
+	assert.Equal(t, "token abc", masker.String("token abc"))
+
+	masker.Add("abc")
+	masker.Add("abcdef")
+	masker.Add("")
+	assert.Equal(t, "token *** and ***", masker.String("token abcdef and abc"))
+
+	type item struct {
+		Value string `json:"value"`
+	}
+	masked := masker.Value(map[string]interface{}{
+		"status_code": 200,
+		"headers":     http.Header{"X-Token": []string{"abc"}},
+		"body":        []interface{}{"abc", 1.5, map[string]interface{}{"abc": true}},
+		"items":       []item{{Value: "abc"}},
+		"plain":       []item{{Value: "xyz"}},
+	})
+	assert.Equal(t, map[string]interface{}{
+		"status_code": 200,
+		"headers":     http.Header{"X-Token": []string{"***"}},
+		"body":        []interface{}{"***", 1.5, map[string]interface{}{"***": true}},
+		"items":       []interface{}{map[string]interface{}{"value": "***"}},
+		"plain":       []item{{Value: "xyz"}},
+	}, masked)
+}
+
+func TestMasker_LogHandler(t *testing.T) {
+	masker := NewMasker()
+	masker.Add("s3cret")
+	var buf bytes.Buffer
+	logger := slog.New(masker.LogHandler(slog.NewTextHandler(&buf, nil)))
+
+	logger.With("token", "s3cret").Info("using s3cret",
+		"url", "https://example.com/?key=s3cret",
+		"error", errors.New("denied for s3cret"),
+		"count", 3,
+		slog.Group("request", "auth", "Bearer s3cret"),
+	)
+
+	assert.NotContains(t, buf.String(), "s3cret")
+	assert.Contains(t, buf.String(), `msg="using ***"`)
+	assert.Contains(t, buf.String(), "token=***")
+	assert.Contains(t, buf.String(), `error="denied for ***"`)
+	assert.Contains(t, buf.String(), "count=3")
+	assert.Contains(t, buf.String(), `request.auth="Bearer ***"`)
+}
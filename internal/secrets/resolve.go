@@ -0,0 +1,175 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+)
+
+// referencePattern matches a {{secret "name"}} reference
+var referencePattern = regexp.MustCompile(`\{\{-?\s*secret\s+"([^"]*)"\s*-?\}\}`)
+
+// Source looks up secret values by name; *Store is a Source.
+type Source interface {
+	Value(name string) (string, error)
+}
+
+// Install makes the runs of e resolve {{secret "name"}} references from
+// source, and masks the resolved values with masker so they are not
+// recorded:
+//   - references are resolved in a copy of each task's config just before
+//     the task executes, so TaskLog inputs keep the references;
+//   - resolved values are masked in task outputs and errors, before they
+//     are logged or stored in the execution context;
+//   - they are masked in the execution context once the run finishes, so
+//     the context snapshot doesn't hold values that reached it otherwise,
+//     e.g. as the input of a sub-workflow.
+//
+// In the config keys an executor names as engine.TemplateProvider, values
+// are inserted as template string literals, so a value containing "{{" is
+// rendered as is rather than executed by the task's own template pass.
+//
+// A reference to a missing secret fails the task. Call Install before e
+// starts executing workflows, after any other middleware, so that the
+// other middleware only sees masked results.
+func Install(e *engine.Engine, source Source, masker *Masker) {
+	r := &resolver{source: source, masker: masker, registry: e.Registry()}
+	e.AddHook(r)
+	e.Use(r.wrapTask)
+}
+
+// resolver is the task middleware and RunHook installed by Install
+type resolver struct {
+	source   Source
+	masker   *Masker
+	registry *engine.Registry
+}
+
+func (r *resolver) wrapTask(next engine.TaskHandler) engine.TaskHandler {
+	return func(ctx context.Context, call engine.TaskCall) engine.TaskResult {
+		if call.Skip {
+			return next(ctx, call)
+		}
+		config, err := r.resolveConfig(call.Task)
+		if err != nil {
+			return engine.TaskResult{Status: "failed", Error: err.Error()}
+		}
+		call.Task.Config = config
+
+		result := next(ctx, call)
+		result.Output = r.masker.Value(result.Output)
+		result.Error = r.masker.String(result.Error)
+		return result
+	}
+}
+
+// resolveConfig returns a copy of task's config with its secret references
+// resolved, escaped under the keys its executor executes as templates
+func (r *resolver) resolveConfig(task engine.Task) (map[string]interface{}, error) {
+	if task.Config == nil {
+		return nil, nil
+	}
+	templateKeys := make(map[string]bool)
+	if executor, err := r.registry.Get(task.Type); err == nil {
+		if provider, ok := executor.(engine.TemplateProvider); ok {
+			for _, key := range provider.TemplateKeys() {
+				templateKeys[key] = true
+			}
+		}
+	}
+
+	config := make(map[string]interface{}, len(task.Config))
+	for key, value := range task.Config {
+		var err error
+		if config[key], err = r.resolve(value, templateKeys[key]); err != nil {
+			return nil, err
+		}
+	}
+	return config, nil
+}
+
+// resolve returns a copy of value with the secret references in its strings
+// replaced by the secrets' values, or by template actions rendering them if
+// escape is set
+func (r *resolver) resolve(value interface{}, escape bool) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return r.resolveString(v, escape)
+	case map[string]interface{}:
+		if v == nil {
+			return v, nil
+		}
+		resolved := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			var err error
+			if resolved[key], err = r.resolve(item, escape); err != nil {
+				return nil, err
+			}
+		}
+		return resolved, nil
+	case []interface{}:
+		resolved := make([]interface{}, len(v))
+		for i, item := range v {
+			var err error
+			if resolved[i], err = r.resolve(item, escape); err != nil {
+				return nil, err
+			}
+		}
+		return resolved, nil
+	default:
+		return value, nil
+	}
+}
+
+func (r *resolver) resolveString(s string, escape bool) (string, error) {
+	var resolveErr error
+	resolved := referencePattern.ReplaceAllStringFunc(s, func(reference string) string {
+		if resolveErr != nil {
+			return reference
+		}
+		name := referencePattern.FindStringSubmatch(reference)[1]
+		value, err := r.source.Value(name)
+		if err != nil {
+			resolveErr = fmt.Errorf("failed to resolve secret '%s': %w", name, err)
+			return reference
+		}
+		r.masker.Add(value)
+		if escape {
+			return templateLiteral(reference, value)
+		}
+		return value
+	})
+	return resolved, resolveErr
+}
+
+// templateLiteral returns a template action rendering value, keeping the
+// trim markers of reference
+func templateLiteral(reference, value string) string {
+	left, right := "{{", "}}"
+	if strings.HasPrefix(reference, "{{-") {
+		left = "{{- "
+	}
+	if strings.HasSuffix(reference, "-}}") {
+		right = " -}}"
+	}
+	return left + strconv.Quote(value) + right
+}
+
+func (r *resolver) BeforeRun(ctx context.Context, run *engine.RunInfo) (context.Context, error) {
+	return ctx, nil
+}
+
+// AfterRun masks the resolved values in the run's context
+func (r *resolver) AfterRun(ctx context.Context, run *engine.RunInfo) error {
+	if !r.masker.active() {
+		return nil
+	}
+	for key, value := range run.Context.GetAll() {
+		run.Context.Set(key, r.masker.Value(value))
+	}
+	return nil
+}
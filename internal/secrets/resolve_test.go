@@ -0,0 +1,197 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/tasks"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingLogger keeps what a run records in memory
+type recordingLogger struct {
+	mu        sync.Mutex
+	execution engine.ExecutionRecord
+	taskLogs  map[uuid.UUID]engine.TaskLogRecord
+}
+
+func newRecordingLogger() *recordingLogger {
+	return &recordingLogger{taskLogs: make(map[uuid.UUID]engine.TaskLogRecord)}
+}
+
+func (l *recordingLogger) CreateExecution(execution *engine.ExecutionRecord) error {
+	return l.UpdateExecution(execution)
+}
+
+func (l *recordingLogger) UpdateExecution(execution *engine.ExecutionRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.execution = *execution
+	return nil
+}
+
+func (l *recordingLogger) CreateTaskLog(taskLog *engine.TaskLogRecord) error {
+	return l.UpdateTaskLog(taskLog)
+}
+
+func (l *recordingLogger) UpdateTaskLog(taskLog *engine.TaskLogRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.taskLogs[taskLog.ID] = *taskLog
+	return nil
+}
+
+// echoExecutor returns its config as output and copies it into the
+// execution context; it fails with the config in its error if asked to
+type echoExecutor struct {
+	mu      sync.Mutex
+	configs []map[string]interface{}
+}
+
+func (e *echoExecutor) Execute(ctx context.Context, execCtx *engine.ExecutionContext, config map[string]interface{}) engine.TaskResult {
+	e.mu.Lock()
+	e.configs = append(e.configs, config)
+	e.mu.Unlock()
+	execCtx.Set("leaked", config)
+	if config["fail"] == true {
+		return engine.TaskResult{Status: "failed", Output: config, Error: fmt.Sprintf("rejected %v", config["headers"])}
+	}
+	return engine.TaskResult{Status: "success", Output: config}
+}
+
+func newResolvingEngine(t *testing.T, executor *echoExecutor) (*engine.Engine, *Masker) {
+	store, err := NewStore(newMemorySecretRepository(), testKey(1))
+	require.NoError(t, err)
+	_, err = store.Create("api_token", "tok-123")
+	require.NoError(t, err)
+
+	registry := engine.NewRegistry()
+	registry.Register("echo", executor)
+	executionEngine := engine.NewEngine(registry)
+	masker := NewMasker()
+	Install(executionEngine, store, masker)
+	return executionEngine, masker
+}
+
+func TestInstall_ResolvesReferencesAndMasksRecords(t *testing.T) {
+	executor := &echoExecutor{}
+	executionEngine, masker := newResolvingEngine(t, executor)
+	logger := newRecordingLogger()
+
+	_, err := executionEngine.ExecuteWithLogging(context.Background(), engine.WorkflowDefinition{
+		Name: "secret",
+		Tasks: []engine.Task{{ID: "call", Type: "echo", Config: map[string]interface{}{
+			"headers": map[string]interface{}{"Authorization": `Bearer {{secret "api_token"}}`},
+			"list":    []interface{}{`{{ secret "api_token" }}`, 1.0},
+			"fail":    true,
+		}}},
+	}, uuid.New(), logger, nil)
+	require.Error(t, err)
+
+	// The executor gets the value
+	require.Len(t, executor.configs, 1)
+	assert.Equal(t, map[string]interface{}{
+		"headers": map[string]interface{}{"Authorization": "Bearer tok-123"},
+		"list":    []interface{}{"tok-123", 1.0},
+		"fail":    true,
+	}, executor.configs[0])
+	assert.Equal(t, "***", masker.String("tok-123"))
+
+	// but it is not recorded anywhere
+	assert.NotContains(t, err.Error(), "tok-123")
+	assert.NotContains(t, string(logger.execution.ContextSnapshot), "tok-123")
+	assert.Contains(t, string(logger.execution.ContextSnapshot), "Bearer ***")
+	require.Len(t, logger.taskLogs, 1)
+	for _, taskLog := range logger.taskLogs {
+		assert.Contains(t, string(taskLog.Input), `{{secret \"api_token\"}}`)
+		assert.NotContains(t, string(taskLog.Output), "tok-123")
+		assert.Equal(t, "rejected map[Authorization:Bearer ***]", taskLog.Error)
+	}
+}
+
+func TestInstall_SecretsAreNotExecutedAsTemplates(t *testing.T) {
+	var received *http.Request
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	store, err := NewStore(newMemorySecretRepository(), testKey(1))
+	require.NoError(t, err)
+	_, err = store.Create("tricky", `a"{{.context.missing}}`)
+	require.NoError(t, err)
+	registry := engine.NewRegistry()
+	tasks.RegisterHTTPTask(registry)
+	executionEngine := engine.NewEngine(registry)
+	Install(executionEngine, store, NewMasker())
+
+	_, err = executionEngine.Execute(context.Background(), engine.WorkflowDefinition{
+		Name: "tricky",
+		Tasks: []engine.Task{{ID: "call", Type: "http_request", Config: map[string]interface{}{
+			"method":  "POST",
+			"url":     server.URL + "/items",
+			"query":   map[string]interface{}{"key": `{{secret "tricky"}}`},
+			"headers": map[string]interface{}{"X-Token": `{{- secret "tricky" -}}`},
+			"body":    `token={{secret "tricky"}}&n={{len "abc"}}`,
+			"auth":    map[string]interface{}{"type": "basic", "username": "user", "password": `{{secret "tricky"}}`},
+		}}},
+	})
+	require.NoError(t, err)
+
+	// Templated and plain fields both get the value as is
+	require.NotNil(t, received)
+	assert.Equal(t, `a"{{.context.missing}}`, received.URL.Query().Get("key"))
+	assert.Equal(t, `a"{{.context.missing}}`, received.Header.Get("X-Token"))
+	assert.Equal(t, `token=a"{{.context.missing}}&n=3`, body)
+	_, password, ok := received.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, `a"{{.context.missing}}`, password)
+}
+
+func TestInstall_MissingSecretFailsTask(t *testing.T) {
+	executor := &echoExecutor{}
+	executionEngine, _ := newResolvingEngine(t, executor)
+
+	_, err := executionEngine.Execute(context.Background(), engine.WorkflowDefinition{
+		Name: "missing",
+		Tasks: []engine.Task{{ID: "call", Type: "echo", Config: map[string]interface{}{
+			"token": `{{secret "nope"}}`,
+		}}},
+	})
+	assert.ErrorContains(t, err, "failed to resolve secret 'nope'")
+	assert.Empty(t, executor.configs)
+}
+
+func TestInstall_WithoutStore(t *testing.T) {
+	executor := &echoExecutor{}
+	registry := engine.NewRegistry()
+	registry.Register("echo", executor)
+	executionEngine := engine.NewEngine(registry)
+	var store *Store
+	Install(executionEngine, store, NewMasker())
+
+	// Configs without references run as usual
+	_, err := executionEngine.Execute(context.Background(), engine.WorkflowDefinition{
+		Name:  "plain",
+		Tasks: []engine.Task{{ID: "call", Type: "echo", Config: map[string]interface{}{"url": "{{.context.url}}"}}},
+	})
+	require.NoError(t, err)
+
+	_, err = executionEngine.Execute(context.Background(), engine.WorkflowDefinition{
+		Name:  "secret",
+		Tasks: []engine.Task{{ID: "call", Type: "echo", Config: map[string]interface{}{"token": `{{secret "api_token"}}`}}},
+	})
+	assert.ErrorContains(t, err, ErrNotConfigured.Error())
+}
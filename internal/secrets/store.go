@@ -0,0 +1,215 @@
+// Package secrets stores named secret values encrypted at rest, resolves
+// {{secret "name"}} references in task configs when tasks run, and masks
+// the resolved values wherever executions are recorded or logged.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/google/uuid"
+)
+
+// MasterKeyEnv names the environment variable holding the master key: 32
+// random bytes, base64 encoded (e.g. the output of `openssl rand -base64 32`).
+const MasterKeyEnv = "SECRETS_MASTER_KEY"
+
+var (
+	// ErrNotConfigured is returned by a nil Store, i.e. when no master key is set
+	ErrNotConfigured = errors.New("secrets are not configured: " + MasterKeyEnv + " is not set")
+	// ErrInvalidName is returned by Store.Create for names a reference can't spell
+	ErrInvalidName = errors.New("invalid secret name")
+)
+
+// namePattern restricts secret names to what a {{secret "name"}} reference
+// can spell without escaping.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,255}$`)
+
+// MasterKeyFromEnv decodes the master key from SECRETS_MASTER_KEY. It returns
+// nil if the variable is not set.
+func MasterKeyFromEnv() ([]byte, error) {
+	value := os.Getenv(MasterKeyEnv)
+	if value == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", MasterKeyEnv, err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid %s: expected 32 bytes, got %d", MasterKeyEnv, len(key))
+	}
+	return key, nil
+}
+
+// Store keeps secrets in a SecretRepository, encrypted with AES-256-GCM under
+// the master key. Each value is bound to its secret's name, so ciphertexts
+// cannot be swapped between secrets. A nil *Store has no secrets: all its
+// methods fail with ErrNotConfigured.
+type Store struct {
+	repo repository.SecretRepository
+	aead cipher.AEAD
+}
+
+// NewStore creates a Store encrypting with key, which must be 32 bytes long.
+func NewStore(repo repository.SecretRepository, key []byte) (*Store, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid master key: expected 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid master key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{repo: repo, aead: aead}, nil
+}
+
+// Create stores a new secret.
+func (s *Store) Create(name, value string) (*repository.Secret, error) {
+	if s == nil {
+		return nil, ErrNotConfigured
+	}
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w '%s': use letters, digits, '_', '.' and '-'", ErrInvalidName, name)
+	}
+	secret := &repository.Secret{Name: name}
+	var err error
+	if secret.Value, err = s.encrypt(name, value); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// Get returns the secret with the given ID; its Value stays encrypted.
+func (s *Store) Get(id uuid.UUID) (*repository.Secret, error) {
+	if s == nil {
+		return nil, ErrNotConfigured
+	}
+	return s.repo.GetByID(id)
+}
+
+// List returns all secrets; their Values stay encrypted.
+func (s *Store) List() ([]*repository.Secret, error) {
+	if s == nil {
+		return nil, ErrNotConfigured
+	}
+	return s.repo.GetAll()
+}
+
+// Update replaces the value of the secret with the given ID.
+func (s *Store) Update(id uuid.UUID, value string) (*repository.Secret, error) {
+	if s == nil {
+		return nil, ErrNotConfigured
+	}
+	secret, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if secret.Value, err = s.encrypt(secret.Name, value); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// Delete removes the secret with the given ID.
+func (s *Store) Delete(id uuid.UUID) error {
+	if s == nil {
+		return ErrNotConfigured
+	}
+	return s.repo.Delete(id)
+}
+
+// Value returns the decrypted value of the secret called name.
+func (s *Store) Value(name string) (string, error) {
+	if s == nil {
+		return "", ErrNotConfigured
+	}
+	secret, err := s.repo.GetByName(name)
+	if err != nil {
+		return "", err
+	}
+	return s.decrypt(secret)
+}
+
+// Seal encrypts value for storage outside the secrets table, e.g. in another
+// model's column. The result is base64 text bound to label, which must not be
+// a valid secret name (use a "kind:id" label), so that it can't be opened as
+// a secret or under another label.
+func (s *Store) Seal(label, value string) (string, error) {
+	if s == nil {
+		return "", ErrNotConfigured
+	}
+	sealed, err := s.encrypt(label, value)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value sealed with label by Seal.
+func (s *Store) Open(label, sealed string) (string, error) {
+	if s == nil {
+		return "", ErrNotConfigured
+	}
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", fmt.Errorf("sealed value '%s' is corrupted: %w", label, err)
+	}
+	plaintext, err := s.open(label, raw)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt sealed value '%s': %w", label, err)
+	}
+	return plaintext, nil
+}
+
+// encrypt seals value under the additional data aad (the secret's name for
+// secrets), prefixed with a random nonce
+func (s *Store) encrypt(aad, value string) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return s.aead.Seal(nonce, nonce, []byte(value), []byte(aad)), nil
+}
+
+// open opens a value sealed by encrypt under aad
+func (s *Store) open(aad string, sealed []byte) (string, error) {
+	nonceSize := s.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", errors.New("value is too short")
+	}
+	plaintext, err := s.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(aad))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// decrypt opens the value of secret, as sealed by encrypt
+func (s *Store) decrypt(secret *repository.Secret) (string, error) {
+	if len(secret.Value) < s.aead.NonceSize() {
+		return "", fmt.Errorf("secret '%s' is corrupted", secret.Name)
+	}
+	plaintext, err := s.open(secret.Name, secret.Value)
+	if err != nil {
+		// Usually a different master key than the one the value was stored with
+		return "", fmt.Errorf("failed to decrypt secret '%s': %w", secret.Name, err)
+	}
+	return plaintext, nil
+}
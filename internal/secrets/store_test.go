@@ -0,0 +1,198 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySecretRepository is an in-memory repository.SecretRepository
+type memorySecretRepository struct {
+	mu      sync.Mutex
+	secrets map[uuid.UUID]*repository.Secret
+}
+
+func newMemorySecretRepository() *memorySecretRepository {
+	return &memorySecretRepository{secrets: make(map[uuid.UUID]*repository.Secret)}
+}
+
+func (m *memorySecretRepository) Create(secret *repository.Secret) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.secrets {
+		if existing.Name == secret.Name {
+			return fmt.Errorf("secret with name '%s' already exists", secret.Name)
+		}
+	}
+	secret.ID = uuid.New()
+	stored := *secret
+	m.secrets[secret.ID] = &stored
+	return nil
+}
+
+func (m *memorySecretRepository) GetByID(id uuid.UUID) (*repository.Secret, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	secret, ok := m.secrets[id]
+	if !ok {
+		return nil, fmt.Errorf("secret not found: %s", id)
+	}
+	found := *secret
+	return &found, nil
+}
+
+func (m *memorySecretRepository) GetByName(name string) (*repository.Secret, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, secret := range m.secrets {
+		if secret.Name == name {
+			found := *secret
+			return &found, nil
+		}
+	}
+	return nil, fmt.Errorf("secret not found: %s", name)
+}
+
+func (m *memorySecretRepository) GetAll() ([]*repository.Secret, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var secrets []*repository.Secret
+	for _, secret := range m.secrets {
+		found := *secret
+		secrets = append(secrets, &found)
+	}
+	return secrets, nil
+}
+
+func (m *memorySecretRepository) Update(secret *repository.Secret) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.secrets[secret.ID]; !ok {
+		return fmt.Errorf("secret not found: %s", secret.ID)
+	}
+	stored := *secret
+	m.secrets[secret.ID] = &stored
+	return nil
+}
+
+func (m *memorySecretRepository) Delete(id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.secrets[id]; !ok {
+		return fmt.Errorf("secret not found: %s", id)
+	}
+	delete(m.secrets, id)
+	return nil
+}
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func TestStore_EncryptsValues(t *testing.T) {
+	repo := newMemorySecretRepository()
+	store, err := NewStore(repo, testKey(1))
+	require.NoError(t, err)
+
+	secret, err := store.Create("api_token", "s3cret-value")
+	require.NoError(t, err)
+	stored, err := repo.GetByID(secret.ID)
+	require.NoError(t, err)
+	assert.NotContains(t, string(stored.Value), "s3cret-value")
+
+	value, err := store.Value("api_token")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret-value", value)
+
+	_, err = store.Update(secret.ID, "rotated")
+	require.NoError(t, err)
+	value, err = store.Value("api_token")
+	require.NoError(t, err)
+	assert.Equal(t, "rotated", value)
+
+	// Values can't be read with another key, nor under another name
+	otherStore, err := NewStore(repo, testKey(2))
+	require.NoError(t, err)
+	_, err = otherStore.Value("api_token")
+	assert.ErrorContains(t, err, "failed to decrypt secret 'api_token'")
+
+	copied, err := store.Create("copy", "placeholder")
+	require.NoError(t, err)
+	copied.Value = stored.Value
+	require.NoError(t, repo.Update(copied))
+	_, err = store.Value("copy")
+	assert.Error(t, err)
+}
+
+func TestStore_SealAndOpen(t *testing.T) {
+	store, err := NewStore(newMemorySecretRepository(), testKey(1))
+	require.NoError(t, err)
+
+	sealed, err := store.Seal("webhook:1", "signing-key")
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "signing-key")
+
+	value, err := store.Open("webhook:1", sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "signing-key", value)
+
+	// Sealed values are bound to their label
+	_, err = store.Open("webhook:2", sealed)
+	assert.ErrorContains(t, err, "failed to decrypt sealed value 'webhook:2'")
+	_, err = store.Open("webhook:1", "not base64!")
+	assert.ErrorContains(t, err, "is corrupted")
+
+	var disabled *Store
+	_, err = disabled.Seal("webhook:1", "signing-key")
+	assert.ErrorIs(t, err, ErrNotConfigured)
+}
+
+func TestStore_Errors(t *testing.T) {
+	store, err := NewStore(newMemorySecretRepository(), testKey(1))
+	require.NoError(t, err)
+
+	_, err = store.Create("has space", "value")
+	assert.ErrorIs(t, err, ErrInvalidName)
+	_, err = store.Create("token", "value")
+	require.NoError(t, err)
+	_, err = store.Create("token", "value")
+	assert.ErrorContains(t, err, "already exists")
+	_, err = store.Value("missing")
+	assert.ErrorContains(t, err, "not found")
+
+	_, err = NewStore(newMemorySecretRepository(), testKey(1)[:16])
+	assert.Error(t, err)
+
+	var disabled *Store
+	_, err = disabled.Value("token")
+	assert.ErrorIs(t, err, ErrNotConfigured)
+	_, err = disabled.List()
+	assert.ErrorIs(t, err, ErrNotConfigured)
+}
+
+func TestMasterKeyFromEnv(t *testing.T) {
+	t.Setenv(MasterKeyEnv, "")
+	key, err := MasterKeyFromEnv()
+	assert.NoError(t, err)
+	assert.Nil(t, key)
+
+	t.Setenv(MasterKeyEnv, base64.StdEncoding.EncodeToString(testKey(7)))
+	key, err = MasterKeyFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, testKey(7), key)
+
+	t.Setenv(MasterKeyEnv, base64.StdEncoding.EncodeToString([]byte("too short")))
+	_, err = MasterKeyFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv(MasterKeyEnv, "not base64!")
+	_, err = MasterKeyFromEnv()
+	assert.Error(t, err)
+}
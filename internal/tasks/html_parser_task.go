@@ -3,6 +3,7 @@
 package tasks
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -35,7 +36,7 @@ type SelectorConfig struct {
 //   - multiple (bool, optional): Extract all matches (default: false, first match only)
 //
 // Returns extracted data as []map[string]any per AC1.
-func (h *HTMLParserTask) Execute(ctx *engine.ExecutionContext, config map[string]interface{}) engine.TaskResult {
+func (h *HTMLParserTask) Execute(ctx context.Context, execCtx *engine.ExecutionContext, config map[string]interface{}) engine.TaskResult {
 	// Validate html_source
 	htmlSource, ok := config["html_source"].(string)
 	if !ok || htmlSource == "" {
@@ -57,7 +58,7 @@ func (h *HTMLParserTask) Execute(ctx *engine.ExecutionContext, config map[string
 	}
 
 	// Load HTML content from context
-	htmlContent, exists := ctx.Get(htmlSource)
+	htmlContent, exists := execCtx.Get(htmlSource)
 	if !exists {
 		slog.Warn("HTML source not found in context", "source", htmlSource)
 		return engine.TaskResult{
@@ -132,6 +133,61 @@ func (h *HTMLParserTask) Execute(ctx *engine.ExecutionContext, config map[string
 	}
 }
 
+// ValidateConfig implements engine.ConfigValidator. It checks the required
+// html_source and selectors, including each selector's name and selector.
+func (h *HTMLParserTask) ValidateConfig(config map[string]interface{}) error {
+	if htmlSource, ok := config["html_source"].(string); !ok || htmlSource == "" {
+		return fmt.Errorf("missing or invalid 'html_source' in configuration")
+	}
+	selectorsConfig, ok := config["selectors"].([]interface{})
+	if !ok || len(selectorsConfig) == 0 {
+		return fmt.Errorf("missing or invalid 'selectors' in configuration")
+	}
+	_, err := h.parseSelectors(selectorsConfig)
+	return err
+}
+
+// ContextPathKeys implements engine.ContextPathProvider.
+func (h *HTMLParserTask) ContextPathKeys() []string {
+	return []string{"html_source"}
+}
+
+// Schema implements engine.SchemaProvider.
+func (h *HTMLParserTask) Schema() engine.TaskSchema {
+	return engine.TaskSchema{
+		Description: "Extracts data from HTML stored in the context using CSS selectors.",
+		Config: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"html_source", "selectors"},
+			"properties": map[string]interface{}{
+				"html_source": map[string]interface{}{
+					"type":        "string",
+					"description": "Context key holding the HTML, e.g. the result of an http_request task",
+				},
+				"selectors": map[string]interface{}{
+					"type":        "array",
+					"description": "Values to extract",
+					"items": map[string]interface{}{
+						"type":     "object",
+						"required": []string{"name", "selector"},
+						"properties": map[string]interface{}{
+							"name":      map[string]interface{}{"type": "string", "description": "Output field name"},
+							"selector":  map[string]interface{}{"type": "string", "description": "CSS selector"},
+							"attribute": map[string]interface{}{"type": "string", "description": "Attribute to extract instead of the text"},
+							"multiple":  map[string]interface{}{"type": "boolean", "description": "Extract all matches instead of the first one"},
+						},
+					},
+				},
+			},
+		},
+		Output: map[string]interface{}{
+			"type":        "array",
+			"description": "A single object mapping each selector name to the extracted string, or to a list of strings for multiple selectors",
+			"items":       map[string]interface{}{"type": "object"},
+		},
+	}
+}
+
 // parseSelectors converts raw config to SelectorConfig structs.
 func (h *HTMLParserTask) parseSelectors(selectorsConfig []interface{}) ([]SelectorConfig, error) {
 	selectors := make([]SelectorConfig, 0, len(selectorsConfig))
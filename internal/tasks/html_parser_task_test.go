@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"testing"
 
 	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
@@ -45,7 +46,7 @@ func TestHTMLParserTask_SingleElementExtraction(t *testing.T) {
 		},
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.Empty(t, result.Error)
@@ -73,7 +74,7 @@ func TestHTMLParserTask_MultipleElementExtraction(t *testing.T) {
 		},
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.Empty(t, result.Error)
@@ -105,7 +106,7 @@ func TestHTMLParserTask_AttributeExtraction(t *testing.T) {
 		},
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 
@@ -131,7 +132,7 @@ func TestHTMLParserTask_MultipleAttributeExtraction(t *testing.T) {
 		},
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 
@@ -160,7 +161,7 @@ func TestHTMLParserTask_ImageSourceExtraction(t *testing.T) {
 		},
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 
@@ -196,7 +197,7 @@ func TestHTMLParserTask_MultipleSelectors(t *testing.T) {
 		},
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 
@@ -226,7 +227,7 @@ func TestHTMLParserTask_NestedSelectors(t *testing.T) {
 		},
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 
@@ -250,7 +251,7 @@ func TestHTMLParserTask_EmptyResults(t *testing.T) {
 		},
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	// Should succeed but with empty value
 	assert.Equal(t, "success", result.Status)
@@ -275,7 +276,7 @@ func TestHTMLParserTask_MissingHTMLSource(t *testing.T) {
 		},
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "failed", result.Status)
 	assert.Contains(t, result.Error, "not found in context")
@@ -295,7 +296,7 @@ func TestHTMLParserTask_InvalidHTMLSourceConfig(t *testing.T) {
 		},
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "failed", result.Status)
 	assert.Contains(t, result.Error, "html_source")
@@ -312,7 +313,7 @@ func TestHTMLParserTask_MissingSelectors(t *testing.T) {
 		// selectors missing
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "failed", result.Status)
 	assert.Contains(t, result.Error, "selectors")
@@ -334,7 +335,7 @@ func TestHTMLParserTask_InvalidSelectorConfig_MissingName(t *testing.T) {
 		},
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "failed", result.Status)
 	assert.Contains(t, result.Error, "missing 'name'")
@@ -356,7 +357,7 @@ func TestHTMLParserTask_InvalidSelectorConfig_MissingSelector(t *testing.T) {
 		},
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "failed", result.Status)
 	assert.Contains(t, result.Error, "missing 'selector'")
@@ -379,7 +380,7 @@ func TestHTMLParserTask_InvalidHTML(t *testing.T) {
 		},
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	// Goquery will parse even malformed HTML successfully
 	assert.Equal(t, "success", result.Status)
@@ -408,7 +409,7 @@ func TestHTMLParserTask_HTMLFromHTTPResponse(t *testing.T) {
 		},
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 
@@ -433,7 +434,7 @@ func TestHTMLParserTask_NonStringHTMLContent(t *testing.T) {
 		},
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "failed", result.Status)
 	assert.Contains(t, result.Error, "not a string")
@@ -482,7 +483,7 @@ func TestHTMLParserTask_ComplexHTMLStructure(t *testing.T) {
 		},
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 
@@ -514,3 +515,22 @@ func TestHTMLParserTask_RegisterHTMLParserTask(t *testing.T) {
 	assert.NotNil(t, executor)
 	assert.IsType(t, &HTMLParserTask{}, executor)
 }
+
+func TestHTMLParserTask_ValidateConfig(t *testing.T) {
+	task := &HTMLParserTask{}
+
+	assert.NoError(t, task.ValidateConfig(map[string]interface{}{
+		"html_source": "fetch_result",
+		"selectors":   []interface{}{map[string]interface{}{"name": "title", "selector": "h1"}},
+	}))
+	assert.ErrorContains(t, task.ValidateConfig(map[string]interface{}{
+		"selectors": []interface{}{map[string]interface{}{"name": "title", "selector": "h1"}},
+	}), "missing or invalid 'html_source'")
+	assert.ErrorContains(t, task.ValidateConfig(map[string]interface{}{
+		"html_source": "fetch_result",
+	}), "missing or invalid 'selectors'")
+	assert.ErrorContains(t, task.ValidateConfig(map[string]interface{}{
+		"html_source": "fetch_result",
+		"selectors":   []interface{}{map[string]interface{}{"name": "title"}},
+	}), "missing 'selector'")
+}
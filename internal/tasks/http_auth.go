@@ -0,0 +1,260 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported values of the http_request auth "type"
+const (
+	authBasic                   = "basic"
+	authBearer                  = "bearer"
+	authAPIKey                  = "api_key"
+	authOAuth2ClientCredentials = "oauth2_client_credentials"
+)
+
+// tokenExpiryDelta is how long before its expiry a cached OAuth2 token is
+// replaced, so it doesn't expire while a request is in flight
+const tokenExpiryDelta = 10 * time.Second
+
+// httpAuth is the auth block of an http_request task:
+//   - basic: username, password
+//   - bearer: token
+//   - api_key: name, value, in ("header", the default, or "query")
+//   - oauth2_client_credentials: token_url, client_id, client_secret,
+//     scope (optional), client_auth ("basic", the default, sends the client
+//     credentials in an Authorization header, "body" in the form)
+type httpAuth struct {
+	Type         string
+	Username     string
+	Password     string
+	Token        string
+	Name         string
+	Value        string
+	In           string
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scope        string
+	ClientAuth   string
+}
+
+// parseHTTPAuth reads and checks the auth block of config. It returns nil if
+// there is none.
+func parseHTTPAuth(config map[string]interface{}) (*httpAuth, error) {
+	raw, exists := config["auth"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+	block, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid 'auth' in configuration: expected an object")
+	}
+
+	fields := make(map[string]string, len(block))
+	for key, value := range block {
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid 'auth.%s' in configuration: expected a string", key)
+		}
+		fields[key] = str
+	}
+	auth := &httpAuth{
+		Type:         fields["type"],
+		Username:     fields["username"],
+		Password:     fields["password"],
+		Token:        fields["token"],
+		Name:         fields["name"],
+		Value:        fields["value"],
+		In:           fields["in"],
+		TokenURL:     fields["token_url"],
+		ClientID:     fields["client_id"],
+		ClientSecret: fields["client_secret"],
+		Scope:        fields["scope"],
+		ClientAuth:   fields["client_auth"],
+	}
+
+	var required []string
+	switch auth.Type {
+	case authBasic:
+		required = []string{"username"}
+	case authBearer:
+		required = []string{"token"}
+	case authAPIKey:
+		required = []string{"name", "value"}
+		if auth.In == "" {
+			auth.In = "header"
+		}
+		if auth.In != "header" && auth.In != "query" {
+			return nil, fmt.Errorf("invalid 'auth.in' in configuration: expected \"header\" or \"query\"")
+		}
+	case authOAuth2ClientCredentials:
+		required = []string{"token_url", "client_id", "client_secret"}
+		if auth.ClientAuth == "" {
+			auth.ClientAuth = "basic"
+		}
+		if auth.ClientAuth != "basic" && auth.ClientAuth != "body" {
+			return nil, fmt.Errorf("invalid 'auth.client_auth' in configuration: expected \"basic\" or \"body\"")
+		}
+	case "":
+		return nil, fmt.Errorf("missing 'auth.type' in configuration")
+	default:
+		return nil, fmt.Errorf("unsupported auth type '%s': expected basic, bearer, api_key or oauth2_client_credentials", auth.Type)
+	}
+	for _, key := range required {
+		if fields[key] == "" {
+			return nil, fmt.Errorf("missing 'auth.%s' for %s auth", key, auth.Type)
+		}
+	}
+	return auth, nil
+}
+
+// apply authenticates req. For OAuth2 it takes the token from the session,
+// fetching one with client if there is none; fresh reports whether the token
+// was fetched for this request.
+func (a *httpAuth) apply(req *http.Request, client *http.Client, session *httpSession) (fresh bool, err error) {
+	switch a.Type {
+	case authBasic:
+		req.SetBasicAuth(a.Username, a.Password)
+	case authBearer:
+		req.Header.Set("Authorization", "Bearer "+a.Token)
+	case authAPIKey:
+		if a.In == "query" {
+			query := req.URL.Query()
+			query.Set(a.Name, a.Value)
+			req.URL.RawQuery = query.Encode()
+		} else {
+			req.Header.Set(a.Name, a.Value)
+		}
+	case authOAuth2ClientCredentials:
+		token, fresh, err := session.token(req.Context(), a, client)
+		if err != nil {
+			return false, err
+		}
+		req.Header.Set("Authorization", token.authorization())
+		return fresh, nil
+	}
+	return false, nil
+}
+
+// oauth2Token is an access token from a token endpoint
+type oauth2Token struct {
+	AccessToken string
+	TokenType   string
+	Expiry      time.Time // zero if the endpoint gave no expires_in
+}
+
+func (t *oauth2Token) valid(now time.Time) bool {
+	return t.Expiry.IsZero() || now.Add(tokenExpiryDelta).Before(t.Expiry)
+}
+
+// authorization returns the Authorization header value for t. Token types
+// other than bearer are rare; they are sent as given.
+func (t *oauth2Token) authorization() string {
+	if t.TokenType == "" || strings.EqualFold(t.TokenType, "bearer") {
+		return "Bearer " + t.AccessToken
+	}
+	return t.TokenType + " " + t.AccessToken
+}
+
+// cacheKey identifies the tokens of a client; tasks with the same client and
+// scope share them
+func (a *httpAuth) cacheKey() string {
+	return strings.Join([]string{a.TokenURL, a.ClientID, a.ClientSecret, a.Scope}, "\x00")
+}
+
+// token returns the session's valid token for a's client, fetching a new one
+// if needed. Concurrent requests wait for a single fetch.
+func (s *httpSession) token(ctx context.Context, a *httpAuth, client *http.Client) (*oauth2Token, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := a.cacheKey()
+	if token, ok := s.tokens[key]; ok && token.valid(time.Now()) {
+		return token, false, nil
+	}
+	token, err := fetchClientCredentialsToken(ctx, a, client)
+	if err != nil {
+		return nil, false, err
+	}
+	s.tokens[key] = token
+	return token, true, nil
+}
+
+// invalidate drops a's cached token after the API rejected it, unless another
+// request already replaced it.
+func (s *httpSession) invalidate(a *httpAuth, rejected string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := a.cacheKey()
+	if token, ok := s.tokens[key]; ok && token.authorization() == rejected {
+		delete(s.tokens, key)
+	}
+}
+
+// fetchClientCredentialsToken requests a token from a.TokenURL with the
+// client credentials grant (RFC 6749 section 4.4).
+func fetchClientCredentialsToken(ctx context.Context, a *httpAuth, client *http.Client) (*oauth2Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if a.Scope != "" {
+		form.Set("scope", a.Scope)
+	}
+	if a.ClientAuth == "body" {
+		form.Set("client_id", a.ClientID)
+		form.Set("client_secret", a.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if a.ClientAuth != "body" {
+		req.SetBasicAuth(url.QueryEscape(a.ClientID), url.QueryEscape(a.ClientSecret))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("token endpoint returned HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var parsed struct {
+		AccessToken string      `json:"access_token"`
+		TokenType   string      `json:"token_type"`
+		ExpiresIn   json.Number `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid token response: %w", err)
+	}
+	if parsed.AccessToken == "" {
+		return nil, fmt.Errorf("invalid token response: missing access_token")
+	}
+
+	token := &oauth2Token{AccessToken: parsed.AccessToken, TokenType: parsed.TokenType}
+	if parsed.ExpiresIn != "" {
+		seconds, err := strconv.ParseFloat(string(parsed.ExpiresIn), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token response: expires_in %q", parsed.ExpiresIn)
+		}
+		if seconds > 0 {
+			token.Expiry = time.Now().Add(time.Duration(seconds * float64(time.Second)))
+		}
+	}
+	return token, nil
+}
@@ -0,0 +1,236 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPTask_Execute_Auth(t *testing.T) {
+	var received *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		auth  map[string]interface{}
+		check func(t *testing.T, r *http.Request)
+	}{
+		{"basic", map[string]interface{}{"type": "basic", "username": "user", "password": "pass"}, func(t *testing.T, r *http.Request) {
+			username, password, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "user", username)
+			assert.Equal(t, "pass", password)
+		}},
+		{"bearer", map[string]interface{}{"type": "bearer", "token": "tok-123"}, func(t *testing.T, r *http.Request) {
+			assert.Equal(t, "Bearer tok-123", r.Header.Get("Authorization"))
+		}},
+		{"api key header", map[string]interface{}{"type": "api_key", "name": "X-API-Key", "value": "key-1"}, func(t *testing.T, r *http.Request) {
+			assert.Equal(t, "key-1", r.Header.Get("X-API-Key"))
+			assert.Equal(t, "page=1", r.URL.RawQuery)
+		}},
+		{"api key query", map[string]interface{}{"type": "api_key", "name": "api_key", "value": "key 1", "in": "query"}, func(t *testing.T, r *http.Request) {
+			assert.Equal(t, "key 1", r.URL.Query().Get("api_key"))
+			assert.Equal(t, "1", r.URL.Query().Get("page"))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = nil
+			result := (&HTTPTask{}).Execute(context.Background(), engine.NewExecutionContext(), map[string]interface{}{
+				"method": "GET",
+				"url":    server.URL + "/items?page=1",
+				"auth":   tt.auth,
+			})
+			require.Equal(t, "success", result.Status, result.Error)
+			require.NotNil(t, received)
+			tt.check(t, received)
+		})
+	}
+}
+
+// tokenServer is an OAuth2 token endpoint and an API accepting only the
+// tokens it issued and didn't revoke
+type tokenServer struct {
+	mu        sync.Mutex
+	expiresIn int
+	issued    int
+	revoked   map[string]bool
+	forms     []map[string]string
+	apiCalls  []string // Authorization header and body of each API call
+}
+
+func newTokenServer(t *testing.T, expiresIn int) (*tokenServer, *httptest.Server) {
+	ts := &tokenServer{expiresIn: expiresIn, revoked: make(map[string]bool)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+		require.NoError(t, r.ParseForm())
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok {
+			clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+		}
+		ts.forms = append(ts.forms, map[string]string{"grant_type": r.PostForm.Get("grant_type"), "scope": r.PostForm.Get("scope"), "client_id": clientID})
+		if clientSecret != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"invalid_client"}`)
+			return
+		}
+		ts.issued++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fmt.Sprintf("token-%d", ts.issued),
+			"token_type":   "bearer",
+			"expires_in":   ts.expiresIn,
+		})
+	})
+	mux.HandleFunc("/api", func(w http.ResponseWriter, r *http.Request) {
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		authorization := r.Header.Get("Authorization")
+		ts.apiCalls = append(ts.apiCalls, authorization+" "+string(body))
+		if authorization == "" || ts.revoked[authorization] {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return ts, server
+}
+
+func (ts *tokenServer) revoke(authorization string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.revoked[authorization] = true
+}
+
+func oauth2Config(server *httptest.Server, secret string) map[string]interface{} {
+	return map[string]interface{}{
+		"method": "POST",
+		"url":    server.URL + "/api",
+		"body":   `{"n": 1}`,
+		"auth": map[string]interface{}{
+			"type":          "oauth2_client_credentials",
+			"token_url":     server.URL + "/token",
+			"client_id":     "client",
+			"client_secret": secret,
+			"scope":         "read write",
+		},
+	}
+}
+
+func TestHTTPTask_Execute_OAuth2ClientCredentials(t *testing.T) {
+	ts, server := newTokenServer(t, 3600)
+	ctx, _ := httpSessionHook{}.BeforeRun(context.Background(), &engine.RunInfo{})
+	task := &HTTPTask{}
+
+	for i := 0; i < 3; i++ {
+		result := task.Execute(ctx, engine.NewExecutionContext(), oauth2Config(server, "s3cret"))
+		require.Equal(t, "success", result.Status, result.Error)
+	}
+
+	// One token serves the whole session
+	assert.Equal(t, 1, ts.issued)
+	assert.Equal(t, []map[string]string{{"grant_type": "client_credentials", "scope": "read write", "client_id": "client"}}, ts.forms)
+	assert.Equal(t, []string{`Bearer token-1 {"n": 1}`, `Bearer token-1 {"n": 1}`, `Bearer token-1 {"n": 1}`}, ts.apiCalls)
+
+	// A new session gets its own token
+	result := task.Execute(context.Background(), engine.NewExecutionContext(), oauth2Config(server, "s3cret"))
+	require.Equal(t, "success", result.Status, result.Error)
+	assert.Equal(t, 2, ts.issued)
+}
+
+func TestHTTPTask_Execute_OAuth2CredentialsInBody(t *testing.T) {
+	ts, server := newTokenServer(t, 3600)
+	config := oauth2Config(server, "s3cret")
+	config["auth"].(map[string]interface{})["client_auth"] = "body"
+
+	result := (&HTTPTask{}).Execute(context.Background(), engine.NewExecutionContext(), config)
+	require.Equal(t, "success", result.Status, result.Error)
+	assert.Equal(t, 1, ts.issued)
+}
+
+func TestHTTPTask_Execute_OAuth2RefreshesExpiredToken(t *testing.T) {
+	// Tokens expiring within tokenExpiryDelta are not reused
+	ts, server := newTokenServer(t, 5)
+	ctx, _ := httpSessionHook{}.BeforeRun(context.Background(), &engine.RunInfo{})
+
+	for i := 0; i < 2; i++ {
+		result := (&HTTPTask{}).Execute(ctx, engine.NewExecutionContext(), oauth2Config(server, "s3cret"))
+		require.Equal(t, "success", result.Status, result.Error)
+	}
+	assert.Equal(t, 2, ts.issued)
+}
+
+func TestHTTPTask_Execute_OAuth2RefreshesOn401(t *testing.T) {
+	ts, server := newTokenServer(t, 3600)
+	ctx, _ := httpSessionHook{}.BeforeRun(context.Background(), &engine.RunInfo{})
+	task := &HTTPTask{}
+
+	result := task.Execute(ctx, engine.NewExecutionContext(), oauth2Config(server, "s3cret"))
+	require.Equal(t, "success", result.Status, result.Error)
+
+	ts.revoke("Bearer token-1")
+	result = task.Execute(ctx, engine.NewExecutionContext(), oauth2Config(server, "s3cret"))
+	require.Equal(t, "success", result.Status, result.Error)
+	assert.Equal(t, 2, ts.issued)
+	assert.Equal(t, []string{`Bearer token-1 {"n": 1}`, `Bearer token-1 {"n": 1}`, `Bearer token-2 {"n": 1}`}, ts.apiCalls)
+
+	// A 401 to a token fetched for the request is not retried
+	ts.revoke("Bearer token-2")
+	ts.revoke("Bearer token-3")
+	result = task.Execute(context.Background(), engine.NewExecutionContext(), oauth2Config(server, "s3cret"))
+	assert.Equal(t, "failed", result.Status)
+	assert.Equal(t, 401, result.Output.(map[string]interface{})["status_code"])
+	assert.Equal(t, 3, ts.issued)
+}
+
+func TestHTTPTask_Execute_OAuth2TokenError(t *testing.T) {
+	ts, server := newTokenServer(t, 3600)
+
+	result := (&HTTPTask{}).Execute(context.Background(), engine.NewExecutionContext(), oauth2Config(server, "wrong"))
+	assert.Equal(t, "failed", result.Status)
+	assert.Contains(t, result.Error, "authentication failed: token endpoint returned HTTP 401")
+	assert.Empty(t, ts.apiCalls)
+}
+
+func TestInstallHTTPSessions(t *testing.T) {
+	ts, server := newTokenServer(t, 3600)
+	registry := engine.NewRegistry()
+	RegisterHTTPTask(registry)
+	executionEngine := engine.NewEngine(registry)
+	InstallHTTPSessions(executionEngine)
+
+	workflow := engine.WorkflowDefinition{
+		Name: "oauth2",
+		Tasks: []engine.Task{
+			{ID: "first", Type: "http_request", Config: oauth2Config(server, "s3cret")},
+			{ID: "second", Type: "http_request", Config: oauth2Config(server, "s3cret")},
+		},
+	}
+	for i := 0; i < 2; i++ {
+		_, err := executionEngine.Execute(context.Background(), workflow)
+		require.NoError(t, err)
+	}
+
+	// One token per execution
+	assert.Equal(t, 2, ts.issued)
+	assert.Len(t, ts.apiCalls, 4)
+}
@@ -0,0 +1,376 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+)
+
+// Pagination styles supported by the http_request pagination option
+const (
+	paginationPage   = "page"
+	paginationOffset = "offset"
+	paginationCursor = "cursor"
+	paginationLink   = "link"
+)
+
+// defaultMaxPages is how many pages are fetched when max_pages is not set
+const defaultMaxPages = 10
+
+// httpPagination is the pagination option of an http_request task. Each page
+// is requested with the task's method, headers, body and auth; only the query
+// string changes. Next links are therefore only followed within the origin
+// of the first page, so they can't send the credentials elsewhere.
+type httpPagination struct {
+	// Type is "page", "offset", "cursor" or "link"
+	Type string `json:"type"`
+	// MaxPages stops the task after that many pages (default: 10)
+	MaxPages int `json:"max_pages,omitempty"`
+	// DelayMs is waited between two page requests
+	DelayMs int `json:"delay_ms,omitempty"`
+	// ItemsPath locates the page's items in its body, e.g. "data.items";
+	// empty means the body itself. Page and offset pagination stop at the
+	// first empty or short page.
+	ItemsPath string `json:"items_path,omitempty"`
+
+	// PageParam is the page number query parameter (default: "page")
+	PageParam string `json:"page_param,omitempty"`
+	// StartPage is the first page number (default: 1)
+	StartPage *int `json:"start_page,omitempty"`
+	// PageSize, if set, is sent as SizeParam and a page with fewer items is
+	// the last one
+	PageSize  int    `json:"page_size,omitempty"`
+	SizeParam string `json:"size_param,omitempty"`
+
+	// OffsetParam and LimitParam are the offset/limit query parameters
+	// (default: "offset" and "limit"); Limit is required
+	OffsetParam string `json:"offset_param,omitempty"`
+	LimitParam  string `json:"limit_param,omitempty"`
+	Limit       int    `json:"limit,omitempty"`
+
+	// CursorPath locates the next cursor in the body, e.g. "meta.next_cursor";
+	// CursorParam is the query parameter it is sent in (default: "cursor").
+	// Pagination stops when the cursor is missing or empty.
+	CursorPath  string `json:"cursor_path,omitempty"`
+	CursorParam string `json:"cursor_param,omitempty"`
+}
+
+// parseHTTPPagination reads and checks the pagination option of config. It
+// returns nil if there is none.
+func parseHTTPPagination(config map[string]interface{}) (*httpPagination, error) {
+	raw, exists := config["pagination"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+	if _, ok := raw.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("invalid 'pagination' in configuration: expected an object")
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid 'pagination' in configuration: %v", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+	p := &httpPagination{}
+	if err := decoder.Decode(p); err != nil {
+		return nil, fmt.Errorf("invalid 'pagination' in configuration: %v", err)
+	}
+
+	switch {
+	case p.MaxPages < 0:
+		return nil, fmt.Errorf("invalid 'pagination.max_pages': must not be negative")
+	case p.DelayMs < 0:
+		return nil, fmt.Errorf("invalid 'pagination.delay_ms': must not be negative")
+	case p.PageSize < 0:
+		return nil, fmt.Errorf("invalid 'pagination.page_size': must not be negative")
+	}
+	if p.MaxPages == 0 {
+		p.MaxPages = defaultMaxPages
+	}
+
+	switch p.Type {
+	case paginationPage:
+		if p.PageParam == "" {
+			p.PageParam = "page"
+		}
+		if p.StartPage == nil {
+			start := 1
+			p.StartPage = &start
+		}
+		if p.SizeParam != "" && p.PageSize == 0 {
+			return nil, fmt.Errorf("missing 'pagination.page_size' for 'pagination.size_param'")
+		}
+	case paginationOffset:
+		if p.OffsetParam == "" {
+			p.OffsetParam = "offset"
+		}
+		if p.LimitParam == "" {
+			p.LimitParam = "limit"
+		}
+		if p.Limit <= 0 {
+			return nil, fmt.Errorf("missing or invalid 'pagination.limit' for offset pagination")
+		}
+	case paginationCursor:
+		if p.CursorPath == "" {
+			return nil, fmt.Errorf("missing 'pagination.cursor_path' for cursor pagination")
+		}
+		if p.CursorParam == "" {
+			p.CursorParam = "cursor"
+		}
+	case paginationLink:
+	case "":
+		return nil, fmt.Errorf("missing 'pagination.type' in configuration")
+	default:
+		return nil, fmt.Errorf("unsupported pagination type '%s': expected page, offset, cursor or link", p.Type)
+	}
+	return p, nil
+}
+
+// run fetches the pages starting at url. The output has the parsed body of
+// every page in "body" and their status codes in "status_codes";
+// "status_code", "headers" and "cookies" are those of the last page. A page
+// with an error status, or any other error after the first page, fails the
+// task with the pages fetched so far as output.
+func (p *httpPagination) run(ctx context.Context, call *httpCall, url string) engine.TaskResult {
+	var bodies []interface{}
+	var statusCodes []int
+	var last *httpPage
+	output := func(page *httpPage) map[string]interface{} {
+		return map[string]interface{}{
+			"status_code":  page.StatusCode,
+			"headers":      page.Header,
+			"cookies":      page.Cookies,
+			"body":         bodies,
+			"status_codes": statusCodes,
+			"pages":        len(bodies),
+		}
+	}
+
+	failed := func(err error) engine.TaskResult {
+		slog.Error("HTTP request failed", "error", err, "pages", len(bodies))
+		if last == nil {
+			return engine.TaskResult{Status: "failed", Output: nil, Error: err.Error()}
+		}
+		return engine.TaskResult{Status: "failed", Output: output(last), Error: err.Error()}
+	}
+
+	next, err := p.first(url)
+	if err != nil {
+		return failed(err)
+	}
+	for pageNumber := 1; ; pageNumber++ {
+		if pageNumber > 1 && p.DelayMs > 0 {
+			select {
+			case <-ctx.Done():
+				return failed(fmt.Errorf("page %d: request execution failed: %v", pageNumber, ctx.Err()))
+			case <-time.After(time.Duration(p.DelayMs) * time.Millisecond):
+			}
+		}
+
+		slog.Info("Executing HTTP request", "method", call.method, "url", next, "page", pageNumber)
+		page, err := call.do(ctx, next)
+		if err != nil {
+			return failed(fmt.Errorf("page %d: %v", pageNumber, err))
+		}
+		bodies = append(bodies, page.Body)
+		statusCodes = append(statusCodes, page.StatusCode)
+		last = page
+
+		if page.StatusCode >= 400 {
+			slog.Warn("HTTP request returned error status", "status_code", page.StatusCode, "page", pageNumber)
+			return engine.TaskResult{
+				Status: "failed",
+				Output: output(page),
+				Error:  fmt.Sprintf("page %d: %s", pageNumber, page.error()),
+			}
+		}
+
+		if next, err = p.next(page, pageNumber); err != nil {
+			return failed(fmt.Errorf("page %d: %v", pageNumber, err))
+		}
+		if next == "" {
+			slog.Info("HTTP request completed successfully", "status_code", page.StatusCode, "pages", pageNumber)
+			return engine.TaskResult{Status: "success", Output: output(page)}
+		}
+		if pageNumber == p.MaxPages {
+			slog.Warn("Pagination stopped at max_pages", "max_pages", p.MaxPages)
+			return engine.TaskResult{Status: "success", Output: output(page)}
+		}
+	}
+}
+
+// first returns the URL of the first page.
+func (p *httpPagination) first(url string) (string, error) {
+	switch p.Type {
+	case paginationPage:
+		params := neturl.Values{p.PageParam: {strconv.Itoa(*p.StartPage)}}
+		if p.SizeParam != "" {
+			params.Set(p.SizeParam, strconv.Itoa(p.PageSize))
+		}
+		return withQuery(url, params)
+	case paginationOffset:
+		return withQuery(url, neturl.Values{p.OffsetParam: {"0"}, p.LimitParam: {strconv.Itoa(p.Limit)}})
+	}
+	return url, nil
+}
+
+// next returns the URL of the page after page, the pageNumber-th one, or ""
+// if it was the last.
+func (p *httpPagination) next(page *httpPage, pageNumber int) (string, error) {
+	switch p.Type {
+	case paginationPage, paginationOffset:
+		count, err := p.itemCount(page)
+		if err != nil {
+			return "", err
+		}
+		if p.Type == paginationPage {
+			if count == 0 || count < p.PageSize {
+				return "", nil
+			}
+			return withQuery(page.url, neturl.Values{p.PageParam: {strconv.Itoa(*p.StartPage + pageNumber)}})
+		}
+		if count == 0 || count < p.Limit {
+			return "", nil
+		}
+		return withQuery(page.url, neturl.Values{p.OffsetParam: {strconv.Itoa(pageNumber * p.Limit)}})
+
+	case paginationCursor:
+		body, _ := page.Body.(map[string]interface{})
+		value, err := engine.ResolvePath(body, p.CursorPath)
+		if err != nil {
+			return "", fmt.Errorf("invalid 'pagination.cursor_path': %v", err)
+		}
+		cursor := cursorString(value)
+		if cursor == "" {
+			return "", nil
+		}
+		return withQuery(page.url, neturl.Values{p.CursorParam: {cursor}})
+
+	case paginationLink:
+		link := nextLink(page.Header)
+		if link == "" {
+			return "", nil
+		}
+		base, err := neturl.Parse(page.url)
+		if err != nil {
+			return "", err
+		}
+		target, err := base.Parse(link)
+		if err != nil {
+			return "", fmt.Errorf("invalid next link %q: %v", link, err)
+		}
+		if !strings.EqualFold(target.Scheme, base.Scheme) || !strings.EqualFold(target.Host, base.Host) {
+			return "", fmt.Errorf("next link %q is on another origin than %s://%s", link, base.Scheme, base.Host)
+		}
+		return target.String(), nil
+	}
+	return "", nil
+}
+
+// itemCount returns the number of items on page.
+func (p *httpPagination) itemCount(page *httpPage) (int, error) {
+	items := page.Body
+	if p.ItemsPath != "" {
+		body, _ := page.Body.(map[string]interface{})
+		var err error
+		if items, err = engine.ResolvePath(body, p.ItemsPath); err != nil {
+			return 0, fmt.Errorf("invalid 'pagination.items_path': %v", err)
+		}
+	}
+	list, ok := items.([]interface{})
+	if !ok {
+		if p.ItemsPath == "" {
+			return 0, fmt.Errorf("page body is not an array; set 'pagination.items_path' to the items")
+		}
+		return 0, fmt.Errorf("no array at '%s' in the page body", p.ItemsPath)
+	}
+	return len(list), nil
+}
+
+// withQuery returns url with params set in its query string, replacing the
+// values it had.
+func withQuery(url string, params neturl.Values) (string, error) {
+	parsed, err := neturl.Parse(url)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	query := parsed.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+// cursorString formats a cursor found in a JSON body; nil, false and empty
+// strings mean there is no next page.
+func cursorString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// nextLink returns the target of the rel="next" link in the Link headers
+// (RFC 8288, formerly RFC 5988), or "" if there is none.
+func nextLink(header http.Header) string {
+	for _, value := range header.Values("Link") {
+		for _, link := range splitLinks(value) {
+			target, params, found := strings.Cut(link, ";")
+			target = strings.TrimSpace(target)
+			if !found || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range strings.Split(params, ";") {
+				name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(strings.TrimSpace(name), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return target[1 : len(target)-1]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// splitLinks splits a Link header value at the commas between links, which
+// may also appear inside their URLs and quoted parameters.
+func splitLinks(value string) []string {
+	var links []string
+	inURL, inQuotes, start := false, false, 0
+	for i, r := range value {
+		switch {
+		case r == '<' && !inQuotes:
+			inURL = true
+		case r == '>' && !inQuotes:
+			inURL = false
+		case r == '"' && !inURL:
+			inQuotes = !inQuotes
+		case r == ',' && !inURL && !inQuotes:
+			links = append(links, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(links, value[start:])
+}
@@ -0,0 +1,310 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagedServer records the query string of each request to a paginated API
+type pagedServer struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func newPagedServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) (*pagedServer, *httptest.Server) {
+	ps := &pagedServer{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ps.mu.Lock()
+		ps.queries = append(ps.queries, r.URL.RawQuery)
+		ps.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return ps, server
+}
+
+// itemRange returns the items first..last of 1..total, as JSON numbers
+func itemRange(first, last, total int) []interface{} {
+	items := []interface{}{}
+	for i := first; i <= last && i <= total; i++ {
+		items = append(items, float64(i))
+	}
+	return items
+}
+
+func queryInt(r *http.Request, name string) int {
+	value, _ := strconv.Atoi(r.URL.Query().Get(name))
+	return value
+}
+
+func executePaginated(t *testing.T, url string, pagination map[string]interface{}) engine.TaskResult {
+	t.Helper()
+	return (&HTTPTask{}).Execute(context.Background(), engine.NewExecutionContext(), map[string]interface{}{
+		"method":     "GET",
+		"url":        url,
+		"pagination": pagination,
+	})
+}
+
+func TestHTTPTask_Pagination_PageNumber(t *testing.T) {
+	ps, server := newPagedServer(t, func(w http.ResponseWriter, r *http.Request) {
+		page, size := queryInt(r, "page"), queryInt(r, "per_page")
+		json.NewEncoder(w).Encode(itemRange((page-1)*size+1, page*size, 5))
+	})
+
+	result := executePaginated(t, server.URL+"/items?sort=asc", map[string]interface{}{
+		"type": "page", "page_size": 2.0, "size_param": "per_page",
+	})
+	require.Equal(t, "success", result.Status, result.Error)
+
+	output := result.Output.(map[string]interface{})
+	assert.Equal(t, []interface{}{
+		[]interface{}{1.0, 2.0},
+		[]interface{}{3.0, 4.0},
+		[]interface{}{5.0},
+	}, output["body"])
+	assert.Equal(t, []int{200, 200, 200}, output["status_codes"])
+	assert.Equal(t, 3, output["pages"])
+	assert.Equal(t, 200, output["status_code"])
+	assert.Equal(t, []string{
+		"page=1&per_page=2&sort=asc",
+		"page=2&per_page=2&sort=asc",
+		"page=3&per_page=2&sort=asc",
+	}, ps.queries)
+}
+
+func TestHTTPTask_Pagination_PageNumberStopsAtEmptyPage(t *testing.T) {
+	ps, server := newPagedServer(t, func(w http.ResponseWriter, r *http.Request) {
+		page := queryInt(r, "p")
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": itemRange(page*10+1, page*10+10, 15)})
+	})
+
+	result := executePaginated(t, server.URL, map[string]interface{}{
+		"type": "page", "page_param": "p", "start_page": 0.0, "items_path": "data",
+	})
+	require.Equal(t, "success", result.Status, result.Error)
+	assert.Equal(t, 3, result.Output.(map[string]interface{})["pages"])
+	assert.Equal(t, []string{"p=0", "p=1", "p=2"}, ps.queries)
+}
+
+func TestHTTPTask_Pagination_OffsetLimit(t *testing.T) {
+	ps, server := newPagedServer(t, func(w http.ResponseWriter, r *http.Request) {
+		offset, limit := queryInt(r, "offset"), queryInt(r, "limit")
+		json.NewEncoder(w).Encode(map[string]interface{}{"results": map[string]interface{}{"items": itemRange(offset+1, offset+limit, 4)}})
+	})
+
+	result := executePaginated(t, server.URL, map[string]interface{}{
+		"type": "offset", "limit": 2.0, "items_path": "results.items",
+	})
+	require.Equal(t, "success", result.Status, result.Error)
+	// The third page is empty: 4 items fill two pages exactly
+	assert.Equal(t, 3, result.Output.(map[string]interface{})["pages"])
+	assert.Equal(t, []string{"limit=2&offset=0", "limit=2&offset=2", "limit=2&offset=4"}, ps.queries)
+}
+
+func TestHTTPTask_Pagination_Cursor(t *testing.T) {
+	cursors := map[string]interface{}{"": "abc", "abc": 1234567.0, "1234567": nil}
+	ps, server := newPagedServer(t, func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("after")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"items": []string{"after " + cursor},
+			"meta":  map[string]interface{}{"next": cursors[cursor]},
+		})
+	})
+
+	result := executePaginated(t, server.URL, map[string]interface{}{
+		"type": "cursor", "cursor_path": "meta.next", "cursor_param": "after",
+	})
+	require.Equal(t, "success", result.Status, result.Error)
+	assert.Equal(t, 3, result.Output.(map[string]interface{})["pages"])
+	assert.Equal(t, []string{"", "after=abc", "after=1234567"}, ps.queries)
+}
+
+func TestHTTPTask_Pagination_LinkHeader(t *testing.T) {
+	var serverURL string
+	ps, server := newPagedServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", `<?page=2>; rel="next", <?page=9>; rel="last"`)
+		case "2":
+			w.Header().Add("Link", `<`+serverURL+`/items?page=1>; rel="prev first"`)
+			w.Header().Add("Link", `<`+serverURL+`/items?page=3>; rel="next"`)
+		}
+		fmt.Fprintf(w, `{"page": %q}`, r.URL.Query().Get("page"))
+	})
+	serverURL = server.URL
+
+	result := executePaginated(t, server.URL+"/items", map[string]interface{}{"type": "link"})
+	require.Equal(t, "success", result.Status, result.Error)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"page": ""},
+		map[string]interface{}{"page": "2"},
+		map[string]interface{}{"page": "3"},
+	}, result.Output.(map[string]interface{})["body"])
+	assert.Equal(t, []string{"", "page=2", "page=3"}, ps.queries)
+}
+
+func TestHTTPTask_Pagination_LinkToAnotherOrigin(t *testing.T) {
+	var leaked []string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = append(leaked, r.Header.Get("Authorization"))
+		w.Write([]byte(`{}`))
+	}))
+	defer other.Close()
+	_, server := newPagedServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", `<`+other.URL+`/items?page=2>; rel="next"`)
+		w.Write([]byte(`{"page": 1}`))
+	})
+
+	result := (&HTTPTask{}).Execute(context.Background(), engine.NewExecutionContext(), map[string]interface{}{
+		"method":     "GET",
+		"url":        server.URL + "/items",
+		"auth":       map[string]interface{}{"type": "bearer", "token": "tok-123"},
+		"pagination": map[string]interface{}{"type": "link"},
+	})
+	assert.Equal(t, "failed", result.Status)
+	assert.Contains(t, result.Error, "page 1: next link")
+	assert.Contains(t, result.Error, "is on another origin")
+	assert.Equal(t, []interface{}{map[string]interface{}{"page": 1.0}}, result.Output.(map[string]interface{})["body"])
+	assert.Empty(t, leaked)
+}
+
+func TestHTTPTask_Pagination_MaxPagesAndDelay(t *testing.T) {
+	ps, server := newPagedServer(t, func(w http.ResponseWriter, r *http.Request) {
+		// Every page links to another one
+		w.Header().Set("Link", fmt.Sprintf(`</?page=%d>; rel="next"`, queryInt(r, "page")+1))
+		w.Write([]byte(`[]`))
+	})
+
+	start := time.Now()
+	result := executePaginated(t, server.URL, map[string]interface{}{"type": "link", "max_pages": 3.0, "delay_ms": 25.0})
+	require.Equal(t, "success", result.Status, result.Error)
+	assert.Equal(t, 3, result.Output.(map[string]interface{})["pages"])
+	assert.Len(t, ps.queries, 3)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestHTTPTask_Pagination_ErrorStatus(t *testing.T) {
+	_, server := newPagedServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if queryInt(r, "page") == 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error": "slow down"}`))
+			return
+		}
+		json.NewEncoder(w).Encode([]int{1})
+	})
+
+	result := executePaginated(t, server.URL, map[string]interface{}{"type": "page"})
+	assert.Equal(t, "failed", result.Status)
+	assert.Equal(t, `page 2: HTTP 429: {"error": "slow down"}`, result.Error)
+
+	// Retry policies can match the failing page's status code
+	output := result.Output.(map[string]interface{})
+	assert.Equal(t, 429, output["status_code"])
+	assert.Equal(t, []int{200, 429}, output["status_codes"])
+}
+
+func TestHTTPTask_Pagination_ConnectionDropped(t *testing.T) {
+	_, server := newPagedServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if queryInt(r, "page") == 2 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+			return
+		}
+		w.Header().Set("X-Page", "1")
+		json.NewEncoder(w).Encode([]int{1})
+	})
+
+	result := executePaginated(t, server.URL, map[string]interface{}{"type": "page"})
+	assert.Equal(t, "failed", result.Status)
+	assert.Contains(t, result.Error, "page 2: request execution failed")
+
+	// The first page is kept
+	output := result.Output.(map[string]interface{})
+	assert.Equal(t, []interface{}{[]interface{}{1.0}}, output["body"])
+	assert.Equal(t, []int{200}, output["status_codes"])
+	assert.Equal(t, 1, output["pages"])
+	assert.Equal(t, 200, output["status_code"])
+	assert.Equal(t, "1", output["headers"].(http.Header).Get("X-Page"))
+}
+
+func TestHTTPTask_Pagination_ItemsNotAnArray(t *testing.T) {
+	_, server := newPagedServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": [1]}`))
+	})
+
+	result := executePaginated(t, server.URL, map[string]interface{}{"type": "page"})
+	assert.Equal(t, "failed", result.Status)
+	assert.Contains(t, result.Error, "set 'pagination.items_path'")
+
+	result = executePaginated(t, server.URL, map[string]interface{}{"type": "offset", "limit": 1.0, "items_path": "items"})
+	assert.Equal(t, "failed", result.Status)
+	assert.Contains(t, result.Error, "no array at 'items'")
+}
+
+func TestParseHTTPPagination(t *testing.T) {
+	tests := []struct {
+		name       string
+		pagination interface{}
+		wantErr    string
+	}{
+		{"link", map[string]interface{}{"type": "link"}, ""},
+		{"not an object", "page", "invalid 'pagination'"},
+		{"missing type", map[string]interface{}{}, "missing 'pagination.type'"},
+		{"unknown type", map[string]interface{}{"type": "token"}, "unsupported pagination type 'token'"},
+		{"unknown field", map[string]interface{}{"type": "page", "per_page": 10.0}, "unknown field \"per_page\""},
+		{"negative max pages", map[string]interface{}{"type": "link", "max_pages": -1.0}, "'pagination.max_pages'"},
+		{"offset without limit", map[string]interface{}{"type": "offset"}, "'pagination.limit'"},
+		{"cursor without path", map[string]interface{}{"type": "cursor"}, "'pagination.cursor_path'"},
+		{"size param without size", map[string]interface{}{"type": "page", "size_param": "per_page"}, "'pagination.page_size'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseHTTPPagination(map[string]interface{}{"pagination": tt.pagination})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	tests := []struct {
+		name  string
+		links []string
+		want  string
+	}{
+		{"none", nil, ""},
+		{"next", []string{`<https://api.example.com/items?page=2>; rel="next"`}, "https://api.example.com/items?page=2"},
+		{"unquoted", []string{`<https://api.example.com/items?page=2>; rel=next`}, "https://api.example.com/items?page=2"},
+		{"among others", []string{`<https://a/?p=1>; rel="prev", <https://a/?p=3&q=a,b>; title="x, y"; rel="next"`}, "https://a/?p=3&q=a,b"},
+		{"multiple rels", []string{`<https://a/?p=3>; rel="next last"`}, "https://a/?p=3"},
+		{"no next", []string{`<https://a/?p=1>; rel="prev"`}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for _, link := range tt.links {
+				header.Add("Link", link)
+			}
+			assert.Equal(t, tt.want, nextLink(header))
+		})
+	}
+}
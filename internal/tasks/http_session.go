@@ -0,0 +1,57 @@
+package tasks
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+)
+
+// httpSession holds the state http_request tasks share within an execution:
+// OAuth2 access tokens and cookies.
+type httpSession struct {
+	mu     sync.Mutex
+	tokens map[string]*oauth2Token
+	jar    http.CookieJar
+}
+
+func newHTTPSession() *httpSession {
+	// cookiejar.New only fails on invalid options
+	jar, _ := cookiejar.New(nil)
+	return &httpSession{tokens: make(map[string]*oauth2Token), jar: jar}
+}
+
+type httpSessionKey struct{}
+
+// sessionFromContext returns the session of the execution ctx belongs to, or
+// nil if InstallHTTPSessions was not called.
+func sessionFromContext(ctx context.Context) *httpSession {
+	session, _ := ctx.Value(httpSessionKey{}).(*httpSession)
+	return session
+}
+
+// InstallHTTPSessions gives each execution of e a session shared by its
+// http_request tasks, including those of its sub-workflows, so that an OAuth2
+// token is fetched once per execution rather than once per request, and the
+// cookies set by a login request are sent by the following ones. Without it
+// every http_request task starts from an empty session.
+func InstallHTTPSessions(e *engine.Engine) {
+	e.AddHook(httpSessionHook{})
+}
+
+// httpSessionHook starts a session for each top-level run; sub-workflow runs
+// inherit their parent's through the context.
+type httpSessionHook struct{}
+
+func (httpSessionHook) BeforeRun(ctx context.Context, run *engine.RunInfo) (context.Context, error) {
+	if sessionFromContext(ctx) != nil {
+		return ctx, nil
+	}
+	return context.WithValue(ctx, httpSessionKey{}, newHTTPSession()), nil
+}
+
+func (httpSessionHook) AfterRun(ctx context.Context, run *engine.RunInfo) error {
+	return nil
+}
@@ -0,0 +1,101 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loginServer sets a session cookie on /login and records the cookie sent to
+// /profile
+type loginServer struct {
+	mu       sync.Mutex
+	logins   int
+	profiles []string
+}
+
+func newLoginServer(t *testing.T) (*loginServer, *httptest.Server) {
+	ls := &loginServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		ls.mu.Lock()
+		defer ls.mu.Unlock()
+		ls.logins++
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: fmt.Sprintf("s%d", ls.logins), Path: "/"})
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/profile", func(w http.ResponseWriter, r *http.Request) {
+		ls.mu.Lock()
+		defer ls.mu.Unlock()
+		cookie, err := r.Cookie("session")
+		if err != nil {
+			ls.profiles = append(ls.profiles, "")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		ls.profiles = append(ls.profiles, cookie.Value)
+		http.SetCookie(w, &http.Cookie{Name: "seen", Value: "1", Path: "/"})
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return ls, server
+}
+
+func TestHTTPTask_Execute_CookiesSharedInSession(t *testing.T) {
+	ls, server := newLoginServer(t)
+	ctx, _ := httpSessionHook{}.BeforeRun(context.Background(), &engine.RunInfo{})
+	task := &HTTPTask{}
+
+	login := task.Execute(ctx, engine.NewExecutionContext(), map[string]interface{}{"method": "POST", "url": server.URL + "/login"})
+	require.Equal(t, "success", login.Status, login.Error)
+	assert.Equal(t, map[string]string{"session": "s1"}, login.Output.(map[string]interface{})["cookies"])
+
+	profile := task.Execute(ctx, engine.NewExecutionContext(), map[string]interface{}{"method": "GET", "url": server.URL + "/profile"})
+	require.Equal(t, "success", profile.Status, profile.Error)
+	assert.Equal(t, map[string]string{"session": "s1", "seen": "1"}, profile.Output.(map[string]interface{})["cookies"])
+
+	// Opting out neither sends nor stores cookies
+	optOut := task.Execute(ctx, engine.NewExecutionContext(), map[string]interface{}{"method": "POST", "url": server.URL + "/profile", "cookies": false})
+	assert.Equal(t, "failed", optOut.Status)
+	assert.Equal(t, map[string]string{}, optOut.Output.(map[string]interface{})["cookies"])
+	optOut = task.Execute(ctx, engine.NewExecutionContext(), map[string]interface{}{"method": "POST", "url": server.URL + "/login", "cookies": false})
+	assert.Equal(t, map[string]string{"session": "s2"}, optOut.Output.(map[string]interface{})["cookies"])
+
+	profile = task.Execute(ctx, engine.NewExecutionContext(), map[string]interface{}{"method": "GET", "url": server.URL + "/profile"})
+	require.Equal(t, "success", profile.Status, profile.Error)
+	assert.Equal(t, []string{"s1", "", "s1"}, ls.profiles)
+}
+
+func TestInstallHTTPSessions_CookieJarPerExecution(t *testing.T) {
+	ls, server := newLoginServer(t)
+	registry := engine.NewRegistry()
+	RegisterHTTPTask(registry)
+	executionEngine := engine.NewEngine(registry)
+	InstallHTTPSessions(executionEngine)
+
+	login := engine.WorkflowDefinition{
+		Name: "login",
+		Tasks: []engine.Task{
+			{ID: "login", Type: "http_request", Config: map[string]interface{}{"method": "POST", "url": server.URL + "/login"}},
+			{ID: "profile", Type: "http_request", Config: map[string]interface{}{"method": "GET", "url": server.URL + "/profile"}, DependsOn: []string{"login"}},
+		},
+	}
+	_, err := executionEngine.Execute(context.Background(), login)
+	require.NoError(t, err)
+
+	// Another execution starts without cookies
+	_, err = executionEngine.Execute(context.Background(), engine.WorkflowDefinition{
+		Name:  "anonymous",
+		Tasks: []engine.Task{{ID: "profile", Type: "http_request", Config: map[string]interface{}{"method": "GET", "url": server.URL + "/profile"}}},
+	})
+	assert.ErrorContains(t, err, "HTTP 401")
+	assert.Equal(t, []string{"s1", ""}, ls.profiles)
+}
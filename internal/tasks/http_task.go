@@ -4,11 +4,15 @@ package tasks
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	neturl "net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
@@ -18,21 +22,54 @@ import (
 
 // HTTPTask executes HTTP requests with support for dynamic body interpolation from ExecutionContext.
 // It supports GET, POST, PUT, DELETE, and PATCH methods with custom headers and request bodies.
-type HTTPTask struct{}
+type HTTPTask struct {
+	// Transport sends the requests (default: http.DefaultTransport)
+	Transport http.RoundTripper
+}
 
 // Execute performs an HTTP request based on the provided configuration.
 // Configuration fields:
 //   - method (string, required): HTTP method (GET, POST, PUT, DELETE, PATCH)
-//   - url (string, required): Target URL
-//   - headers (map[string]interface{}, optional): HTTP headers
-//   - body (string, optional): Request body with template support for context interpolation
+//   - url (string, required): Target URL with the same template support as body
+//   - headers (map[string]interface{}, optional): HTTP headers; values support templates
+//   - query (map[string]interface{}, optional): Query parameters added to the URL,
+//     URL-encoded. String values support templates; an array value adds the
+//     parameter once per element.
+//   - auth (map[string]interface{}, optional): Authentication, one of
+//     {"type": "basic", "username", "password"}, {"type": "bearer", "token"},
+//     {"type": "api_key", "name", "value", "in": "header" or "query"} or
+//     {"type": "oauth2_client_credentials", "token_url", "client_id",
+//     "client_secret", "scope", "client_auth": "basic" or "body"}
+//   - body (string, optional): Request body with template support for context interpolation,
+//     e.g. {{.context.login_result.body.token}}, and the transform task functions.
+//     A key missing from the context fails the task.
 //   - timeout (int, optional): Request timeout in seconds (default: 30)
+//   - pagination (map[string]interface{}, optional): Fetch every page of a
+//     paginated API, see httpPagination
+//   - cookies (bool, optional): Send and store cookies in the execution's
+//     cookie jar (default: true)
+//
+// Cookies are kept in the execution's session (see InstallHTTPSessions), so
+// a login request's session cookie is sent by the following tasks. OAuth2
+// tokens are cached there too, until they expire or a request gets HTTP 401,
+// which is then sent once more with a new token.
+//
+// The request is bound to ctx, so cancelling the execution or exceeding the
+// workflow/task timeout aborts it even before the client timeout.
 //
 // The response is returned in TaskResult.Output with the following structure:
 //   - status_code (int): HTTP status code
 //   - headers (map[string][]string): Response headers
 //   - body (interface{}): Parsed JSON response body (or raw string if not JSON)
-func (h *HTTPTask) Execute(ctx *engine.ExecutionContext, config map[string]interface{}) engine.TaskResult {
+//   - cookies (map[string]string): The jar's cookies for the URL after the
+//     response, or the cookies the response set if cookies is false
+//
+// With pagination, body is the array of every page's parsed body,
+// status_codes (int array) holds their status codes and pages their count;
+// status_code, headers and cookies are those of the last page.
+//
+// Responses with status >= 400 fail the task but still carry the same output.
+func (h *HTTPTask) Execute(ctx context.Context, execCtx *engine.ExecutionContext, config map[string]interface{}) engine.TaskResult {
 	// Validate required configuration
 	method, ok := config["method"].(string)
 	if !ok || method == "" {
@@ -52,6 +89,56 @@ func (h *HTTPTask) Execute(ctx *engine.ExecutionContext, config map[string]inter
 		}
 	}
 
+	// Apply URL interpolation, e.g. {{.context.item.url}} inside a foreach task
+	url, err := h.interpolate("url", url, execCtx)
+	if err != nil {
+		slog.Error("Failed to interpolate URL", "error", err)
+		return engine.TaskResult{
+			Status: "failed",
+			Output: nil,
+			Error:  fmt.Sprintf("url interpolation failed: %v", err),
+		}
+	}
+
+	// Add the query parameters, interpolated the same way
+	query, err := h.interpolateQuery(config, execCtx)
+	if err != nil {
+		slog.Error("Failed to interpolate query", "error", err)
+		return engine.TaskResult{
+			Status: "failed",
+			Output: nil,
+			Error:  err.Error(),
+		}
+	}
+	if len(query) > 0 {
+		if url, err = withQuery(url, query); err != nil {
+			return engine.TaskResult{
+				Status: "failed",
+				Output: nil,
+				Error:  err.Error(),
+			}
+		}
+	}
+
+	// Interpolate header values, e.g. an ID returned by a previous request
+	headers := make(map[string]string)
+	if configHeaders, ok := config["headers"].(map[string]interface{}); ok {
+		for key, value := range configHeaders {
+			strValue, ok := value.(string)
+			if !ok {
+				continue
+			}
+			if headers[key], err = h.interpolate("headers."+key, strValue, execCtx); err != nil {
+				slog.Error("Failed to interpolate header", "header", key, "error", err)
+				return engine.TaskResult{
+					Status: "failed",
+					Output: nil,
+					Error:  fmt.Sprintf("header '%s' interpolation failed: %v", key, err),
+				}
+			}
+		}
+	}
+
 	// Get optional timeout (default 30s)
 	timeout := 30
 	if t, ok := config["timeout"].(int); ok {
@@ -68,7 +155,7 @@ func (h *HTTPTask) Execute(ctx *engine.ExecutionContext, config map[string]inter
 
 	// Apply body interpolation if body is provided
 	if bodyStr != "" {
-		interpolated, err := h.interpolateBody(bodyStr, ctx)
+		interpolated, err := h.interpolate("body", bodyStr, execCtx)
 		if err != nil {
 			slog.Error("Failed to interpolate body", "error", err)
 			return engine.TaskResult{
@@ -80,74 +167,127 @@ func (h *HTTPTask) Execute(ctx *engine.ExecutionContext, config map[string]inter
 		bodyStr = interpolated
 	}
 
-	// Build HTTP request
-	var bodyReader io.Reader
-	if bodyStr != "" {
-		bodyReader = strings.NewReader(bodyStr)
-	}
-
-	req, err := http.NewRequest(strings.ToUpper(method), url, bodyReader)
+	auth, err := parseHTTPAuth(config)
 	if err != nil {
-		slog.Error("Failed to create HTTP request", "error", err)
 		return engine.TaskResult{
 			Status: "failed",
 			Output: nil,
-			Error:  fmt.Sprintf("failed to create request: %v", err),
+			Error:  err.Error(),
 		}
 	}
 
-	// Set headers
-	if headers, ok := config["headers"].(map[string]interface{}); ok {
-		for key, value := range headers {
-			if strValue, ok := value.(string); ok {
-				req.Header.Set(key, strValue)
-			}
+	pagination, err := parseHTTPPagination(config)
+	if err != nil {
+		return engine.TaskResult{
+			Status: "failed",
+			Output: nil,
+			Error:  err.Error(),
 		}
 	}
 
-	// Set default Content-Type for POST/PUT/PATCH with body
-	if bodyStr != "" && req.Header.Get("Content-Type") == "" {
-		req.Header.Set("Content-Type", "application/json")
+	// Execute request with timeout
+	call := &httpCall{
+		client: &http.Client{
+			Transport: h.Transport,
+			Timeout:   time.Duration(timeout) * time.Second,
+		},
+		method:  strings.ToUpper(method),
+		body:    bodyStr,
+		headers: headers,
+		auth:    auth,
+		session: sessionFromContext(ctx),
+	}
+	if call.session == nil {
+		call.session = newHTTPSession()
+	}
+	if useCookies, ok := config["cookies"].(bool); !ok || useCookies {
+		call.client.Jar = call.session.jar
 	}
 
-	// Execute request with timeout
-	client := &http.Client{
-		Timeout: time.Duration(timeout) * time.Second,
+	if pagination != nil {
+		return pagination.run(ctx, call, url)
 	}
 
 	slog.Info("Executing HTTP request", "method", method, "url", url)
-	resp, err := client.Do(req)
+	page, err := call.do(ctx, url)
 	if err != nil {
 		slog.Error("HTTP request failed", "error", err)
 		return engine.TaskResult{
 			Status: "failed",
 			Output: nil,
-			Error:  fmt.Sprintf("request execution failed: %v", err),
+			Error:  err.Error(),
 		}
 	}
-	defer resp.Body.Close()
 
-	// Read response body
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		slog.Error("Failed to read response body", "error", err)
-		return engine.TaskResult{
-			Status: "failed",
-			Output: nil,
-			Error:  fmt.Sprintf("failed to read response: %v", err),
-		}
+	// Build output with response details
+	output := map[string]interface{}{
+		"status_code": page.StatusCode,
+		"headers":     page.Header,
+		"body":        page.Body,
+		"cookies":     page.Cookies,
 	}
 
-	// Check for HTTP error status codes
-	if resp.StatusCode >= 400 {
-		slog.Warn("HTTP request returned error status", "status_code", resp.StatusCode)
+	// Check for HTTP error status codes. The response details are kept in the
+	// output so retry policies can match on status_code.
+	if page.StatusCode >= 400 {
+		slog.Warn("HTTP request returned error status", "status_code", page.StatusCode)
 		return engine.TaskResult{
 			Status: "failed",
-			Output: nil,
-			Error:  fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(respBody)),
+			Output: output,
+			Error:  page.error(),
 		}
 	}
 
+	slog.Info("HTTP request completed successfully", "status_code", page.StatusCode)
+	return engine.TaskResult{
+		Status: "success",
+		Output: output,
+		Error:  "",
+	}
+}
+
+// httpCall holds what the requests of an http_request task have in common;
+// with pagination only the URL changes between them.
+type httpCall struct {
+	client  *http.Client
+	method  string
+	body    string
+	headers map[string]string
+	auth    *httpAuth
+	session *httpSession
+}
+
+// httpPage is a response with its body read and parsed.
+type httpPage struct {
+	url        string // requested, before any auth query parameter
+	StatusCode int
+	Header     http.Header
+	Body       interface{} // parsed JSON, or the raw body as a string
+	// Cookies are the session's cookies for the URL after the response, or
+	// the ones the response set if the task doesn't use the session's cookies
+	Cookies map[string]string
+	raw     []byte
+}
+
+// error describes a response with an error status.
+func (p *httpPage) error() string {
+	return fmt.Sprintf("HTTP %d: %s", p.StatusCode, string(p.raw))
+}
+
+// do sends a request to url and reads the response.
+func (c *httpCall) do(ctx context.Context, url string) (*httpPage, error) {
+	resp, err := c.send(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
 	// Parse JSON response if Content-Type is application/json
 	var parsedBody interface{}
 	contentType := resp.Header.Get("Content-Type")
@@ -160,36 +300,271 @@ func (h *HTTPTask) Execute(ctx *engine.ExecutionContext, config map[string]inter
 		parsedBody = string(respBody)
 	}
 
-	// Build output with response details
-	output := map[string]interface{}{
-		"status_code": resp.StatusCode,
-		"headers":     resp.Header,
-		"body":        parsedBody,
+	cookies := resp.Cookies()
+	if c.client.Jar != nil {
+		cookies = c.client.Jar.Cookies(resp.Request.URL)
+	}
+	cookieValues := make(map[string]string, len(cookies))
+	for _, cookie := range cookies {
+		cookieValues[cookie.Name] = cookie.Value
 	}
 
-	slog.Info("HTTP request completed successfully", "status_code", resp.StatusCode)
-	return engine.TaskResult{
-		Status: "success",
-		Output: output,
-		Error:  "",
+	return &httpPage{
+		url:        url,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       parsedBody,
+		Cookies:    cookieValues,
+		raw:        respBody,
+	}, nil
+}
+
+// send builds and sends the request. A 401 response to a cached OAuth2 token
+// drops the token and sends the request again with a new one.
+func (c *httpCall) send(ctx context.Context, url string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		var bodyReader io.Reader
+		if c.body != "" {
+			bodyReader = strings.NewReader(c.body)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, c.method, url, bodyReader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %v", err)
+		}
+
+		// Set headers
+		for key, value := range c.headers {
+			req.Header.Set(key, value)
+		}
+
+		// Set default Content-Type for POST/PUT/PATCH with body
+		if c.body != "" && req.Header.Get("Content-Type") == "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		fresh := false
+		if c.auth != nil {
+			if fresh, err = c.auth.apply(req, c.client, c.session); err != nil {
+				return nil, fmt.Errorf("authentication failed: %v", err)
+			}
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("request execution failed: %v", err)
+		}
+		if resp.StatusCode != http.StatusUnauthorized || c.auth == nil || c.auth.Type != authOAuth2ClientCredentials || fresh || attempt > 0 {
+			return resp, nil
+		}
+
+		slog.Info("Refreshing OAuth2 token after HTTP 401", "token_url", c.auth.TokenURL)
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		c.session.invalidate(c.auth, req.Header.Get("Authorization"))
 	}
 }
 
-// interpolateBody replaces template variables in the body string with values from ExecutionContext.
-// Template syntax: {{context.key}} where 'key' is a key in the ExecutionContext.
-func (h *HTTPTask) interpolateBody(bodyTemplate string, ctx *engine.ExecutionContext) (string, error) {
+// ContextPathKeys implements engine.ContextPathProvider. The context is only
+// referenced in templates; the pagination paths point into response bodies.
+func (h *HTTPTask) ContextPathKeys() []string {
+	return []string{}
+}
+
+// TemplateKeys implements engine.TemplateProvider.
+func (h *HTTPTask) TemplateKeys() []string {
+	return []string{"url", "headers", "query", "body"}
+}
+
+// ValidateConfig implements engine.ConfigValidator. It checks the required
+// method and url and that the url and body templates parse.
+func (h *HTTPTask) ValidateConfig(config map[string]interface{}) error {
+	if method, ok := config["method"].(string); !ok || method == "" {
+		return fmt.Errorf("missing or invalid 'method' in configuration")
+	}
+	url, ok := config["url"].(string)
+	if !ok || url == "" {
+		return fmt.Errorf("missing or invalid 'url' in configuration")
+	}
+	if headers, exists := config["headers"]; exists {
+		if _, ok := headers.(map[string]interface{}); !ok {
+			return fmt.Errorf("invalid 'headers' in configuration: expected an object")
+		}
+	}
+	if cookies, exists := config["cookies"]; exists {
+		if _, ok := cookies.(bool); !ok {
+			return fmt.Errorf("invalid 'cookies' in configuration: expected a boolean")
+		}
+	}
+	if _, err := parseHTTPAuth(config); err != nil {
+		return err
+	}
+	if _, err := parseHTTPPagination(config); err != nil {
+		return err
+	}
+
+	query, err := queryConfig(config)
+	if err != nil {
+		return err
+	}
+
+	templates := map[string]string{"url": url}
+	if body, ok := config["body"].(string); ok {
+		templates["body"] = body
+	}
+	headers, _ := config["headers"].(map[string]interface{})
+	for key, value := range headers {
+		if text, ok := value.(string); ok {
+			templates["headers."+key] = text
+		}
+	}
+	for key, value := range query {
+		values, ok := value.([]interface{})
+		if !ok {
+			values = []interface{}{value}
+		}
+		for i, item := range values {
+			text, err := queryValue(key, item)
+			if err != nil {
+				return err
+			}
+			templates[fmt.Sprintf("query.%s[%d]", key, i)] = text
+		}
+	}
+	keys := make([]string, 0, len(templates))
+	for key := range templates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		text := templates[key]
+		if _, err := template.New(key).Funcs(httpTemplateFuncs).Funcs(validationFuncs).Parse(text); err != nil {
+			return fmt.Errorf("invalid '%s' template: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// Schema implements engine.SchemaProvider.
+func (h *HTTPTask) Schema() engine.TaskSchema {
+	return engine.TaskSchema{
+		Description: "Performs an HTTP request. Responses with status >= 400 fail the task but still carry the response as output.",
+		Config: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"method", "url"},
+			"properties": map[string]interface{}{
+				"method": map[string]interface{}{
+					"type":        "string",
+					"description": "HTTP method: GET, POST, PUT, DELETE or PATCH",
+				},
+				"url": map[string]interface{}{
+					"type":        "string",
+					"description": "Target URL, may use templates such as {{.context.input.url}}",
+				},
+				"query": map[string]interface{}{
+					"type":        "object",
+					"description": "Query parameters added to the URL; string values may use templates, arrays repeat the parameter",
+				},
+				"headers": map[string]interface{}{
+					"type":                 "object",
+					"description":          "Request headers; values may use templates and reference secrets as {{secret \"name\"}}",
+					"additionalProperties": map[string]interface{}{"type": "string"},
+				},
+				"auth": map[string]interface{}{
+					"type":        "object",
+					"description": "Authentication; values may reference secrets as {{secret \"name\"}}",
+					"required":    []string{"type"},
+					"properties": map[string]interface{}{
+						"type": map[string]interface{}{
+							"type": "string",
+							"enum": []string{authBasic, authBearer, authAPIKey, authOAuth2ClientCredentials},
+						},
+						"username":      map[string]interface{}{"type": "string", "description": "basic: user name"},
+						"password":      map[string]interface{}{"type": "string", "description": "basic: password"},
+						"token":         map[string]interface{}{"type": "string", "description": "bearer: token"},
+						"name":          map[string]interface{}{"type": "string", "description": "api_key: header or query parameter name"},
+						"value":         map[string]interface{}{"type": "string", "description": "api_key: key"},
+						"in":            map[string]interface{}{"type": "string", "enum": []string{"header", "query"}, "description": "api_key: where the key is sent (default: header)"},
+						"token_url":     map[string]interface{}{"type": "string", "description": "oauth2_client_credentials: token endpoint"},
+						"client_id":     map[string]interface{}{"type": "string", "description": "oauth2_client_credentials: client ID"},
+						"client_secret": map[string]interface{}{"type": "string", "description": "oauth2_client_credentials: client secret"},
+						"scope":         map[string]interface{}{"type": "string", "description": "oauth2_client_credentials: space-separated scopes"},
+						"client_auth":   map[string]interface{}{"type": "string", "enum": []string{"basic", "body"}, "description": "oauth2_client_credentials: send the client credentials in an Authorization header (default) or in the form"},
+					},
+				},
+				"body": map[string]interface{}{
+					"type":        "string",
+					"description": "Request body, may use templates such as {{.context.login_result.body.token}}",
+				},
+				"timeout": map[string]interface{}{
+					"type":        "integer",
+					"description": "Request timeout in seconds (default: 30)",
+				},
+				"cookies": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Send and store cookies in the execution's cookie jar (default: true)",
+				},
+				"pagination": map[string]interface{}{
+					"type":        "object",
+					"description": "Fetch every page; body becomes the array of page bodies and status_codes lists their status codes",
+					"required":    []string{"type"},
+					"properties": map[string]interface{}{
+						"type": map[string]interface{}{
+							"type": "string",
+							"enum": []string{paginationPage, paginationOffset, paginationCursor, paginationLink},
+						},
+						"max_pages":    map[string]interface{}{"type": "integer", "description": "Stop after this many pages (default: 10)"},
+						"delay_ms":     map[string]interface{}{"type": "integer", "description": "Delay between two page requests in milliseconds"},
+						"items_path":   map[string]interface{}{"type": "string", "description": "page, offset: path of the items array in the body (default: the body); an empty or short page is the last"},
+						"page_param":   map[string]interface{}{"type": "string", "description": "page: page number query parameter (default: page)"},
+						"start_page":   map[string]interface{}{"type": "integer", "description": "page: first page number (default: 1)"},
+						"page_size":    map[string]interface{}{"type": "integer", "description": "page: items per page; a page with fewer items is the last"},
+						"size_param":   map[string]interface{}{"type": "string", "description": "page: query parameter page_size is sent in"},
+						"offset_param": map[string]interface{}{"type": "string", "description": "offset: offset query parameter (default: offset)"},
+						"limit_param":  map[string]interface{}{"type": "string", "description": "offset: limit query parameter (default: limit)"},
+						"limit":        map[string]interface{}{"type": "integer", "description": "offset: items per page"},
+						"cursor_path":  map[string]interface{}{"type": "string", "description": "cursor: path of the next cursor in the body, e.g. meta.next_cursor"},
+						"cursor_param": map[string]interface{}{"type": "string", "description": "cursor: query parameter the cursor is sent in (default: cursor)"},
+					},
+				},
+			},
+		},
+		Output: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"status_code":  map[string]interface{}{"type": "integer", "description": "HTTP status code"},
+				"headers":      map[string]interface{}{"type": "object", "description": "Response headers"},
+				"body":         map[string]interface{}{"description": "Parsed JSON response body, or the raw body as a string; with pagination, the array of page bodies"},
+				"status_codes": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "integer"}, "description": "With pagination, the status code of each page"},
+				"pages":        map[string]interface{}{"type": "integer", "description": "With pagination, the number of pages fetched"},
+			},
+		},
+	}
+}
+
+// httpTemplateFuncs are the functions available to http_request templates,
+// the same as for transform templates
+var httpTemplateFuncs = createTemplateFuncMap()
+
+// interpolate replaces template variables in text, the value of the config
+// field name, with values from ExecutionContext. Templates use the transform
+// task functions and see the context as .context, e.g. {{.context.key}}. A
+// missing key fails instead of rendering "<no value>"; use
+// {{default "x" (index .context "key")}} for optional values.
+func (h *HTTPTask) interpolate(name, text string, execCtx *engine.ExecutionContext) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
 	// Create template with context data
-	tmpl, err := template.New("body").Parse(bodyTemplate)
+	tmpl, err := template.New(name).Funcs(httpTemplateFuncs).Option("missingkey=error").Parse(text)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse template: %w", err)
 	}
 
-	// Get all context data
-	contextData := ctx.GetAll()
-
 	// Create template data structure
 	data := map[string]interface{}{
-		"context": contextData,
+		"context": execCtx.GetAll(),
 	}
 
 	// Execute template
@@ -201,9 +576,75 @@ func (h *HTTPTask) interpolateBody(bodyTemplate string, ctx *engine.ExecutionCon
 	return buf.String(), nil
 }
 
+// interpolateQuery returns the query config field as URL query parameters.
+// String values are interpolated like the URL; numbers and booleans are
+// formatted, and arrays give the parameter once per element.
+func (h *HTTPTask) interpolateQuery(config map[string]interface{}, execCtx *engine.ExecutionContext) (neturl.Values, error) {
+	entries, err := queryConfig(config)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+
+	query := make(neturl.Values, len(entries))
+	for key, value := range entries {
+		values, ok := value.([]interface{})
+		if !ok {
+			values = []interface{}{value}
+		}
+		query[key] = []string{}
+		for _, item := range values {
+			str, err := queryValue(key, item)
+			if err != nil {
+				return nil, err
+			}
+			if str, err = h.interpolate("query."+key, str, execCtx); err != nil {
+				return nil, fmt.Errorf("query '%s' interpolation failed: %v", key, err)
+			}
+			query[key] = append(query[key], str)
+		}
+	}
+	return query, nil
+}
+
+// queryConfig returns the query config field, or an error if it is not an
+// object.
+func queryConfig(config map[string]interface{}) (map[string]interface{}, error) {
+	raw, exists := config["query"]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+	entries, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid 'query' in configuration: expected an object")
+	}
+	return entries, nil
+}
+
+// queryValue formats a scalar query parameter value.
+func queryValue(key string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("invalid 'query.%s' in configuration: expected a string, number or boolean, or an array of them", key)
+	}
+}
+
 // RegisterHTTPTask registers the HTTP task executor with the provided registry.
 // The task is registered with the type name "http_request".
 func RegisterHTTPTask(registry *engine.Registry) {
-	registry.Register("http_request", &HTTPTask{})
+	RegisterHTTPTaskWithTransport(registry, nil)
+}
+
+// RegisterHTTPTaskWithTransport is RegisterHTTPTask with the transport the
+// task sends its requests through, e.g. one that records metrics.
+func RegisterHTTPTaskWithTransport(registry *engine.Registry, transport http.RoundTripper) {
+	registry.Register("http_request", &HTTPTask{Transport: transport})
 	slog.Info("Registered HTTP task executor", "type", "http_request")
 }
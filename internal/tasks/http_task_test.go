@@ -1,14 +1,17 @@
 package tasks
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHTTPTask_Execute_GET_Success(t *testing.T) {
@@ -29,7 +32,7 @@ func TestHTTPTask_Execute_GET_Success(t *testing.T) {
 		"url":    server.URL,
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.Empty(t, result.Error)
@@ -68,7 +71,7 @@ func TestHTTPTask_Execute_POST_WithBody(t *testing.T) {
 		"body":   `{"key":"test"}`,
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.Empty(t, result.Error)
@@ -97,7 +100,7 @@ func TestHTTPTask_Execute_WithCustomHeaders(t *testing.T) {
 		},
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.Empty(t, result.Error)
@@ -131,12 +134,105 @@ func TestHTTPTask_Execute_BodyInterpolation(t *testing.T) {
 		"body":   `{"user_id":"{{.context.userId}}","email":"{{.context.userEmail}}"}`,
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.Empty(t, result.Error)
 }
 
+func TestHTTPTask_Execute_URLHeaderAndQueryInterpolation(t *testing.T) {
+	var received *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	task := &HTTPTask{}
+	ctx := engine.NewExecutionContext()
+	ctx.Set("fetch_result", map[string]interface{}{"body": map[string]interface{}{"id": 42.0, "token": "abc"}})
+	ctx.Set("search", "café & bar")
+	ctx.Set("tags", []interface{}{"a", "b"})
+
+	config := map[string]interface{}{
+		"method": "GET",
+		"url":    server.URL + "/items/{{.context.fetch_result.body.id}}?fields=all",
+		"headers": map[string]interface{}{
+			"Authorization": "Bearer {{.context.fetch_result.body.token}}",
+			"X-Tags":        `{{join "," .context.tags}}`,
+		},
+		"query": map[string]interface{}{
+			"q":      "{{.context.search}}",
+			"upper":  "{{toUpper .context.search}}",
+			"limit":  10.0,
+			"active": true,
+			"id":     []interface{}{"{{.context.fetch_result.body.id}}", 7.0},
+			"fields": "id",
+		},
+	}
+
+	result := task.Execute(context.Background(), ctx, config)
+
+	require.Equal(t, "success", result.Status, result.Error)
+	require.NotNil(t, received)
+	assert.Equal(t, "/items/42", received.URL.Path)
+	assert.Equal(t, "Bearer abc", received.Header.Get("Authorization"))
+	assert.Equal(t, "a,b", received.Header.Get("X-Tags"))
+	assert.Equal(t, url.Values{
+		"q":      {"café & bar"},
+		"upper":  {"CAFÉ & BAR"},
+		"limit":  {"10"},
+		"active": {"true"},
+		"id":     {"42", "7"},
+		"fields": {"id"},
+	}, received.URL.Query())
+	assert.Contains(t, received.URL.RawQuery, "q=caf%C3%A9+%26+bar")
+}
+
+func TestHTTPTask_Execute_MissingKeyFails(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	task := &HTTPTask{}
+	ctx := engine.NewExecutionContext()
+	ctx.Set("fetch_result", map[string]interface{}{"body": map[string]interface{}{}})
+
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantErr string
+	}{
+		{"url", map[string]interface{}{"url": server.URL + "/items/{{.context.fetch_result.body.id}}"}, `url interpolation failed`},
+		{"header", map[string]interface{}{"url": server.URL, "headers": map[string]interface{}{"X-Id": "{{.context.missing}}"}}, `header 'X-Id' interpolation failed`},
+		{"query", map[string]interface{}{"url": server.URL, "query": map[string]interface{}{"id": "{{.context.missing}}"}}, `query 'id' interpolation failed`},
+		{"body", map[string]interface{}{"url": server.URL, "body": `{"id": "{{.context.missing}}"}`}, `body interpolation failed`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config["method"] = "POST"
+			result := task.Execute(context.Background(), ctx, tt.config)
+			assert.Equal(t, "failed", result.Status)
+			assert.Contains(t, result.Error, tt.wantErr)
+			assert.Contains(t, result.Error, "map has no entry for key")
+			assert.NotContains(t, result.Error, "<no value>")
+		})
+	}
+	assert.Zero(t, requests)
+
+	// Optional values can be looked up with index
+	result := task.Execute(context.Background(), ctx, map[string]interface{}{
+		"method": "GET",
+		"url":    server.URL,
+		"query":  map[string]interface{}{"id": `{{default "none" (index .context.fetch_result.body "id")}}`},
+	})
+	assert.Equal(t, "success", result.Status, result.Error)
+}
+
 func TestHTTPTask_Execute_Timeout(t *testing.T) {
 	// Create slow server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -154,12 +250,39 @@ func TestHTTPTask_Execute_Timeout(t *testing.T) {
 		"timeout": 1, // 1 second timeout
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "failed", result.Status)
 	assert.Contains(t, result.Error, "request execution failed")
 }
 
+func TestHTTPTask_Execute_ContextCancelled(t *testing.T) {
+	// Create slow server
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(3 * time.Second)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	task := &HTTPTask{}
+	ctx := engine.NewExecutionContext()
+
+	config := map[string]interface{}{
+		"method": "GET",
+		"url":    server.URL,
+	}
+
+	reqCtx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	result := task.Execute(reqCtx, ctx, config)
+
+	assert.Equal(t, "failed", result.Status)
+	assert.Contains(t, result.Error, "context deadline exceeded")
+	assert.Less(t, time.Since(start), time.Second)
+}
+
 func TestHTTPTask_Execute_HTTPError_404(t *testing.T) {
 	// Create test server returning 404
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -176,10 +299,15 @@ func TestHTTPTask_Execute_HTTPError_404(t *testing.T) {
 		"url":    server.URL,
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "failed", result.Status)
 	assert.Contains(t, result.Error, "HTTP 404")
+
+	// Response details are kept for retry matching
+	output := result.Output.(map[string]interface{})
+	assert.Equal(t, 404, output["status_code"])
+	assert.Equal(t, "Not Found", output["body"])
 }
 
 func TestHTTPTask_Execute_HTTPError_500(t *testing.T) {
@@ -198,7 +326,7 @@ func TestHTTPTask_Execute_HTTPError_500(t *testing.T) {
 		"url":    server.URL,
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "failed", result.Status)
 	assert.Contains(t, result.Error, "HTTP 500")
@@ -212,7 +340,7 @@ func TestHTTPTask_Execute_MissingMethod(t *testing.T) {
 		"url": "http://example.com",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "failed", result.Status)
 	assert.Contains(t, result.Error, "missing or invalid 'method'")
@@ -226,7 +354,7 @@ func TestHTTPTask_Execute_MissingURL(t *testing.T) {
 		"method": "GET",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "failed", result.Status)
 	assert.Contains(t, result.Error, "missing or invalid 'url'")
@@ -241,7 +369,7 @@ func TestHTTPTask_Execute_InvalidURL(t *testing.T) {
 		"url":    "://invalid-url",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "failed", result.Status)
 	assert.Contains(t, result.Error, "failed to create request")
@@ -264,7 +392,7 @@ func TestHTTPTask_Execute_NonJSONResponse(t *testing.T) {
 		"url":    server.URL,
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 
@@ -289,7 +417,7 @@ func TestHTTPTask_Execute_PUT_Method(t *testing.T) {
 		"body":   `{"update":"data"}`,
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 }
@@ -310,7 +438,7 @@ func TestHTTPTask_Execute_DELETE_Method(t *testing.T) {
 		"url":    server.URL,
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 }
@@ -323,7 +451,7 @@ func TestHTTPTask_InterpolateBody_Success(t *testing.T) {
 
 	template := `{"name":"{{.context.name}}","age":{{.context.age}}}`
 
-	result, err := task.interpolateBody(template, ctx)
+	result, err := task.interpolate("body", template, ctx)
 
 	assert.NoError(t, err)
 	assert.Contains(t, result, `"name":"John"`)
@@ -336,7 +464,7 @@ func TestHTTPTask_InterpolateBody_InvalidTemplate(t *testing.T) {
 
 	template := `{{.context.invalid`
 
-	_, err := task.interpolateBody(template, ctx)
+	_, err := task.interpolate("body", template, ctx)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to parse template")
@@ -352,3 +480,44 @@ func TestHTTPTask_RegisterHTTPTask(t *testing.T) {
 	assert.NotNil(t, executor)
 	assert.IsType(t, &HTTPTask{}, executor)
 }
+
+func TestHTTPTask_ValidateConfig(t *testing.T) {
+	task := &HTTPTask{}
+
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantErr string
+	}{
+		{"valid", map[string]interface{}{"method": "GET", "url": "{{.context.input.url}}", "body": `{"a": 1}`}, ""},
+		{"missing method", map[string]interface{}{"url": "http://example.com"}, "missing or invalid 'method'"},
+		{"missing url", map[string]interface{}{"method": "GET"}, "missing or invalid 'url'"},
+		{"secret reference", map[string]interface{}{"method": "GET", "url": "http://example.com", "headers": map[string]interface{}{"Authorization": `Bearer {{secret "api_token"}}`}}, ""},
+		{"invalid headers", map[string]interface{}{"method": "GET", "url": "http://example.com", "headers": "x"}, "invalid 'headers'"},
+		{"bearer auth", map[string]interface{}{"method": "GET", "url": "http://example.com", "auth": map[string]interface{}{"type": "bearer", "token": "t"}}, ""},
+		{"invalid auth", map[string]interface{}{"method": "GET", "url": "http://example.com", "auth": "t"}, "invalid 'auth'"},
+		{"unknown auth type", map[string]interface{}{"method": "GET", "url": "http://example.com", "auth": map[string]interface{}{"type": "digest"}}, "unsupported auth type 'digest'"},
+		{"incomplete auth", map[string]interface{}{"method": "GET", "url": "http://example.com", "auth": map[string]interface{}{"type": "oauth2_client_credentials", "token_url": "http://example.com/token", "client_id": "c"}}, "missing 'auth.client_secret'"},
+		{"invalid api key location", map[string]interface{}{"method": "GET", "url": "http://example.com", "auth": map[string]interface{}{"type": "api_key", "name": "k", "value": "v", "in": "cookie"}}, "invalid 'auth.in'"},
+		{"invalid pagination", map[string]interface{}{"method": "GET", "url": "http://example.com", "pagination": map[string]interface{}{"type": "cursor"}}, "missing 'pagination.cursor_path'"},
+		{"header template", map[string]interface{}{"method": "GET", "url": "http://example.com", "headers": map[string]interface{}{"X-Id": "{{toUpper .context.id}}"}}, ""},
+		{"query", map[string]interface{}{"method": "GET", "url": "http://example.com", "query": map[string]interface{}{"q": "{{.context.q}}", "ids": []interface{}{1.0, "{{.context.id}}"}}}, ""},
+		{"invalid query", map[string]interface{}{"method": "GET", "url": "http://example.com", "query": "q=1"}, "invalid 'query'"},
+		{"invalid query value", map[string]interface{}{"method": "GET", "url": "http://example.com", "query": map[string]interface{}{"q": map[string]interface{}{}}}, "invalid 'query.q'"},
+		{"invalid header template", map[string]interface{}{"method": "GET", "url": "http://example.com", "headers": map[string]interface{}{"X-Id": "{{.context.id"}}, "invalid 'headers.X-Id' template"},
+		{"invalid query template", map[string]interface{}{"method": "GET", "url": "http://example.com", "query": map[string]interface{}{"ids": []interface{}{"{{end}}"}}}, "invalid 'query.ids[0]' template"},
+		{"invalid url template", map[string]interface{}{"method": "GET", "url": "{{.context.url"}, "invalid 'url' template"},
+		{"invalid body template", map[string]interface{}{"method": "POST", "url": "http://example.com", "body": "{{end}}"}, "invalid 'body' template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := task.ValidateConfig(tt.config)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -1,13 +1,18 @@
 package tasks
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 
 	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHTTPTask_IntegrationWithEngine(t *testing.T) {
@@ -46,11 +51,11 @@ func TestHTTPTask_IntegrationWithEngine(t *testing.T) {
 	}
 
 	// Execute workflow
-	err := eng.Execute(workflow)
+	execCtx, err := eng.Execute(context.Background(), workflow)
 	assert.NoError(t, err)
 
 	// Verify response was stored in context
-	result, exists := eng.GetContext().Get("fetch_user_result")
+	result, exists := execCtx.Get("fetch_user_result")
 	assert.True(t, exists)
 	assert.NotNil(t, result)
 
@@ -118,15 +123,15 @@ func TestHTTPTask_IntegrationWithContextSharing(t *testing.T) {
 	}
 
 	// Execute workflow
-	err := eng.Execute(workflow)
+	execCtx, err := eng.Execute(context.Background(), workflow)
 	assert.NoError(t, err)
 
 	// Verify first task result
-	_, exists := eng.GetContext().Get("prepare_data_result")
+	_, exists := execCtx.Get("prepare_data_result")
 	assert.True(t, exists)
 
 	// Verify HTTP task result
-	httpResult, exists := eng.GetContext().Get("send_request_result")
+	httpResult, exists := execCtx.Get("send_request_result")
 	assert.True(t, exists)
 	assert.NotNil(t, httpResult)
 
@@ -181,12 +186,12 @@ func TestHTTPTask_IntegrationWithSubsequentTasks(t *testing.T) {
 	}
 
 	// Execute workflow
-	err := eng.Execute(workflow)
+	execCtx, err := eng.Execute(context.Background(), workflow)
 	assert.NoError(t, err)
 
 	// Both tasks should have results in context
-	apiResult, exists1 := eng.GetContext().Get("fetch_api_result")
-	processResult, exists2 := eng.GetContext().Get("process_data_result")
+	apiResult, exists1 := execCtx.Get("fetch_api_result")
+	processResult, exists2 := execCtx.Get("process_data_result")
 
 	assert.True(t, exists1)
 	assert.True(t, exists2)
@@ -233,15 +238,63 @@ func TestHTTPTask_IntegrationWithFailure(t *testing.T) {
 	}
 
 	// Execute workflow - should fail
-	err := eng.Execute(workflow)
+	execCtx, err := eng.Execute(context.Background(), workflow)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "HTTP 500")
 
 	// Verify second task did not execute
-	_, exists := eng.GetContext().Get("should_not_run_result")
+	_, exists := execCtx.Get("should_not_run_result")
 	assert.False(t, exists)
 }
 
+func TestHTTPTask_IntegrationWithRetryOnStatusCode(t *testing.T) {
+	// Create test server that is unavailable for the first two requests
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("try again"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	registry := engine.NewRegistry()
+	RegisterHTTPTask(registry)
+	eng := engine.NewEngine(registry)
+
+	workflow := engine.WorkflowDefinition{
+		Name: "test-retry-workflow",
+		Tasks: []engine.Task{
+			{
+				ID:   "flaky_request",
+				Type: "http_request",
+				Config: map[string]interface{}{
+					"method": "GET",
+					"url":    server.URL,
+				},
+				Retry: &engine.RetryPolicy{
+					MaxAttempts: 3,
+					Backoff:     engine.BackoffExponential,
+					DelayMs:     1,
+					RetryOn:     &engine.RetryOn{StatusCodes: []int{503}},
+				},
+			},
+		},
+	}
+
+	execCtx, err := eng.Execute(context.Background(), workflow)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	result, exists := execCtx.Get("flaky_request_result")
+	assert.True(t, exists)
+	output := result.(map[string]interface{})
+	assert.Equal(t, 200, output["status_code"])
+}
+
 func TestTransformTask_IntegrationWithEngine(t *testing.T) {
 	// Create registry and register Transform task
 	registry := engine.NewRegistry()
@@ -249,9 +302,10 @@ func TestTransformTask_IntegrationWithEngine(t *testing.T) {
 
 	// Create engine with registry
 	eng := engine.NewEngine(registry)
+	run := eng.NewRun(nil)
 
 	// Pre-populate context with data
-	eng.GetContext().Set("user_data", map[string]interface{}{
+	run.Context().Set("user_data", map[string]interface{}{
 		"firstName": "Alice",
 		"lastName":  "Smith",
 		"age":       30,
@@ -274,11 +328,12 @@ func TestTransformTask_IntegrationWithEngine(t *testing.T) {
 	}
 
 	// Execute workflow
-	err := eng.Execute(workflow)
+	err := run.Execute(context.Background(), workflow)
+	execCtx := run.Context()
 	assert.NoError(t, err)
 
 	// Verify transformed data stored in context
-	result, exists := eng.GetContext().Get("reshape_user_result")
+	result, exists := execCtx.Get("reshape_user_result")
 	assert.True(t, exists)
 	assert.NotNil(t, result)
 
@@ -336,16 +391,16 @@ func TestHTTPAndTransform_IntegrationWorkflow(t *testing.T) {
 	}
 
 	// Execute workflow
-	err := eng.Execute(workflow)
+	execCtx, err := eng.Execute(context.Background(), workflow)
 	assert.NoError(t, err)
 
 	// Verify HTTP task result
-	httpResult, exists := eng.GetContext().Get("fetch_users_result")
+	httpResult, exists := execCtx.Get("fetch_users_result")
 	assert.True(t, exists)
 	assert.NotNil(t, httpResult)
 
 	// Verify Transform task result
-	transformResult, exists := eng.GetContext().Get("extract_names_result")
+	transformResult, exists := execCtx.Get("extract_names_result")
 	assert.True(t, exists)
 	assert.NotNil(t, transformResult)
 
@@ -414,11 +469,11 @@ func TestHTTPAndTransform_ComplexDataReshaping(t *testing.T) {
 	}
 
 	// Execute workflow
-	err := eng.Execute(workflow)
+	execCtx, err := eng.Execute(context.Background(), workflow)
 	assert.NoError(t, err)
 
 	// Verify Transform result
-	transformResult, exists := eng.GetContext().Get("flatten_profile_result")
+	transformResult, exists := execCtx.Get("flatten_profile_result")
 	assert.True(t, exists)
 
 	flattened := transformResult.(map[string]interface{})
@@ -434,13 +489,14 @@ func TestTransformTask_IntegrationWithMultipleContextSources(t *testing.T) {
 
 	// Create engine
 	eng := engine.NewEngine(registry)
+	run := eng.NewRun(nil)
 
 	// Populate context with multiple data sources
-	eng.GetContext().Set("user_info", map[string]interface{}{
+	run.Context().Set("user_info", map[string]interface{}{
 		"name": "Alice",
 		"id":   123,
 	})
-	eng.GetContext().Set("order_info", map[string]interface{}{
+	run.Context().Set("order_info", map[string]interface{}{
 		"total":    99.99,
 		"currency": "USD",
 	})
@@ -462,11 +518,12 @@ func TestTransformTask_IntegrationWithMultipleContextSources(t *testing.T) {
 	}
 
 	// Execute workflow
-	err := eng.Execute(workflow)
+	err := run.Execute(context.Background(), workflow)
+	execCtx := run.Context()
 	assert.NoError(t, err)
 
 	// Verify combined result
-	result, exists := eng.GetContext().Get("combine_data_result")
+	result, exists := execCtx.Get("combine_data_result")
 	assert.True(t, exists)
 
 	combined := result.(map[string]interface{})
@@ -521,11 +578,11 @@ func TestTransformTask_IntegrationWithCustomFunctions(t *testing.T) {
 	}
 
 	// Execute workflow
-	err := eng.Execute(workflow)
+	execCtx, err := eng.Execute(context.Background(), workflow)
 	assert.NoError(t, err)
 
 	// Verify transform with custom functions
-	result, exists := eng.GetContext().Get("transform_data_result")
+	result, exists := execCtx.Get("transform_data_result")
 	assert.True(t, exists)
 
 	transformed := result.(map[string]interface{})
@@ -540,6 +597,7 @@ func TestHTMLParserTask_IntegrationWithEngine(t *testing.T) {
 
 	// Create engine
 	eng := engine.NewEngine(registry)
+	run := eng.NewRun(nil)
 
 	// Pre-populate context with HTML content
 	htmlContent := `
@@ -554,7 +612,7 @@ func TestHTMLParserTask_IntegrationWithEngine(t *testing.T) {
 		</body>
 	</html>
 	`
-	eng.GetContext().Set("html_data", htmlContent)
+	run.Context().Set("html_data", htmlContent)
 
 	// Create workflow with HTML Parser task
 	workflow := engine.WorkflowDefinition{
@@ -582,11 +640,12 @@ func TestHTMLParserTask_IntegrationWithEngine(t *testing.T) {
 	}
 
 	// Execute workflow
-	err := eng.Execute(workflow)
+	err := run.Execute(context.Background(), workflow)
+	execCtx := run.Context()
 	assert.NoError(t, err)
 
 	// Verify parsed data stored in context
-	result, exists := eng.GetContext().Get("parse_html_result")
+	result, exists := execCtx.Get("parse_html_result")
 	assert.True(t, exists)
 	assert.NotNil(t, result)
 
@@ -683,16 +742,16 @@ func TestHTTPAndHTMLParser_IntegrationWorkflow(t *testing.T) {
 	}
 
 	// Execute workflow
-	err := eng.Execute(workflow)
+	execCtx, err := eng.Execute(context.Background(), workflow)
 	assert.NoError(t, err)
 
 	// Verify HTTP task result
-	httpResult, exists := eng.GetContext().Get("fetch_page_result")
+	httpResult, exists := execCtx.Get("fetch_page_result")
 	assert.True(t, exists)
 	assert.NotNil(t, httpResult)
 
 	// Verify HTML Parser task result
-	parseResult, exists := eng.GetContext().Get("parse_items_result")
+	parseResult, exists := execCtx.Get("parse_items_result")
 	assert.True(t, exists)
 	assert.NotNil(t, parseResult)
 
@@ -796,19 +855,19 @@ func TestHTTPHTMLParserTransform_CompleteDataPipeline(t *testing.T) {
 	}
 
 	// Execute workflow
-	err := eng.Execute(workflow)
+	execCtx, err := eng.Execute(context.Background(), workflow)
 	assert.NoError(t, err)
 
 	// Verify all task results exist
-	httpResult, exists := eng.GetContext().Get("fetch_html_result")
+	httpResult, exists := execCtx.Get("fetch_html_result")
 	assert.True(t, exists)
 	assert.NotNil(t, httpResult)
 
-	parseResult, exists := eng.GetContext().Get("extract_products_result")
+	parseResult, exists := execCtx.Get("extract_products_result")
 	assert.True(t, exists)
 	assert.NotNil(t, parseResult)
 
-	transformResult, exists := eng.GetContext().Get("format_output_result")
+	transformResult, exists := execCtx.Get("format_output_result")
 	assert.True(t, exists)
 	assert.NotNil(t, transformResult)
 
@@ -949,11 +1008,11 @@ func TestHTMLParserTask_IntegrationWithRealWorldHTML(t *testing.T) {
 	}
 
 	// Execute workflow
-	err := eng.Execute(workflow)
+	execCtx, err := eng.Execute(context.Background(), workflow)
 	assert.NoError(t, err)
 
 	// Verify extracted listing data
-	listingData, exists := eng.GetContext().Get("extract_listing_data_result")
+	listingData, exists := execCtx.Get("extract_listing_data_result")
 	assert.True(t, exists)
 
 	results := listingData.([]map[string]any)
@@ -997,3 +1056,133 @@ func TestHTMLParserTask_IntegrationWithRealWorldHTML(t *testing.T) {
 	assert.Equal(t, "/listings/123", urls[0])
 	assert.Equal(t, "/listings/456", urls[1])
 }
+
+func TestHTTPAndHTMLParser_IntegrationForEachDetailPages(t *testing.T) {
+	// Listing page links to detail pages that return JSON
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`
+				<html><body>
+					<a class="listing" href="/listings/1">One</a>
+					<a class="listing" href="/listings/2">Two</a>
+					<a class="listing" href="/listings/3">Three</a>
+				</body></html>
+			`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"path": r.URL.Path})
+	}))
+	defer server.Close()
+
+	registry := engine.NewRegistry()
+	RegisterHTTPTask(registry)
+	RegisterHTMLParserTask(registry)
+	eng := engine.NewEngine(registry)
+
+	workflow := engine.WorkflowDefinition{
+		Name: "fetch-listing-details",
+		Tasks: []engine.Task{
+			{
+				ID:   "fetch_listings",
+				Type: "http_request",
+				Config: map[string]interface{}{
+					"method": "GET",
+					"url":    server.URL,
+				},
+			},
+			{
+				ID:   "parse_listings",
+				Type: "html_parser",
+				Config: map[string]interface{}{
+					"html_source": "fetch_listings_result",
+					"selectors": []interface{}{
+						map[string]interface{}{
+							"name":      "links",
+							"selector":  ".listing",
+							"attribute": "href",
+							"multiple":  true,
+						},
+					},
+				},
+			},
+			{
+				ID:   "fetch_details",
+				Type: "http_request",
+				Config: map[string]interface{}{
+					"method": "GET",
+					"url":    server.URL + "{{.context.item}}",
+				},
+				ForEach: &engine.ForEach{Items: "parse_listings_result[0].links", Parallelism: 2},
+			},
+		},
+	}
+
+	execCtx, err := eng.Execute(context.Background(), workflow)
+	assert.NoError(t, err)
+
+	result, exists := execCtx.Get("fetch_details_result")
+	assert.True(t, exists)
+	details := result.([]interface{})
+	assert.Len(t, details, 3)
+	for i, path := range []string{"/listings/1", "/listings/2", "/listings/3"} {
+		body := details[i].(map[string]interface{})["body"].(map[string]interface{})
+		assert.Equal(t, path, body["path"])
+	}
+}
+
+func TestValidate_TestdataWorkflows(t *testing.T) {
+	registry := engine.NewRegistry()
+	RegisterHTTPTask(registry)
+	RegisterTransformTask(registry)
+	RegisterHTMLParserTask(registry)
+	RegisterWorkflowTask(registry, newMemoryWorkflowRepository())
+
+	paths, err := filepath.Glob("../../testdata/workflows/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+
+			var workflow engine.WorkflowDefinition
+			require.NoError(t, json.Unmarshal(data, &workflow))
+			assert.NoError(t, engine.Validate(workflow, registry))
+		})
+	}
+}
+
+func TestTaskSchemas_BuiltInTasks(t *testing.T) {
+	registry := engine.NewRegistry()
+	RegisterHTTPTask(registry)
+	RegisterTransformTask(registry)
+	RegisterHTMLParserTask(registry)
+	RegisterWorkflowTask(registry, newMemoryWorkflowRepository())
+
+	for _, info := range registry.DescribeAll() {
+		require.NotNil(t, info.Schema, info.Type)
+		assert.NotEmpty(t, info.Schema.Description, info.Type)
+		assert.Equal(t, "object", info.Schema.Config["type"], info.Type)
+	}
+
+	err := engine.Validate(engine.WorkflowDefinition{
+		Name: "bad-configs",
+		Tasks: []engine.Task{
+			{ID: "fetch", Type: "http_request", Config: map[string]interface{}{
+				"method": "GET", "url": "http://example.com", "timeout": "30", "headers": map[string]interface{}{"X-Retry": 1},
+			}},
+			{ID: "parse", Type: "html_parser", Config: map[string]interface{}{
+				"html_source": "fetch_result", "selectors": []interface{}{map[string]interface{}{"name": "title", "multiple": "yes"}},
+			}},
+		},
+	}, registry)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "config.headers.X-Retry must be of type string (got number)")
+	assert.Contains(t, err.Error(), "config.timeout must be of type integer (got string)")
+	assert.Contains(t, err.Error(), "config.selectors[0].selector is required")
+	assert.Contains(t, err.Error(), "config.selectors[0].multiple must be of type boolean (got string)")
+}
@@ -4,6 +4,7 @@ package tasks
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -34,7 +35,7 @@ func NewTransformTask() *TransformTask {
 //   - output_format (string, optional): "json" or "string" (default: "json")
 //
 // The transformed data is returned in TaskResult.Output.
-func (t *TransformTask) Execute(ctx *engine.ExecutionContext, config map[string]interface{}) engine.TaskResult {
+func (t *TransformTask) Execute(ctx context.Context, execCtx *engine.ExecutionContext, config map[string]interface{}) engine.TaskResult {
 	// Extract and validate configuration
 	templateStr, ok := config["template"].(string)
 	if !ok || templateStr == "" {
@@ -49,7 +50,7 @@ func (t *TransformTask) Execute(ctx *engine.ExecutionContext, config map[string]
 	var inputData interface{}
 	if dataSource, ok := config["data_source"].(string); ok && dataSource != "" {
 		// Load specific key from context
-		data, exists := ctx.Get(dataSource)
+		data, exists := execCtx.Get(dataSource)
 		if !exists {
 			slog.Warn("Data source not found in context", "source", dataSource)
 			inputData = map[string]interface{}{} // Empty map if not found
@@ -58,7 +59,7 @@ func (t *TransformTask) Execute(ctx *engine.ExecutionContext, config map[string]
 		}
 	} else {
 		// Use entire context
-		inputData = ctx.GetAll()
+		inputData = execCtx.GetAll()
 	}
 
 	// Get output format (optional - defaults to "json")
@@ -118,6 +119,64 @@ func (t *TransformTask) Execute(ctx *engine.ExecutionContext, config map[string]
 	}
 }
 
+// ValidateConfig implements engine.ConfigValidator. It checks that the
+// template is present and parses with the transform functions.
+func (t *TransformTask) ValidateConfig(config map[string]interface{}) error {
+	templateStr, ok := config["template"].(string)
+	if !ok || templateStr == "" {
+		return fmt.Errorf("missing or invalid 'template' in configuration")
+	}
+	if _, err := template.New("transform").Funcs(t.funcMap).Funcs(validationFuncs).Parse(templateStr); err != nil {
+		return fmt.Errorf("failed to parse template: %v", err)
+	}
+	return nil
+}
+
+// ContextPathKeys implements engine.ContextPathProvider.
+func (t *TransformTask) ContextPathKeys() []string {
+	return []string{"data_source"}
+}
+
+// TemplateKeys implements engine.TemplateProvider.
+func (t *TransformTask) TemplateKeys() []string {
+	return []string{"template"}
+}
+
+// Schema implements engine.SchemaProvider.
+func (t *TransformTask) Schema() engine.TaskSchema {
+	return engine.TaskSchema{
+		Description: "Reshapes context data with a Go template. Functions: toUpper, toLower, trim, join, toJSON, default.",
+		Config: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"template"},
+			"properties": map[string]interface{}{
+				"template": map[string]interface{}{
+					"type":        "string",
+					"description": "Go template applied to the input data",
+				},
+				"data_source": map[string]interface{}{
+					"type":        "string",
+					"description": "Context key used as template data; the whole context is used if omitted",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "\"json\" parses the rendered template as JSON, \"string\" returns it as is (default: \"json\")",
+				},
+			},
+		},
+		Output: map[string]interface{}{
+			"description": "The parsed JSON value for output_format \"json\" (the raw string if it is not valid JSON), otherwise the rendered string",
+		},
+	}
+}
+
+// validationFuncs are added to the template functions when validating
+// configs. Secret references ({{secret "name"}}) are resolved before tasks
+// execute (see package secrets), so executors never see them.
+var validationFuncs = template.FuncMap{
+	"secret": func(name string) string { return "" },
+}
+
 // createTemplateFuncMap creates custom template functions for data transformation.
 // Available functions:
 //   - toUpper: Convert string to uppercase
@@ -1,6 +1,7 @@
 package tasks
 
 import (
+	"context"
 	"testing"
 
 	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
@@ -23,7 +24,7 @@ func TestTransformTask_SimpleFieldExtraction(t *testing.T) {
 		"output_format": "string",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.Empty(t, result.Error)
@@ -47,7 +48,7 @@ func TestTransformTask_ArrayTransformation(t *testing.T) {
 		"output_format": "json",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.Empty(t, result.Error)
@@ -79,7 +80,7 @@ func TestTransformTask_DataReshaping(t *testing.T) {
 		"output_format": "json",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.Empty(t, result.Error)
@@ -104,7 +105,7 @@ func TestTransformTask_CustomFunction_ToUpper(t *testing.T) {
 		"output_format": "string",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.Empty(t, result.Error)
@@ -125,7 +126,7 @@ func TestTransformTask_CustomFunction_ToLower(t *testing.T) {
 		"output_format": "string",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.Equal(t, "alice", result.Output)
@@ -145,7 +146,7 @@ func TestTransformTask_CustomFunction_Trim(t *testing.T) {
 		"output_format": "string",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.Equal(t, "hello world", result.Output)
@@ -164,7 +165,7 @@ func TestTransformTask_CustomFunction_Join(t *testing.T) {
 		"output_format": "string",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.Empty(t, result.Error)
@@ -188,7 +189,7 @@ func TestTransformTask_CustomFunction_ToJSON(t *testing.T) {
 		"output_format": "string",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.Contains(t, result.Output, `"name":"Alice"`)
@@ -209,7 +210,7 @@ func TestTransformTask_CustomFunction_Default(t *testing.T) {
 		"output_format": "string",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.Equal(t, "Unknown", result.Output)
@@ -229,7 +230,7 @@ func TestTransformTask_CustomFunction_DefaultWithValue(t *testing.T) {
 		"output_format": "string",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.Equal(t, "Alice", result.Output)
@@ -249,7 +250,7 @@ func TestTransformTask_AllContextAccess(t *testing.T) {
 		"output_format": "json",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.Empty(t, result.Error)
@@ -268,7 +269,7 @@ func TestTransformTask_InvalidTemplate(t *testing.T) {
 		"template": `{{.invalid syntax`,
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "failed", result.Status)
 	assert.Contains(t, result.Error, "failed to parse template")
@@ -283,7 +284,7 @@ func TestTransformTask_MissingTemplate(t *testing.T) {
 		"data_source": "something",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "failed", result.Status)
 	assert.Contains(t, result.Error, "template")
@@ -301,7 +302,7 @@ func TestTransformTask_MissingDataSource(t *testing.T) {
 		"output_format": "string",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	// Should succeed but with empty data (graceful handling)
 	assert.Equal(t, "success", result.Status)
@@ -323,7 +324,7 @@ func TestTransformTask_TemplateExecutionError(t *testing.T) {
 		"output_format": "string",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "failed", result.Status)
 	assert.Contains(t, result.Error, "failed to execute template")
@@ -344,7 +345,7 @@ func TestTransformTask_JSONOutputFormat(t *testing.T) {
 		"output_format": "json",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 
@@ -369,7 +370,7 @@ func TestTransformTask_StringOutputFormat(t *testing.T) {
 		"output_format": "string",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	assert.Equal(t, "Name: Alice, Age: 30", result.Output)
@@ -389,7 +390,7 @@ func TestTransformTask_InvalidJSON_ReturnsAsString(t *testing.T) {
 		"output_format": "json",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 	// Should return as string since it's not valid JSON
@@ -416,7 +417,7 @@ func TestTransformTask_ComplexNesting(t *testing.T) {
 		"output_format": "json",
 	}
 
-	result := task.Execute(ctx, config)
+	result := task.Execute(context.Background(), ctx, config)
 
 	assert.Equal(t, "success", result.Status)
 
@@ -465,3 +466,12 @@ func TestTransformTask_NewTransformTask(t *testing.T) {
 	_, hasDefault := task.funcMap["default"]
 	assert.True(t, hasDefault)
 }
+
+func TestTransformTask_ValidateConfig(t *testing.T) {
+	task := NewTransformTask()
+
+	assert.NoError(t, task.ValidateConfig(map[string]interface{}{"template": `{{toUpper .name}}`}))
+	assert.NoError(t, task.ValidateConfig(map[string]interface{}{"template": `{"token": "{{secret "api_token"}}"}`}))
+	assert.ErrorContains(t, task.ValidateConfig(map[string]interface{}{}), "missing or invalid 'template'")
+	assert.ErrorContains(t, task.ValidateConfig(map[string]interface{}{"template": `{{unknownFunc .}}`}), "failed to parse template")
+}
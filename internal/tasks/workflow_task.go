@@ -0,0 +1,220 @@
+// Package tasks provides concrete implementations of TaskExecutor for various workflow operations.
+// Each task type (HTTP request, transform, HTML parser, etc.) is implemented as a separate executor.
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/google/uuid"
+)
+
+// WorkflowTask implements TaskExecutor for running another stored workflow as
+// a sub-workflow. It lets shared steps (e.g. login + fetch) live in one workflow.
+type WorkflowTask struct {
+	workflows repository.WorkflowRepository
+}
+
+// NewWorkflowTask creates a new Workflow task executor that loads workflows from the given repository.
+func NewWorkflowTask(workflows repository.WorkflowRepository) *WorkflowTask {
+	return &WorkflowTask{workflows: workflows}
+}
+
+// Execute implements the TaskExecutor interface for sub-workflows.
+// Configuration fields:
+//   - workflow (string, required): Name or ID of the stored workflow to run
+//   - inputs (map[string]interface{}, optional): Child input name -> parent context path.
+//     The values are available to the child under the "input" context key.
+//   - outputs (map[string]interface{}, optional): Output name -> child context path.
+//     If omitted, the child's whole context is returned.
+//
+// Paths use the engine path syntax, e.g. "login_result.body.token".
+// The child runs with engine.ExecuteChild, so it is logged as a linked child
+// execution and nesting is limited to engine.MaxWorkflowDepth.
+//
+// The selected outputs are returned in TaskResult.Output as a map.
+func (w *WorkflowTask) Execute(ctx context.Context, execCtx *engine.ExecutionContext, config map[string]interface{}) engine.TaskResult {
+	// Validate workflow reference
+	ref, ok := config["workflow"].(string)
+	if !ok || ref == "" {
+		return engine.TaskResult{
+			Status: "failed",
+			Output: nil,
+			Error:  "missing or invalid 'workflow' in configuration",
+		}
+	}
+
+	inputs, err := pathMapping(config, "inputs")
+	if err != nil {
+		return engine.TaskResult{Status: "failed", Output: nil, Error: err.Error()}
+	}
+	outputs, err := pathMapping(config, "outputs")
+	if err != nil {
+		return engine.TaskResult{Status: "failed", Output: nil, Error: err.Error()}
+	}
+
+	// Load the child workflow by ID or name
+	workflow, err := w.loadWorkflow(ref)
+	if err != nil {
+		return engine.TaskResult{
+			Status: "failed",
+			Output: nil,
+			Error:  fmt.Sprintf("failed to load workflow '%s': %v", ref, err),
+		}
+	}
+
+	definition, err := workflow.ToWorkflowDefinition()
+	if err != nil {
+		return engine.TaskResult{
+			Status: "failed",
+			Output: nil,
+			Error:  fmt.Sprintf("invalid definition for workflow '%s': %v", ref, err),
+		}
+	}
+
+	// Map the parent context onto the child's inputs
+	input, err := selectPaths(execCtx.GetAll(), inputs)
+	if err != nil {
+		return engine.TaskResult{
+			Status: "failed",
+			Output: nil,
+			Error:  fmt.Sprintf("failed to map inputs: %v", err),
+		}
+	}
+
+	childCtx, err := engine.ExecuteChild(ctx, *definition, workflow.ID, input)
+	if err != nil {
+		slog.Error("Sub-workflow failed", "workflow", workflow.Name, "error", err)
+		return engine.TaskResult{
+			Status: "failed",
+			Output: nil,
+			Error:  fmt.Sprintf("sub-workflow '%s' failed: %v", workflow.Name, err),
+		}
+	}
+
+	// Select the child's outputs
+	childData := childCtx.GetAll()
+	if len(outputs) == 0 {
+		return engine.TaskResult{Status: "success", Output: childData, Error: ""}
+	}
+
+	output, err := selectPaths(childData, outputs)
+	if err != nil {
+		return engine.TaskResult{
+			Status: "failed",
+			Output: nil,
+			Error:  fmt.Sprintf("failed to map outputs: %v", err),
+		}
+	}
+
+	slog.Info("Sub-workflow completed successfully", "workflow", workflow.Name)
+	return engine.TaskResult{
+		Status: "success",
+		Output: output,
+		Error:  "",
+	}
+}
+
+// ValidateConfig implements engine.ConfigValidator. It checks the workflow
+// reference and the shape of the inputs and outputs mappings; whether the
+// referenced workflow exists is only known at run time.
+func (w *WorkflowTask) ValidateConfig(config map[string]interface{}) error {
+	if ref, ok := config["workflow"].(string); !ok || ref == "" {
+		return fmt.Errorf("missing or invalid 'workflow' in configuration")
+	}
+	if _, err := pathMapping(config, "inputs"); err != nil {
+		return err
+	}
+	_, err := pathMapping(config, "outputs")
+	return err
+}
+
+// ContextPathKeys implements engine.ContextPathProvider. The outputs are
+// paths into the child's context, not the parent's.
+func (w *WorkflowTask) ContextPathKeys() []string {
+	return []string{"inputs"}
+}
+
+// Schema implements engine.SchemaProvider.
+func (w *WorkflowTask) Schema() engine.TaskSchema {
+	pathMap := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":                 "object",
+			"description":          description,
+			"additionalProperties": map[string]interface{}{"type": "string"},
+		}
+	}
+	return engine.TaskSchema{
+		Description: "Runs another stored workflow as a linked sub-workflow.",
+		Config: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"workflow"},
+			"properties": map[string]interface{}{
+				"workflow": map[string]interface{}{
+					"type":        "string",
+					"description": "Name or ID of the stored workflow",
+				},
+				"inputs":  pathMap("Child input name -> parent context path"),
+				"outputs": pathMap("Output name -> child context path; the whole child context is returned if omitted"),
+			},
+		},
+		Output: map[string]interface{}{
+			"type":        "object",
+			"description": "The selected outputs, or the child's whole context",
+		},
+	}
+}
+
+// loadWorkflow looks a workflow up by ID if ref is a UUID, by name otherwise.
+func (w *WorkflowTask) loadWorkflow(ref string) (*repository.Workflow, error) {
+	if id, err := uuid.Parse(ref); err == nil {
+		return w.workflows.GetByID(id)
+	}
+	return w.workflows.GetByName(ref)
+}
+
+// pathMapping reads an optional name -> path map from the configuration.
+func pathMapping(config map[string]interface{}, key string) (map[string]string, error) {
+	raw, exists := config[key]
+	if !exists || raw == nil {
+		return nil, nil
+	}
+
+	entries, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid '%s' in configuration: expected an object", key)
+	}
+
+	mapping := make(map[string]string, len(entries))
+	for name, value := range entries {
+		path, ok := value.(string)
+		if !ok || path == "" {
+			return nil, fmt.Errorf("invalid '%s.%s' in configuration: expected a context path", key, name)
+		}
+		mapping[name] = path
+	}
+	return mapping, nil
+}
+
+// selectPaths builds a map of name -> value of path in data.
+func selectPaths(data map[string]interface{}, mapping map[string]string) (map[string]interface{}, error) {
+	selected := make(map[string]interface{}, len(mapping))
+	for name, path := range mapping {
+		value, err := engine.ResolvePath(data, path)
+		if err != nil {
+			return nil, err
+		}
+		selected[name] = value
+	}
+	return selected, nil
+}
+
+// RegisterWorkflowTask registers the Workflow task executor with the provided registry.
+// The task is registered with the type name "workflow".
+func RegisterWorkflowTask(registry *engine.Registry, workflows repository.WorkflowRepository) {
+	registry.Register("workflow", NewWorkflowTask(workflows))
+	slog.Info("Registered Workflow task executor", "type", "workflow")
+}
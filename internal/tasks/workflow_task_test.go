@@ -0,0 +1,233 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryWorkflowRepository is an in-memory WorkflowRepository for tests.
+type memoryWorkflowRepository struct {
+	workflows map[uuid.UUID]*repository.Workflow
+}
+
+func newMemoryWorkflowRepository() *memoryWorkflowRepository {
+	return &memoryWorkflowRepository{workflows: make(map[uuid.UUID]*repository.Workflow)}
+}
+
+func (m *memoryWorkflowRepository) add(t *testing.T, def engine.WorkflowDefinition) *repository.Workflow {
+	t.Helper()
+	workflow, err := repository.FromWorkflowDefinition(def.Name, &def)
+	require.NoError(t, err)
+	workflow.ID = uuid.New()
+	m.workflows[workflow.ID] = workflow
+	return workflow
+}
+
+func (m *memoryWorkflowRepository) Create(workflow *repository.Workflow) error {
+	m.workflows[workflow.ID] = workflow
+	return nil
+}
+
+func (m *memoryWorkflowRepository) GetByID(id uuid.UUID) (*repository.Workflow, error) {
+	if workflow, exists := m.workflows[id]; exists {
+		return workflow, nil
+	}
+	return nil, fmt.Errorf("workflow not found: %s", id)
+}
+
+func (m *memoryWorkflowRepository) GetByName(name string) (*repository.Workflow, error) {
+	for _, workflow := range m.workflows {
+		if workflow.Name == name {
+			return workflow, nil
+		}
+	}
+	return nil, fmt.Errorf("workflow not found: %s", name)
+}
+
+func (m *memoryWorkflowRepository) GetAll() ([]*repository.Workflow, error) {
+	var all []*repository.Workflow
+	for _, workflow := range m.workflows {
+		all = append(all, workflow)
+	}
+	return all, nil
+}
+
+func (m *memoryWorkflowRepository) Update(workflow *repository.Workflow) error {
+	m.workflows[workflow.ID] = workflow
+	return nil
+}
+
+func (m *memoryWorkflowRepository) Delete(id uuid.UUID) error {
+	delete(m.workflows, id)
+	return nil
+}
+
+// loginWorkflow builds a token from its "user" input with a transform task.
+func loginWorkflow() engine.WorkflowDefinition {
+	return engine.WorkflowDefinition{
+		Name: "login",
+		Tasks: []engine.Task{{
+			ID:   "login",
+			Type: "transform",
+			Config: map[string]interface{}{
+				"template":      `{"token": "token-{{.input.user}}"}`,
+				"output_format": "json",
+			},
+		}},
+	}
+}
+
+func newWorkflowTaskEngine(repo *memoryWorkflowRepository) *engine.Engine {
+	registry := engine.NewRegistry()
+	RegisterTransformTask(registry)
+	RegisterWorkflowTask(registry, repo)
+	return engine.NewEngine(registry)
+}
+
+func TestWorkflowTask_Execute_MissingWorkflow(t *testing.T) {
+	task := NewWorkflowTask(newMemoryWorkflowRepository())
+
+	result := task.Execute(context.Background(), engine.NewExecutionContext(), map[string]interface{}{})
+
+	assert.Equal(t, "failed", result.Status)
+	assert.Contains(t, result.Error, "missing or invalid 'workflow'")
+}
+
+func TestWorkflowTask_Execute_InvalidMapping(t *testing.T) {
+	task := NewWorkflowTask(newMemoryWorkflowRepository())
+
+	result := task.Execute(context.Background(), engine.NewExecutionContext(), map[string]interface{}{
+		"workflow": "login",
+		"inputs":   map[string]interface{}{"user": 42},
+	})
+
+	assert.Equal(t, "failed", result.Status)
+	assert.Contains(t, result.Error, "invalid 'inputs.user'")
+}
+
+func TestWorkflowTask_Execute_UnknownWorkflow(t *testing.T) {
+	eng := newWorkflowTaskEngine(newMemoryWorkflowRepository())
+
+	_, err := eng.Execute(context.Background(), engine.WorkflowDefinition{
+		Name: "parent",
+		Tasks: []engine.Task{{
+			ID:     "call",
+			Type:   "workflow",
+			Config: map[string]interface{}{"workflow": "missing"},
+		}},
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load workflow 'missing'")
+}
+
+func TestWorkflowTask_IntegrationByNameWithMapping(t *testing.T) {
+	repo := newMemoryWorkflowRepository()
+	repo.add(t, loginWorkflow())
+	eng := newWorkflowTaskEngine(repo)
+
+	run := eng.NewRun(nil)
+	run.Context().Set("account", map[string]interface{}{"name": "ada"})
+	err := run.Execute(context.Background(), engine.WorkflowDefinition{
+		Name: "parent",
+		Tasks: []engine.Task{{
+			ID:   "call_login",
+			Type: "workflow",
+			Config: map[string]interface{}{
+				"workflow": "login",
+				"inputs":   map[string]interface{}{"user": "account.name"},
+				"outputs":  map[string]interface{}{"token": "login_result.token"},
+			},
+		}},
+	})
+
+	require.NoError(t, err)
+	result, exists := run.Context().Get("call_login_result")
+	require.True(t, exists)
+	assert.Equal(t, map[string]interface{}{"token": "token-ada"}, result)
+}
+
+func TestWorkflowTask_IntegrationByIDReturnsChildContext(t *testing.T) {
+	repo := newMemoryWorkflowRepository()
+	stored := repo.add(t, loginWorkflow())
+	eng := newWorkflowTaskEngine(repo)
+
+	execCtx, err := eng.Execute(context.Background(), engine.WorkflowDefinition{
+		Name: "parent",
+		Tasks: []engine.Task{{
+			ID:     "call_login",
+			Type:   "workflow",
+			Config: map[string]interface{}{"workflow": stored.ID.String()},
+		}},
+	})
+
+	require.NoError(t, err)
+	result, _ := execCtx.Get("call_login_result")
+	output := result.(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{}, output["input"])
+	assert.Contains(t, output, "login_result")
+}
+
+func TestWorkflowTask_IntegrationRecursionIsGuarded(t *testing.T) {
+	repo := newMemoryWorkflowRepository()
+	self := engine.WorkflowDefinition{
+		Name: "self",
+		Tasks: []engine.Task{{
+			ID:     "call_self",
+			Type:   "workflow",
+			Config: map[string]interface{}{"workflow": "self"},
+		}},
+	}
+	repo.add(t, self)
+	eng := newWorkflowTaskEngine(repo)
+
+	_, err := eng.Execute(context.Background(), self)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "maximum workflow nesting depth")
+}
+
+func TestWorkflowTask_ValidateConfig(t *testing.T) {
+	task := NewWorkflowTask(newMemoryWorkflowRepository())
+
+	assert.NoError(t, task.ValidateConfig(map[string]interface{}{
+		"workflow": "child",
+		"inputs":   map[string]interface{}{"user": "login_result.body.user"},
+	}))
+	assert.ErrorContains(t, task.ValidateConfig(map[string]interface{}{}), "missing or invalid 'workflow'")
+	assert.ErrorContains(t, task.ValidateConfig(map[string]interface{}{
+		"workflow": "child",
+		"outputs":  []interface{}{"token"},
+	}), "invalid 'outputs' in configuration")
+}
+
+func TestWorkflowTask_ValidateOutputsAreChildPaths(t *testing.T) {
+	registry := engine.NewRegistry()
+	RegisterWorkflowTask(registry, newMemoryWorkflowRepository())
+	parent := engine.WorkflowDefinition{
+		Name: "parent",
+		Tasks: []engine.Task{{
+			ID:   "login",
+			Type: "workflow",
+			Config: map[string]interface{}{
+				"workflow": "auth",
+				"inputs":   map[string]interface{}{"user": "input.user"},
+				"outputs":  map[string]interface{}{"token": "auth_result.body.token"},
+			},
+		}},
+	}
+
+	// auth_result is a task of the child workflow
+	assert.NoError(t, engine.Validate(parent, registry))
+
+	// Inputs are still parent context paths
+	parent.Tasks[0].Config["inputs"] = map[string]interface{}{"user": "account_result.name"}
+	assert.ErrorContains(t, engine.Validate(parent, registry), "task 'login' references 'account_result' but the workflow has no task 'account'")
+}
@@ -0,0 +1,276 @@
+// Package tracing records OpenTelemetry traces of workflow runs: a root span
+// per execution, a child span per task attempt, and a client span per
+// outbound HTTP request, whose trace context is propagated to the target
+// service in the traceparent header.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/davioliveira/rest_api_automation_hub_go/internal/tracing"
+	defaultServiceName  = "automation-hub"
+)
+
+// propagator carries trace context in W3C traceparent/tracestate headers
+var propagator = propagation.TraceContext{}
+
+// Config selects where spans are exported.
+type Config struct {
+	// Exporter is "none" (spans are not exported), "otlp" (OTLP over HTTP,
+	// configured by the standard OTEL_EXPORTER_OTLP_* variables), "stdout"
+	// or "file" (one JSON object per span)
+	Exporter string
+	// File is the path spans are appended to with the "file" exporter
+	File string
+	// ServiceName is reported as the service.name resource attribute
+	ServiceName string
+}
+
+// ConfigFromEnv reads the Config from OTEL_TRACES_EXPORTER (default "none"),
+// OTEL_TRACES_FILE and OTEL_SERVICE_NAME (default "automation-hub").
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Exporter:    os.Getenv("OTEL_TRACES_EXPORTER"),
+		File:        os.Getenv("OTEL_TRACES_FILE"),
+		ServiceName: os.Getenv("OTEL_SERVICE_NAME"),
+	}
+	if cfg.Exporter == "" {
+		cfg.Exporter = "none"
+	}
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = defaultServiceName
+	}
+	return cfg
+}
+
+// NewProvider creates a TracerProvider exporting spans as cfg says. Call its
+// Shutdown method before exiting so buffered spans are exported.
+func NewProvider(ctx context.Context, cfg Config) (*sdktrace.TracerProvider, error) {
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+	options := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.Exporter {
+	case "", "none":
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "stdout":
+		exporter, err = stdouttrace.New()
+	case "file":
+		exporter, err = newFileExporter(cfg.File)
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", cfg.Exporter, err)
+	}
+	if exporter != nil {
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	return sdktrace.NewTracerProvider(options...), nil
+}
+
+// fileExporter writes spans to a file, closing it on shutdown.
+type fileExporter struct {
+	*stdouttrace.Exporter
+	file *os.File
+}
+
+func newFileExporter(path string) (*fileExporter, error) {
+	if path == "" {
+		return nil, fmt.Errorf("OTEL_TRACES_FILE is required")
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &fileExporter{Exporter: exporter, file: file}, nil
+}
+
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	if err := e.Exporter.Shutdown(ctx); err != nil {
+		return err
+	}
+	return e.file.Close()
+}
+
+// SetGlobal makes provider and the traceparent propagator the process-wide
+// defaults, for libraries that use the otel globals.
+func SetGlobal(provider trace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+}
+
+// Tracer creates the spans of workflow runs and outbound requests.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// New creates a Tracer recording spans with provider.
+func New(provider trace.TracerProvider) *Tracer {
+	return &Tracer{tracer: provider.Tracer(instrumentationName)}
+}
+
+// Instrument traces the runs and task attempts of e through a RunHook and a
+// Middleware. Call it before e starts executing workflows.
+func (t *Tracer) Instrument(e *engine.Engine) {
+	e.AddHook(&runHook{tracer: t.tracer})
+	e.Use(t.taskMiddleware)
+}
+
+// runHook wraps each run, including sub-workflow runs, in a span
+type runHook struct {
+	tracer trace.Tracer
+}
+
+func (h *runHook) BeforeRun(ctx context.Context, run *engine.RunInfo) (context.Context, error) {
+	attributes := []attribute.KeyValue{attribute.String("workflow.name", run.Workflow.Name)}
+	if run.ExecutionID != nil {
+		attributes = append(attributes,
+			attribute.String("workflow.id", run.WorkflowID.String()),
+			attribute.String("execution.id", run.ExecutionID.String()),
+		)
+	}
+	ctx, _ = h.tracer.Start(ctx, "workflow "+run.Workflow.Name,
+		trace.WithTimestamp(run.StartedAt),
+		trace.WithAttributes(attributes...),
+	)
+	return ctx, nil
+}
+
+func (h *runHook) AfterRun(ctx context.Context, run *engine.RunInfo) error {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("execution.status", run.Status))
+	if run.Err != nil {
+		span.RecordError(run.Err)
+		span.SetStatus(codes.Error, run.Err.Error())
+	}
+	span.End(trace.WithTimestamp(run.CompletedAt))
+	return nil
+}
+
+// taskMiddleware wraps each task attempt in a span. Skipped tasks get a span
+// with status "skipped".
+func (t *Tracer) taskMiddleware(next engine.TaskHandler) engine.TaskHandler {
+	return func(ctx context.Context, call engine.TaskCall) engine.TaskResult {
+		ctx, span := t.tracer.Start(ctx, "task "+call.Task.ID, trace.WithAttributes(
+			attribute.String("task.id", call.Task.ID),
+			attribute.String("task.type", call.Task.Type),
+			attribute.Int("task.attempt", call.Attempt),
+		))
+		defer span.End()
+
+		result := next(ctx, call)
+		span.SetAttributes(attribute.String("task.status", result.Status))
+		if result.Status == "failed" {
+			span.SetStatus(codes.Error, result.Error)
+		}
+		return result
+	}
+}
+
+// InstrumentTransport wraps next (http.DefaultTransport if nil) so that every
+// outbound request gets a client span, whose context is sent along in the
+// traceparent header.
+func (t *Tracer) InstrumentTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		ctx, span := t.tracer.Start(req.Context(), "HTTP "+req.Method,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("http.request.method", req.Method),
+				attribute.String("server.address", req.URL.Host),
+				attribute.String("url.full", spanURL(req.URL)),
+			),
+		)
+		defer span.End()
+
+		// A RoundTripper must not modify the caller's request
+		req = req.Clone(ctx)
+		propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+		resp, err := next.RoundTrip(req)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return resp, err
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		if resp.StatusCode >= http.StatusBadRequest {
+			span.SetStatus(codes.Error, strconv.Itoa(resp.StatusCode))
+		}
+		return resp, nil
+	})
+}
+
+// spanURL returns u without credentials or query string, which may carry
+// secrets
+func spanURL(u *url.URL) string {
+	stripped := *u
+	stripped.User = nil
+	stripped.RawQuery = ""
+	stripped.ForceQuery = false
+	return stripped.String()
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// ExtractHeaders returns ctx with the trace context carried by an incoming
+// request's traceparent header, if any, so spans started from it join the
+// caller's trace.
+func ExtractHeaders(ctx context.Context, header http.Header) context.Context {
+	return propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Inject returns the trace context of ctx as traceparent/tracestate values,
+// or nil if ctx has none. It lets a trace continue in another process, such
+// as the worker that runs a queued execution (see Extract).
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// Extract returns ctx with the trace context stored by Inject.
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	return propagator.Extract(ctx, propagation.MapCarrier(carrier))
+}
@@ -0,0 +1,234 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/davioliveira/rest_api_automation_hub_go/internal/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	callerTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	callerSpanID  = "00f067aa0ba902b7"
+)
+
+// exportedSpan is a span as written by the file exporter
+type exportedSpan struct {
+	Name        string
+	SpanKind    int
+	SpanContext struct {
+		TraceID string
+		SpanID  string
+	}
+	Parent struct {
+		SpanID string
+	}
+	Attributes []struct {
+		Key   string
+		Value struct {
+			Value interface{}
+		}
+	}
+	Status struct {
+		Code        string
+		Description string
+	}
+}
+
+func (s exportedSpan) attribute(key string) interface{} {
+	for _, attr := range s.Attributes {
+		if attr.Key == key {
+			return attr.Value.Value
+		}
+	}
+	return nil
+}
+
+// newFileProvider returns a provider exporting to a temporary file, and a
+// function that shuts it down and returns the spans exported, in the order
+// they ended
+func newFileProvider(t *testing.T) (*sdktrace.TracerProvider, func() []exportedSpan) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	provider, err := NewProvider(context.Background(), Config{Exporter: "file", File: path})
+	require.NoError(t, err)
+
+	return provider, func() []exportedSpan {
+		require.NoError(t, provider.Shutdown(context.Background()))
+		file, err := os.Open(path)
+		require.NoError(t, err)
+		defer file.Close()
+
+		var spans []exportedSpan
+		decoder := json.NewDecoder(file)
+		for {
+			var span exportedSpan
+			err := decoder.Decode(&span)
+			if errors.Is(err, io.EOF) {
+				return spans
+			}
+			require.NoError(t, err)
+			spans = append(spans, span)
+		}
+	}
+}
+
+// flakyExecutor fails its first call and succeeds afterwards
+type flakyExecutor struct {
+	calls int32
+}
+
+func (f *flakyExecutor) Execute(ctx context.Context, execCtx *engine.ExecutionContext, config map[string]interface{}) engine.TaskResult {
+	if atomic.AddInt32(&f.calls, 1) == 1 {
+		return engine.TaskResult{Status: "failed", Error: "temporary"}
+	}
+	return engine.TaskResult{Status: "success"}
+}
+
+func TestInstrument_SpansPerExecutionAndTask(t *testing.T) {
+	provider, exported := newFileProvider(t)
+	registry := engine.NewRegistry()
+	registry.Register("flaky", &flakyExecutor{})
+	registry.Register("mock", &engine.MockExecutor{})
+	executionEngine := engine.NewEngine(registry)
+	New(provider).Instrument(executionEngine)
+
+	// The run joins the trace of the request that started it
+	ctx := Extract(context.Background(), map[string]string{
+		"traceparent": fmt.Sprintf("00-%s-%s-01", callerTraceID, callerSpanID),
+	})
+	_, err := executionEngine.Execute(ctx, engine.WorkflowDefinition{
+		Name: "sync",
+		Tasks: []engine.Task{
+			{ID: "fetch", Type: "flaky", Retry: &engine.RetryPolicy{MaxAttempts: 2, DelayMs: 1}},
+			{ID: "skipped", Type: "mock", When: "fetch_result == 'never'", DependsOn: []string{"fetch"}},
+		},
+	})
+	require.NoError(t, err)
+
+	spans := exported()
+	require.Len(t, spans, 4)
+	root := spans[3]
+	assert.Equal(t, "workflow sync", root.Name)
+	assert.Equal(t, callerTraceID, root.SpanContext.TraceID)
+	assert.Equal(t, callerSpanID, root.Parent.SpanID)
+	assert.Equal(t, "sync", root.attribute("workflow.name"))
+	assert.Equal(t, "completed", root.attribute("execution.status"))
+
+	expected := []struct {
+		name    string
+		attempt float64
+		status  string
+		code    string
+	}{
+		{"task fetch", 1, "failed", "Error"},
+		{"task fetch", 2, "success", "Unset"},
+		{"task skipped", 1, "skipped", "Unset"},
+	}
+	for i, want := range expected {
+		span := spans[i]
+		assert.Equal(t, want.name, span.Name)
+		assert.Equal(t, callerTraceID, span.SpanContext.TraceID)
+		assert.Equal(t, root.SpanContext.SpanID, span.Parent.SpanID)
+		assert.Equal(t, want.attempt, span.attribute("task.attempt"))
+		assert.Equal(t, want.status, span.attribute("task.status"))
+		assert.Equal(t, want.code, span.Status.Code)
+	}
+	assert.Equal(t, "flaky", spans[0].attribute("task.type"))
+	assert.Equal(t, "temporary", spans[0].Status.Description)
+}
+
+func TestInstrument_FailedRunSetsErrorStatus(t *testing.T) {
+	provider, exported := newFileProvider(t)
+	registry := engine.NewRegistry()
+	registry.Register("mock", &engine.MockExecutor{ShouldFail: true, ErrorMsg: "boom"})
+	executionEngine := engine.NewEngine(registry)
+	New(provider).Instrument(executionEngine)
+
+	_, err := executionEngine.Execute(context.Background(), engine.WorkflowDefinition{
+		Name:  "broken",
+		Tasks: []engine.Task{{ID: "fail", Type: "mock"}},
+	})
+	require.Error(t, err)
+
+	spans := exported()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "workflow broken", spans[1].Name)
+	assert.Equal(t, "0000000000000000", spans[1].Parent.SpanID, "a run without a caller trace is a root span")
+	assert.Equal(t, "failed", spans[1].attribute("execution.status"))
+	assert.Equal(t, "Error", spans[1].Status.Code)
+	assert.Equal(t, err.Error(), spans[1].Status.Description)
+}
+
+func TestInstrumentTransport_PropagatesTraceparent(t *testing.T) {
+	var received atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Store(r.Header.Get("traceparent"))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	provider, exported := newFileProvider(t)
+	tracer := New(provider)
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "task")
+	client := &http.Client{Transport: tracer.InstrumentTransport(nil), Timeout: time.Second}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/status", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	parent.End()
+	assert.Empty(t, req.Header.Get("traceparent"), "the caller's request is left untouched")
+
+	spans := exported()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(t, "HTTP GET", span.Name)
+	assert.Equal(t, parent.SpanContext().SpanID().String(), span.Parent.SpanID)
+	assert.Equal(t, float64(503), span.attribute("http.response.status_code"))
+	assert.Equal(t, "Error", span.Status.Code)
+	assert.Equal(t, fmt.Sprintf("00-%s-%s-01", span.SpanContext.TraceID, span.SpanContext.SpanID), received.Load())
+}
+
+func TestInjectExtract_RoundTrip(t *testing.T) {
+	assert.Nil(t, Inject(context.Background()))
+
+	traceparent := fmt.Sprintf("00-%s-%s-01", callerTraceID, callerSpanID)
+	ctx := ExtractHeaders(context.Background(), http.Header{"Traceparent": []string{traceparent}})
+	carrier := Inject(ctx)
+	assert.Equal(t, map[string]string{"traceparent": traceparent}, carrier)
+	assert.Equal(t, carrier, Inject(Extract(context.Background(), carrier)))
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("OTEL_TRACES_EXPORTER", "")
+	t.Setenv("OTEL_TRACES_FILE", "")
+	t.Setenv("OTEL_SERVICE_NAME", "")
+	assert.Equal(t, Config{Exporter: "none", ServiceName: "automation-hub"}, ConfigFromEnv())
+
+	t.Setenv("OTEL_TRACES_EXPORTER", "file")
+	t.Setenv("OTEL_TRACES_FILE", "/tmp/traces.json")
+	t.Setenv("OTEL_SERVICE_NAME", "hub-worker")
+	assert.Equal(t, Config{Exporter: "file", File: "/tmp/traces.json", ServiceName: "hub-worker"}, ConfigFromEnv())
+}
+
+func TestNewProvider_InvalidConfig(t *testing.T) {
+	_, err := NewProvider(context.Background(), Config{Exporter: "zipkin"})
+	assert.EqualError(t, err, `unsupported trace exporter "zipkin"`)
+
+	_, err = NewProvider(context.Background(), Config{Exporter: "file"})
+	assert.Error(t, err)
+}